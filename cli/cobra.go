@@ -29,12 +29,14 @@ func setupCommonRootCommand(rootCmd *cobra.Command) (*cliflags.ClientOptions, *c
 	cobra.AddTemplateFunc("hasManagementSubCommands", hasManagementSubCommands)
 	cobra.AddTemplateFunc("hasSwarmSubCommands", hasSwarmSubCommands)
 	cobra.AddTemplateFunc("hasInvalidPlugins", hasInvalidPlugins)
+	cobra.AddTemplateFunc("hasUserAliases", hasUserAliases)
 	cobra.AddTemplateFunc("topCommands", topCommands)
 	cobra.AddTemplateFunc("commandAliases", commandAliases)
 	cobra.AddTemplateFunc("operationSubCommands", operationSubCommands)
 	cobra.AddTemplateFunc("managementSubCommands", managementSubCommands)
 	cobra.AddTemplateFunc("orchestratorSubCommands", orchestratorSubCommands)
 	cobra.AddTemplateFunc("invalidPlugins", invalidPlugins)
+	cobra.AddTemplateFunc("userAliases", userAliases)
 	cobra.AddTemplateFunc("wrappedFlagUsages", wrappedFlagUsages)
 	cobra.AddTemplateFunc("vendorAndVersion", vendorAndVersion)
 	cobra.AddTemplateFunc("invalidPluginReason", invalidPluginReason)
@@ -235,6 +237,10 @@ func hasInvalidPlugins(cmd *cobra.Command) bool {
 	return len(invalidPlugins(cmd)) > 0
 }
 
+func hasUserAliases(cmd *cobra.Command) bool {
+	return len(userAliases(cmd)) > 0
+}
+
 func hasTopCommands(cmd *cobra.Command) bool {
 	return len(topCommands(cmd)) > 0
 }
@@ -282,7 +288,7 @@ func topCommands(cmd *cobra.Command) []*cobra.Command {
 func operationSubCommands(cmd *cobra.Command) []*cobra.Command {
 	cmds := []*cobra.Command{}
 	for _, sub := range cmd.Commands() {
-		if isPlugin(sub) {
+		if isPlugin(sub) || isUserAlias(sub) {
 			continue
 		}
 		if _, ok := sub.Annotations["category-top"]; ok {
@@ -374,6 +380,23 @@ func invalidPlugins(cmd *cobra.Command) []*cobra.Command {
 	return cmds
 }
 
+// isUserAlias returns whether the command is an alias that is defined in the
+// "aliases" property of the config file.
+func isUserAlias(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations["user-alias"]
+	return ok
+}
+
+func userAliases(cmd *cobra.Command) []*cobra.Command {
+	cmds := []*cobra.Command{}
+	for _, sub := range cmd.Commands() {
+		if isUserAlias(sub) && sub.IsAvailableCommand() {
+			cmds = append(cmds, sub)
+		}
+	}
+	return cmds
+}
+
 func invalidPluginReason(cmd *cobra.Command) string {
 	return cmd.Annotations[metadata.CommandAnnotationPluginInvalid]
 }
@@ -448,6 +471,15 @@ func invalidPluginReason(cmd *cobra.Command) string {
 {{- end}}
 {{- end}}
 
+{{- if hasUserAliases . }}
+
+User-defined Aliases:
+
+{{- range userAliases . }}
+  {{rpad .Name .NamePadding }} {{.Short}}
+{{- end}}
+
+{{- end}}
 {{- if hasInvalidPlugins . }}
 
 Invalid Plugins:
@@ -16,6 +16,7 @@
 
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
 	dcontext "github.com/docker/cli/cli/context"
 	"github.com/docker/cli/cli/context/docker"
 	"github.com/docker/cli/cli/context/store"
@@ -23,6 +24,7 @@
 	cliflags "github.com/docker/cli/cli/flags"
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/cli/cli/version"
+	"github.com/docker/cli/internal/oauth/manager"
 	dopts "github.com/docker/cli/opts"
 	"github.com/moby/moby/api/types/build"
 	"github.com/moby/moby/client"
@@ -250,6 +252,12 @@ func (cli *DockerCli) Initialize(opts *cliflags.ClientOptions, ops ...CLIOption)
 	cli.options = opts
 	cli.configFile = config.LoadDefaultConfigFile(cli.err)
 	cli.currentContext = resolveContextName(cli.options, cli.configFile)
+	if err := cli.loadProfile(); err != nil {
+		return err
+	}
+	cli.configFile.SetCredentialsStoreWrapper(func(store credentials.Store) credentials.Store {
+		return manager.NewRefreshingStore(cli.baseCtx, cli.configFile, store)
+	})
 	cli.contextStore = &ContextStoreWithDefault{
 		Store: store.New(config.ContextStoreDir(), *cli.contextStoreConfig),
 		Resolver: func() (*DefaultContext, error) {
@@ -456,6 +464,45 @@ func resolveContextName(opts *cliflags.ClientOptions, cfg *configfile.ConfigFile
 	return DefaultContextName
 }
 
+// loadProfile merges the configuration profile to use with the config file,
+// if any. The profile is selected, in the following order of preference:
+//
+//  1. The "--profile" command-line option.
+//  2. The "DOCKER_PROFILE" environment variable ([EnvOverrideProfile]).
+//  3. The profile that's configured for the current context in the
+//     "contextProfiles" field in the CLI configuration file.
+//  4. The profile that's configured in the "currentProfile" field in the
+//     CLI configuration file.
+//
+// A profile may select a different context, in which case the profile for
+// that context is not used. Profiles that are configured in the CLI
+// configuration file, but don't exist, produce a warning instead of an error.
+func (cli *DockerCli) loadProfile() error {
+	name := os.Getenv(EnvOverrideProfile)
+	if cli.options != nil && cli.options.Profile != "" {
+		name = cli.options.Profile
+	}
+	configured := name == ""
+	if configured {
+		name = cli.configFile.CurrentProfile
+		if p := cli.configFile.ContextProfiles[cli.currentContext]; p != "" {
+			name = p
+		}
+	}
+	if name == "" || name == config.DefaultProfileName {
+		return nil
+	}
+	if err := config.LoadProfile(cli.configFile, name); err != nil {
+		if !configured {
+			return err
+		}
+		_, _ = fmt.Fprintln(cli.err, "WARNING: Error loading profile:", err)
+		return nil
+	}
+	cli.currentContext = resolveContextName(cli.options, cli.configFile)
+	return nil
+}
+
 // DockerEndpoint returns the current docker endpoint
 func (cli *DockerCli) DockerEndpoint() docker.Endpoint {
 	if err := cli.initialize(); err != nil {
@@ -22,6 +22,7 @@
 	"github.com/docker/cli/cli/flags"
 	"github.com/moby/moby/client"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 )
 
 func TestNewAPIClientFromFlags(t *testing.T) {
@@ -434,3 +435,85 @@ func TestNewDockerCliWithAPIClientOptions(t *testing.T) {
 	assert.NilError(t, err)
 	assert.DeepEqual(t, received, "fake-agent/0.0.1")
 }
+
+func TestInitializeProfile(t *testing.T) {
+	configDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(configDir, config.ConfigFileName), []byte(`{
+	"psFormat": "user",
+	"currentProfile": "dev",
+	"contextProfiles": {"prod": "prod"},
+	"profiles": {
+		"dev": {"psFormat": "dev"},
+		"prod": {"psFormat": "prod"},
+		"ci": {"psFormat": "ci", "currentContext": "prod"}
+	}
+}`), 0o600))
+
+	tests := []struct {
+		doc            string
+		profile        string
+		envProfile     string
+		context        string
+		expectedFormat string
+		expectedErr    string
+	}{
+		{
+			doc:            "current profile",
+			expectedFormat: "dev",
+		},
+		{
+			doc:            "profile of context",
+			context:        "prod",
+			expectedFormat: "prod",
+		},
+		{
+			doc:            "env-var",
+			envProfile:     "prod",
+			expectedFormat: "prod",
+		},
+		{
+			doc:            "flag",
+			profile:        "ci",
+			envProfile:     "prod",
+			context:        "prod",
+			expectedFormat: "ci",
+		},
+		{
+			doc:            "default",
+			profile:        "default",
+			expectedFormat: "user",
+		},
+		{
+			doc:         "not found",
+			envProfile:  "unknown",
+			expectedErr: `profile "unknown" not found`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			t.Setenv(EnvOverrideProfile, tc.envProfile)
+			cli, err := NewDockerCli()
+			assert.NilError(t, err)
+			opts := flags.NewClientOptions()
+			opts.ConfigDir = configDir
+			opts.Profile = tc.profile
+			opts.Context = tc.context
+			err = cli.Initialize(opts)
+			if tc.expectedErr != "" {
+				assert.Check(t, is.Error(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(cli.ConfigFile().PsFormat, tc.expectedFormat))
+		})
+	}
+
+	// A profile can select the context.
+	cli, err := NewDockerCli()
+	assert.NilError(t, err)
+	opts := flags.NewClientOptions()
+	opts.ConfigDir = configDir
+	opts.Profile = "ci"
+	assert.NilError(t, cli.Initialize(opts))
+	assert.Check(t, is.Equal(cli.CurrentContext(), "prod"))
+}
@@ -0,0 +1,140 @@
+package cliconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/commands"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	commands.Register(newCLIConfigCommand)
+}
+
+// newCLIConfigCommand returns a cobra command for `cli-config` subcommands
+func newCLIConfigCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cli-config",
+		Short: "Manage the configuration file of the CLI",
+		Args:  cli.NoArgs,
+		RunE:  command.ShowHelp(dockerCLI.Err()),
+
+		DisableFlagsInUseLine: true,
+	}
+	cmd.AddCommand(
+		newGetCommand(dockerCLI),
+		newListCommand(dockerCLI),
+		newSetCommand(dockerCLI),
+		newUnsetCommand(dockerCLI),
+		newValidateCommand(dockerCLI),
+	)
+	return cmd
+}
+
+// completeKeys offers the keys of the config file, and the properties of the
+// config file that are not set, for completion.
+func completeKeys(dockerCLI command.Cli) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		keys := make(map[string]struct{})
+		for name := range configSchema.properties {
+			if name != "auths" {
+				keys[name] = struct{}{}
+			}
+		}
+		if data, err := configJSON(dockerCLI.ConfigFile()); err == nil {
+			for _, kv := range flatten(data) {
+				keys[kv.key] = struct{}{}
+			}
+		}
+		var completions []string
+		for k := range keys {
+			if strings.HasPrefix(k, toComplete) {
+				completions = append(completions, k)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// configJSON returns the config file as it would be written to disk.
+func configJSON(cfg *configfile.ConfigFile) (map[string]any, error) {
+	var buf bytes.Buffer
+	if err := cfg.SaveToWriter(&buf); err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// lookup returns the value at the given path.
+func lookup(data map[string]any, path []string) (any, bool) {
+	var v any = data
+	for _, p := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[p]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+type keyValue struct {
+	key   string
+	value any
+}
+
+// flatten returns the values in the config file, except for credentials,
+// ordered by key. Objects are flattened to the values of their entries.
+func flatten(data map[string]any) []keyValue {
+	var kvs []keyValue
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		obj, ok := v.(map[string]any)
+		if !ok || (len(obj) == 0 && prefix != "") {
+			kvs = append(kvs, keyValue{key: prefix, value: v})
+			return
+		}
+		for k, ev := range obj {
+			if prefix == "" && k == "auths" {
+				continue
+			}
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			walk(k, ev)
+		}
+	}
+	walk("", data)
+	slices.SortFunc(kvs, func(a, b keyValue) int { return strings.Compare(a.key, b.key) })
+	return kvs
+}
+
+// formatValue formats a value for printing: strings are printed as-is, and
+// other values as JSON.
+func formatValue(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
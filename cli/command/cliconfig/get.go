@@ -0,0 +1,77 @@
+package cliconfig
+
+import (
+	"fmt"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+// newGetCommand creates a new `docker cli-config get` command
+func newGetCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print the value of a key in the configuration file",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGet(dockerCLI, args[0])
+		},
+		ValidArgsFunction:     completeKeys(dockerCLI),
+		DisableFlagsInUseLine: true,
+	}
+	return cmd
+}
+
+func runGet(dockerCLI command.Cli, key string) error {
+	data, err := configJSON(dockerCLI.ConfigFile())
+	if err != nil {
+		return err
+	}
+	path, _, err := resolveKey(key, data)
+	if err != nil {
+		return err
+	}
+	v, ok := lookup(data, path)
+	if !ok {
+		return errdefs.ErrNotFound.WithMessage(fmt.Sprintf("key %q is not set", key))
+	}
+	out, err := formatValue(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(dockerCLI.Out(), out)
+	return err
+}
+
+// newListCommand creates a new `docker cli-config ls` command
+func newListCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the keys that are set in the configuration file",
+		Args:    cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(dockerCLI)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+	return cmd
+}
+
+func runList(dockerCLI command.Cli) error {
+	data, err := configJSON(dockerCLI.ConfigFile())
+	if err != nil {
+		return err
+	}
+	for _, kv := range flatten(data) {
+		out, err := formatValue(kv.value)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(dockerCLI.Out(), "%s=%s\n", kv.key, out)
+	}
+	return nil
+}
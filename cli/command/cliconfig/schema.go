@@ -0,0 +1,277 @@
+package cliconfig
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+)
+
+// deprecatedProperties are properties that may be found in config files,
+// but are no longer used.
+var deprecatedProperties = map[reflect.Type][]string{
+	reflect.TypeFor[configfile.ConfigFile](): {"experimental", "stackOrchestrator"},
+	reflect.TypeFor[types.AuthConfig]():      {"email"},
+}
+
+// schema describes a value in the config file. It is generated from the
+// [configfile.ConfigFile] struct and its tags.
+type schema struct {
+	typ string // "string", "boolean", "integer", "number", "array" or "object"
+
+	// properties holds the properties of objects that are structs, and
+	// deprecated the properties that are no longer used.
+	properties map[string]*schema
+	deprecated []string
+
+	// elem is the schema of the items of arrays, and the entries of objects
+	// that are maps.
+	elem *schema
+}
+
+// configSchema is the schema of the config file.
+var configSchema = func() *schema {
+	s := schemaFor(reflect.TypeFor[configfile.ConfigFile]())
+
+	// Profiles hold the properties of the config file, except for the ones
+	// that define or select profiles, and credentials.
+	profile := &schema{typ: "object", properties: maps.Clone(s.properties), deprecated: s.deprecated}
+	for _, name := range []string{"auths", "contextProfiles", "currentProfile", "profiles"} {
+		delete(profile.properties, name)
+	}
+	s.properties["profiles"] = &schema{typ: "object", elem: profile}
+	return s
+}()
+
+func schemaFor(t reflect.Type) *schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return &schema{typ: "string"}
+	case reflect.Bool:
+		return &schema{typ: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{typ: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{typ: "number"}
+	case reflect.Slice, reflect.Array:
+		return &schema{typ: "array", elem: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &schema{typ: "object", elem: schemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &schema{typ: "object", properties: make(map[string]*schema), deprecated: deprecatedProperties[t]}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, ok := jsonName(f)
+			if !ok {
+				continue
+			}
+			s.properties[name] = schemaFor(f.Type)
+		}
+		return s
+	default:
+		return &schema{}
+	}
+}
+
+// jsonName returns the name of the property that the given struct field is
+// encoded as, and whether the field is encoded at all.
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	default:
+		return name, true
+	}
+}
+
+// isStruct returns whether the schema is an object with fixed properties.
+func (s *schema) isStruct() bool {
+	return s.properties != nil
+}
+
+// isMap returns whether the schema is an object with arbitrary entries.
+func (s *schema) isMap() bool {
+	return s.typ == "object" && s.elem != nil
+}
+
+// jsonSchema returns the schema in JSON Schema format.
+func (s *schema) jsonSchema() map[string]any {
+	js := make(map[string]any)
+	if s.typ != "" {
+		js["type"] = s.typ
+	}
+	switch {
+	case s.isStruct():
+		props := make(map[string]any, len(s.properties)+len(s.deprecated))
+		for name, p := range s.properties {
+			props[name] = p.jsonSchema()
+		}
+		for _, name := range s.deprecated {
+			props[name] = map[string]any{"deprecated": true}
+		}
+		js["properties"] = props
+		js["additionalProperties"] = false
+	case s.typ == "array":
+		js["items"] = s.elem.jsonSchema()
+	case s.isMap():
+		js["additionalProperties"] = s.elem.jsonSchema()
+	}
+	return js
+}
+
+// property returns the schema of the property with the given name. If there
+// is no such property, it returns an error that suggests similar properties.
+func (s *schema) property(name, key string) (*schema, error) {
+	if p, ok := s.properties[name]; ok {
+		return p, nil
+	}
+	msg := fmt.Sprintf("unknown key %q", key)
+	suggestion := suggest(name, slices.Collect(maps.Keys(s.properties)))
+	if suggestion != "" {
+		msg += fmt.Sprintf(": did you mean %q?", strings.TrimSuffix(key, name)+suggestion)
+	}
+	return nil, &unknownKeyError{error: errdefs.ErrInvalidArgument.WithMessage(msg), suggested: suggestion != ""}
+}
+
+// unknownKeyError is returned for keys that are not in the schema.
+type unknownKeyError struct {
+	error
+	suggested bool // whether a similar key was suggested
+}
+
+func (e *unknownKeyError) Unwrap() error {
+	return e.error
+}
+
+// resolveKey splits a dotted key, such as "proxies.default.httpProxy", into
+// the path to its value in the given config file. The keys of entries in
+// objects such as "proxies" may contain dots themselves: they are resolved
+// to existing entries if possible.
+func resolveKey(key string, cfg map[string]any) ([]string, *schema, error) {
+	if key == "" {
+		return nil, nil, errdefs.ErrInvalidArgument.WithMessage("key cannot be empty")
+	}
+	path, s, err := resolve(configSchema, strings.Split(key, "."), cfg, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path[0] == "auths" {
+		return nil, nil, errdefs.ErrInvalidArgument.WithMessage(`"auths" holds credentials: use "docker login" and "docker logout" to manage them`)
+	}
+	return path, s, nil
+}
+
+func resolve(s *schema, segments []string, value any, key string) ([]string, *schema, error) {
+	if len(segments) == 0 {
+		return nil, s, nil
+	}
+	obj, _ := value.(map[string]any)
+	switch {
+	case s.isStruct():
+		p, err := s.property(segments[0], keyPrefix(key, segments[1:]))
+		if err != nil {
+			return nil, nil, err
+		}
+		path, ps, err := resolve(p, segments[1:], obj[segments[0]], key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return append([]string{segments[0]}, path...), ps, nil
+	case s.isMap():
+		// Prefer existing entries, then entries with the shortest name. Keys
+		// that look like a typo in the properties of an entry are rejected,
+		// instead of taken as the name of a new entry.
+		var firstErr, typoErr error
+		var found []string
+		var foundSchema *schema
+		for i := 1; i <= len(segments); i++ {
+			entry := strings.Join(segments[:i], ".")
+			path, ps, err := resolve(s.elem, segments[i:], obj[entry], key)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				var uke *unknownKeyError
+				if typoErr == nil && errors.As(err, &uke) && uke.suggested {
+					typoErr = err
+				}
+				continue
+			}
+			path = append([]string{entry}, path...)
+			if _, ok := obj[entry]; ok {
+				return path, ps, nil
+			}
+			if found == nil {
+				found, foundSchema = path, ps
+			}
+		}
+		switch {
+		case found == nil:
+			return nil, nil, firstErr
+		case typoErr != nil:
+			return nil, nil, typoErr
+		}
+		return found, foundSchema, nil
+	default:
+		return nil, nil, errdefs.ErrInvalidArgument.WithMessage(fmt.Sprintf("invalid key %q: %q is not an object", key, keyPrefix(key, segments)))
+	}
+}
+
+// keyPrefix returns the part of key before the given remaining segments.
+func keyPrefix(key string, remaining []string) string {
+	if len(remaining) == 0 {
+		return key
+	}
+	return strings.TrimSuffix(key, "."+strings.Join(remaining, "."))
+}
+
+// suggest returns the candidate that is most similar to name, if any is
+// similar enough.
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/3+2
+	for _, c := range slices.Sorted(slices.Values(candidates)) {
+		if strings.EqualFold(c, name) {
+			return c
+		}
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(c)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,213 @@
+package cliconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/spf13/cobra"
+)
+
+// newSetCommand creates a new `docker cli-config set` command
+func newSetCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set the value of a key in the configuration file",
+		Args:  cli.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSet(dockerCLI, args[0], args[1])
+		},
+		ValidArgsFunction:     completeKeys(dockerCLI),
+		DisableFlagsInUseLine: true,
+	}
+	return cmd
+}
+
+func runSet(dockerCLI command.Cli, key, value string) error {
+	cfg := dockerCLI.ConfigFile()
+	data, err := configJSON(cfg)
+	if err != nil {
+		return err
+	}
+	path, s, err := resolveKey(key, data)
+	if err != nil {
+		return err
+	}
+	v, err := parseValue(s, value)
+	if err != nil {
+		return errdefs.ErrInvalidArgument.WithMessage(fmt.Sprintf("invalid value for key %q: %v", key, err))
+	}
+	problems, err := validateValue(s, v, key)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return errdefs.ErrInvalidArgument.WithMessage("invalid value: " + problems[0].String())
+	}
+
+	parent := data
+	for _, p := range path[:len(path)-1] {
+		obj, ok := parent[p].(map[string]any)
+		if !ok {
+			obj = make(map[string]any)
+			parent[p] = obj
+		}
+		parent = obj
+	}
+	parent[path[len(path)-1]] = v
+	if err := setField(cfg, path[0], data[path[0]]); err != nil {
+		return err
+	}
+	return cfg.Save()
+}
+
+// newUnsetCommand creates a new `docker cli-config unset` command
+func newUnsetCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Remove a key from the configuration file",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUnset(dockerCLI, args[0])
+		},
+		ValidArgsFunction:     completeKeys(dockerCLI),
+		DisableFlagsInUseLine: true,
+	}
+	return cmd
+}
+
+func runUnset(dockerCLI command.Cli, key string) error {
+	cfg := dockerCLI.ConfigFile()
+	data, err := configJSON(cfg)
+	if err != nil {
+		return err
+	}
+	path, _, err := resolveKey(key, data)
+	if err != nil {
+		return err
+	}
+	if _, ok := lookup(data, path); !ok {
+		return errdefs.ErrNotFound.WithMessage(fmt.Sprintf("key %q is not set", key))
+	}
+
+	// Remove the value, and the objects that are left empty.
+	for i := len(path); i > 0; i-- {
+		parent, _ := lookup(data, path[:i-1])
+		obj := parent.(map[string]any)
+		delete(obj, path[i-1])
+		if len(obj) > 0 {
+			break
+		}
+	}
+	if err := setField(cfg, path[0], data[path[0]]); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	// The value may still be set in another configuration file.
+	if data, err = configJSON(cfg); err != nil {
+		return err
+	}
+	if _, ok := lookup(data, path); ok {
+		origins, err := cfg.Origins()
+		if err != nil {
+			return err
+		}
+		originKey := strings.Join(path[:min(len(path), 2)], ".")
+		for _, o := range origins {
+			if o.Key == originKey && o.Filename != "" {
+				_, _ = fmt.Fprintf(dockerCLI.Err(), "WARNING: %q is still set in the %s config file (%s)\n", key, o.Origin, o.Filename)
+			}
+		}
+	}
+	return nil
+}
+
+// parseValue parses a value that is passed on the command line, for a key
+// with the given schema. Arrays of strings can be passed as a comma-separated
+// list; other arrays and objects must be passed as JSON.
+func parseValue(s *schema, value string) (any, error) {
+	switch s.typ {
+	case "string":
+		return value, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	case "integer":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return i, nil
+	case "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case "array":
+		if !strings.HasPrefix(strings.TrimSpace(value), "[") && s.elem.typ == "string" {
+			items := []any{}
+			for item := range strings.SplitSeq(value, ",") {
+				if item != "" {
+					items = append(items, item)
+				}
+			}
+			return items, nil
+		}
+		var v []any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("value must be a JSON array: %w", err)
+		}
+		return v, nil
+	case "object":
+		var v map[string]any
+		if err := json.Unmarshal([]byte(value), &v); err != nil || v == nil {
+			return nil, errors.New("value must be a JSON object")
+		}
+		return v, nil
+	default:
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return value, nil
+		}
+		return v, nil
+	}
+}
+
+// setField sets the field of the config file that is encoded as the given
+// property to the given value, or to its zero value if value is nil.
+func setField(cfg *configfile.ConfigFile, property string, value any) error {
+	rv := reflect.ValueOf(cfg).Elem()
+	t := rv.Type()
+	for i := range t.NumField() {
+		if name, ok := jsonName(t.Field(i)); !ok || name != property {
+			continue
+		}
+		v := reflect.New(t.Field(i).Type)
+		if value != nil {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, v.Interface()); err != nil {
+				return err
+			}
+		}
+		rv.Field(i).Set(v.Elem())
+		return nil
+	}
+	return errdefs.ErrInvalidArgument.WithMessage(fmt.Sprintf("unknown key %q", property))
+}
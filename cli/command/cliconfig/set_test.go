@@ -0,0 +1,147 @@
+package cliconfig
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestCli(t *testing.T, content string) *test.FakeCli {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "config.json")
+	assert.NilError(t, os.WriteFile(filename, []byte(content), 0o600))
+	cfg := configfile.New(filename)
+	assert.NilError(t, cfg.LoadFromReader(strings.NewReader(content)))
+	fakeCLI := test.NewFakeCli(nil)
+	fakeCLI.SetConfigFile(cfg)
+	return fakeCLI
+}
+
+func runCommand(fakeCLI *test.FakeCli, args ...string) error {
+	cmd := newCLIConfigCommand(fakeCLI)
+	cmd.SetArgs(args)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return cmd.Execute()
+}
+
+func TestSetGetUnset(t *testing.T) {
+	fakeCLI := newTestCli(t, `{
+	"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}},
+	"proxies": {"tcp://docker.example.com:2376": {"httpProxy": "http://old.example.com"}}
+}`)
+
+	assert.NilError(t, runCommand(fakeCLI, "set", "psFormat", "table {{.Names}}"))
+	assert.NilError(t, runCommand(fakeCLI, "set", "proxies.tcp://docker.example.com:2376.httpProxy", "http://proxy.example.com"))
+	assert.NilError(t, runCommand(fakeCLI, "set", "proxies.default", `{"noProxy": "localhost"}`))
+	assert.NilError(t, runCommand(fakeCLI, "set", "plugins.my-plugin.option", "value"))
+	assert.NilError(t, runCommand(fakeCLI, "set", "cliPluginsExtraDirs", "/usr/local/plugins,/opt/plugins"))
+	assert.NilError(t, runCommand(fakeCLI, "set", "registryTLS.registry.example.com.insecureSkipVerify", "true"))
+
+	// Changes are written to the config file.
+	data, err := os.ReadFile(fakeCLI.ConfigFile().Filename)
+	assert.NilError(t, err)
+	cfg := configfile.New(fakeCLI.ConfigFile().Filename)
+	assert.NilError(t, cfg.LoadFromReader(strings.NewReader(string(data))))
+	assert.Check(t, is.Equal(cfg.PsFormat, "table {{.Names}}"))
+	assert.Check(t, is.DeepEqual(cfg.Proxies, map[string]configfile.ProxyConfig{
+		"default":                       {NoProxy: "localhost"},
+		"tcp://docker.example.com:2376": {HTTPProxy: "http://proxy.example.com"},
+	}))
+	assert.Check(t, is.DeepEqual(cfg.Plugins, map[string]map[string]string{"my-plugin": {"option": "value"}}))
+	assert.Check(t, is.DeepEqual(cfg.CLIPluginsExtraDirs, []string{"/usr/local/plugins", "/opt/plugins"}))
+	assert.Check(t, cfg.RegistryTLS["registry.example.com"].InsecureSkipVerify)
+	assert.Check(t, is.Len(cfg.AuthConfigs, 1))
+
+	fakeCLI.OutBuffer().Reset()
+	assert.NilError(t, runGet(fakeCLI, "proxies.tcp://docker.example.com:2376.httpProxy"))
+	assert.NilError(t, runGet(fakeCLI, "proxies.default"))
+	assert.NilError(t, runGet(fakeCLI, "cliPluginsExtraDirs"))
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), `http://proxy.example.com
+{"noProxy":"localhost"}
+["/usr/local/plugins","/opt/plugins"]
+`))
+
+	fakeCLI.OutBuffer().Reset()
+	assert.NilError(t, runList(fakeCLI))
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), `cliPluginsExtraDirs=["/usr/local/plugins","/opt/plugins"]
+plugins.my-plugin.option=value
+proxies.default.noProxy=localhost
+proxies.tcp://docker.example.com:2376.httpProxy=http://proxy.example.com
+psFormat=table {{.Names}}
+registryTLS.registry.example.com.insecureSkipVerify=true
+`))
+
+	// Objects that are left empty are removed.
+	assert.NilError(t, runCommand(fakeCLI, "unset", "plugins.my-plugin.option"))
+	assert.Check(t, is.Len(fakeCLI.ConfigFile().Plugins, 0))
+	assert.NilError(t, runCommand(fakeCLI, "unset", "psFormat"))
+	assert.Check(t, is.Equal(fakeCLI.ConfigFile().PsFormat, ""))
+	err = runCommand(fakeCLI, "unset", "psFormat")
+	assert.Check(t, is.ErrorContains(err, `key "psFormat" is not set`))
+	err = runCommand(fakeCLI, "get", "psFormat")
+	assert.Check(t, is.ErrorContains(err, `key "psFormat" is not set`))
+}
+
+func TestSetInvalid(t *testing.T) {
+	tests := []struct {
+		doc         string
+		args        []string
+		expectedErr string
+	}{
+		{
+			doc:         "unknown key",
+			args:        []string{"psformat", "value"},
+			expectedErr: `unknown key "psformat": did you mean "psFormat"?`,
+		},
+		{
+			doc:         "unknown nested key",
+			args:        []string{"proxies.default.httpProxyy", "value"},
+			expectedErr: `unknown key "proxies.default.httpProxyy": did you mean "proxies.default.httpProxy"?`,
+		},
+		{
+			doc:         "no suggestion",
+			args:        []string{"foo", "value"},
+			expectedErr: `unknown key "foo"`,
+		},
+		{
+			doc:         "not an object",
+			args:        []string{"psFormat.foo", "value"},
+			expectedErr: `invalid key "psFormat.foo": "psFormat" is not an object`,
+		},
+		{
+			doc:         "invalid boolean",
+			args:        []string{"registryTLS.example.com.insecureSkipVerify", "maybe"},
+			expectedErr: `invalid value for key "registryTLS.example.com.insecureSkipVerify": "maybe" is not a boolean`,
+		},
+		{
+			doc:         "invalid object",
+			args:        []string{"proxies.default", `{"httpProxy": 1}`},
+			expectedErr: `invalid value: proxies.default.httpProxy: Invalid type. Expected: string, given: integer`,
+		},
+		{
+			doc:         "unknown property in object",
+			args:        []string{"proxies.default", `{"httpsproxy": "http://proxy"}`},
+			expectedErr: `invalid value: proxies.default.httpsproxy: should be spelled "httpsProxy"`,
+		},
+		{
+			doc:         "credentials",
+			args:        []string{"auths.example.com.auth", "value"},
+			expectedErr: `"auths" holds credentials: use "docker login" and "docker logout" to manage them`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			fakeCLI := newTestCli(t, `{}`)
+			err := runCommand(fakeCLI, append([]string{"set"}, tc.args...)...)
+			assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+		})
+	}
+}
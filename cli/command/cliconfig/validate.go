@@ -0,0 +1,174 @@
+package cliconfig
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// problem is a problem that was found when validating a config file.
+type problem struct {
+	key     string
+	message string
+	warning bool // the config file can be used, but should be updated
+}
+
+func (p problem) String() string {
+	if p.key == "" {
+		return p.message
+	}
+	return p.key + ": " + p.message
+}
+
+// newValidateCommand creates a new `docker cli-config validate` command
+func newValidateCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [FILE...]",
+		Short: "Validate configuration files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidate(dockerCLI, args)
+		},
+		DisableFlagsInUseLine: true,
+	}
+	return cmd
+}
+
+func runValidate(dockerCLI command.Cli, files []string) error {
+	if len(files) == 0 {
+		cfg := dockerCLI.ConfigFile()
+		for _, l := range cfg.Layers() {
+			files = append(files, l.Filename)
+		}
+		if _, err := os.Stat(cfg.Filename); err == nil {
+			files = append(files, cfg.Filename)
+		}
+	}
+
+	var invalid bool
+	for _, file := range files {
+		problems, err := validateFile(file)
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			if p.warning {
+				_, _ = fmt.Fprintf(dockerCLI.Err(), "WARNING: %s: %s\n", file, p)
+			} else {
+				invalid = true
+				_, _ = fmt.Fprintf(dockerCLI.Err(), "%s: %s\n", file, p)
+			}
+		}
+	}
+	if invalid {
+		return cli.StatusError{StatusCode: 1}
+	}
+	return nil
+}
+
+// validateFile validates the given configuration file against the schema.
+func validateFile(file string) ([]problem, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return []problem{{message: "invalid JSON: " + err.Error()}}, nil
+	}
+	return validateValue(configSchema, v, "")
+}
+
+// validateValue validates a value for the given key against its schema.
+//
+// Properties that are not in the schema are checked separately from the JSON
+// schema, to suggest similar properties, and because properties are matched
+// case-insensitively when the config file is loaded.
+func validateValue(s *schema, value any, key string) ([]problem, error) {
+	problems, err := checkProperties(s, value, key)
+	if err != nil {
+		return nil, err
+	}
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(s.jsonSchema()), gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range result.Errors() {
+		if e.Type() == "additional_property_not_allowed" {
+			continue
+		}
+		field := key
+		if f := e.Field(); f != "(root)" {
+			field = joinKey(key, f)
+		}
+		problems = append(problems, problem{key: field, message: e.Description()})
+	}
+	slices.SortStableFunc(problems, func(a, b problem) int { return cmp.Compare(a.key, b.key) })
+	return problems, nil
+}
+
+// checkProperties checks that the properties of objects in the value are in
+// the schema. Properties that only differ in case from a property in the
+// schema are validated against the schema of that property.
+func checkProperties(s *schema, value any, key string) ([]problem, error) {
+	var problems []problem
+	add := func(p []problem, err error) error {
+		problems = append(problems, p...)
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for name, pv := range v {
+			k := joinKey(key, name)
+			var err error
+			switch {
+			case s.isMap():
+				err = add(checkProperties(s.elem, pv, k))
+			case !s.isStruct():
+			case s.properties[name] != nil:
+				err = add(checkProperties(s.properties[name], pv, k))
+			case slices.Contains(s.deprecated, name):
+				problems = append(problems, problem{key: k, message: "deprecated, and no longer used", warning: true})
+			default:
+				suggestion := suggest(name, slices.Collect(maps.Keys(s.properties)))
+				switch {
+				case strings.EqualFold(suggestion, name):
+					problems = append(problems, problem{key: k, message: fmt.Sprintf("should be spelled %q", suggestion), warning: true})
+					err = add(validateValue(s.properties[suggestion], pv, k))
+				case suggestion != "":
+					problems = append(problems, problem{key: k, message: fmt.Sprintf("unknown key: did you mean %q?", suggestion)})
+				default:
+					problems = append(problems, problem{key: k, message: "unknown key"})
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		if s.elem != nil {
+			for i, item := range v {
+				if err := add(checkProperties(s.elem, item, joinKey(key, strconv.Itoa(i)))); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return problems, nil
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
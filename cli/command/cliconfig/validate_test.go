@@ -0,0 +1,71 @@
+package cliconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/cli"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	fakeCLI := newTestCli(t, `{
+	"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz", "email": "user@example.com"}},
+	"PsFormat": "table {{.Names}}",
+	"experimental": "enabled",
+	"proxies": {"default": {"httpProxy": "http://proxy.example.com"}}
+}`)
+	assert.NilError(t, runValidate(fakeCLI, nil))
+	filename := fakeCLI.ConfigFile().Filename
+	assert.Check(t, is.Equal(fakeCLI.ErrBuffer().String(), `WARNING: `+filename+`: PsFormat: should be spelled "psFormat"
+WARNING: `+filename+`: auths.registry.example.com.email: deprecated, and no longer used
+WARNING: `+filename+`: experimental: deprecated, and no longer used
+`))
+}
+
+func TestValidateInvalid(t *testing.T) {
+	fakeCLI := newTestCli(t, `{}`)
+	filename := filepath.Join(t.TempDir(), "config.json")
+	assert.NilError(t, os.WriteFile(filename, []byte(`{
+	"psFormat": 1,
+	"proxies": {"default": {"httpproxy": "http://proxy.example.com", "noproxy": ["localhost"]}},
+	"cliPluginsExtraDir": ["/usr/local/plugins"],
+	"features": {"buildkit": true},
+	"foo": "bar"
+}`), 0o600))
+	invalidJSON := filepath.Join(t.TempDir(), "config.json")
+	assert.NilError(t, os.WriteFile(invalidJSON, []byte(`{`), 0o600))
+
+	err := runValidate(fakeCLI, []string{filename, invalidJSON})
+	assert.Check(t, is.DeepEqual(err, cli.StatusError{StatusCode: 1}))
+	assert.Check(t, is.Equal(fakeCLI.ErrBuffer().String(), filename+`: cliPluginsExtraDir: unknown key: did you mean "cliPluginsExtraDirs"?
+`+filename+`: features.buildkit: Invalid type. Expected: string, given: boolean
+`+filename+`: foo: unknown key
+WARNING: `+filename+`: proxies.default.httpproxy: should be spelled "httpProxy"
+WARNING: `+filename+`: proxies.default.noproxy: should be spelled "noProxy"
+`+filename+`: proxies.default.noproxy: Invalid type. Expected: string, given: array
+`+filename+`: psFormat: Invalid type. Expected: string, given: integer
+`+invalidJSON+`: invalid JSON: unexpected end of JSON input
+`))
+}
+
+func TestSchema(t *testing.T) {
+	js := configSchema.jsonSchema()
+	assert.Check(t, is.Equal(js["additionalProperties"], false))
+	props := js["properties"].(map[string]any)
+	assert.Check(t, is.DeepEqual(props["psFormat"], map[string]any{"type": "string"}))
+	assert.Check(t, is.DeepEqual(props["plugins"], map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+	}))
+	assert.Check(t, is.DeepEqual(props["cliPluginsExtraDirs"], map[string]any{"type": "array", "items": map[string]any{"type": "string"}}))
+	assert.Check(t, !is.Contains(props, "Filename")().Success())
+
+	profile := props["profiles"].(map[string]any)["additionalProperties"].(map[string]any)
+	profileProps := profile["properties"].(map[string]any)
+	assert.Check(t, is.DeepEqual(profileProps["psFormat"], map[string]any{"type": "string"}))
+	assert.Check(t, !is.Contains(profileProps, "profiles")().Success())
+	assert.Check(t, !is.Contains(profileProps, "auths")().Success())
+}
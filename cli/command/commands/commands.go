@@ -4,7 +4,9 @@
 	"github.com/docker/cli/cli/command"
 	_ "github.com/docker/cli/cli/command/builder"
 	_ "github.com/docker/cli/cli/command/checkpoint"
+	_ "github.com/docker/cli/cli/command/cliconfig"
 	_ "github.com/docker/cli/cli/command/config"
+	_ "github.com/docker/cli/cli/command/configfile"
 	_ "github.com/docker/cli/cli/command/container"
 	_ "github.com/docker/cli/cli/command/context"
 	_ "github.com/docker/cli/cli/command/image"
@@ -12,6 +14,7 @@
 	_ "github.com/docker/cli/cli/command/network"
 	_ "github.com/docker/cli/cli/command/node"
 	_ "github.com/docker/cli/cli/command/plugin"
+	_ "github.com/docker/cli/cli/command/profile"
 	_ "github.com/docker/cli/cli/command/registry"
 	_ "github.com/docker/cli/cli/command/secret"
 	_ "github.com/docker/cli/cli/command/service"
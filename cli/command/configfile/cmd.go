@@ -0,0 +1,28 @@
+package configfile
+
+import (
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/internal/commands"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	commands.Register(newConfigFileCommand)
+}
+
+// newConfigFileCommand returns a cobra command for `config-file` subcommands
+func newConfigFileCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config-file",
+		Short: "Inspect the configuration of the CLI",
+		Args:  cli.NoArgs,
+		RunE:  command.ShowHelp(dockerCLI.Err()),
+
+		DisableFlagsInUseLine: true,
+	}
+	cmd.AddCommand(
+		newShowCommand(dockerCLI),
+	)
+	return cmd
+}
@@ -0,0 +1,107 @@
+package configfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+// redactedFields are the fields of the entries in "auths" that hold secrets.
+var redactedFields = []string{"auth", "identitytoken", "registrytoken"}
+
+type showOptions struct {
+	origin bool
+}
+
+// newShowCommand creates a new cobra.Command for `docker config-file show`
+func newShowCommand(dockerCLI command.Cli) *cobra.Command {
+	var options showOptions
+
+	cmd := &cobra.Command{
+		Use:   "show [OPTIONS]",
+		Short: "Print the configuration, merged from all configuration files",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runShow(dockerCLI, options)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&options.origin, "origin", false, "Print the configuration file that each value comes from")
+	return cmd
+}
+
+func runShow(dockerCLI command.Cli, options showOptions) error {
+	origins, err := dockerCLI.ConfigFile().Origins()
+	if err != nil {
+		return err
+	}
+	for i, o := range origins {
+		origins[i].Value = redact(o.Key, o.Value)
+	}
+
+	if !options.origin {
+		merged := make(map[string]any)
+		for _, o := range origins {
+			field, entry, ok := strings.Cut(o.Key, ".")
+			if !ok {
+				merged[field] = o.Value
+				continue
+			}
+			obj, _ := merged[field].(map[string]any)
+			if obj == nil {
+				obj = make(map[string]any)
+				merged[field] = obj
+			}
+			obj[entry] = o.Value
+		}
+		enc := json.NewEncoder(dockerCLI.Out())
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "\t")
+		return enc.Encode(merged)
+	}
+
+	w := tabwriter.NewWriter(dockerCLI.Out(), 10, 1, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KEY\tVALUE\tORIGIN")
+	for _, o := range origins {
+		var value strings.Builder
+		enc := json.NewEncoder(&value)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(o.Value); err != nil {
+			return err
+		}
+		origin := o.Origin
+		if o.Filename != "" {
+			origin += " (" + o.Filename + ")"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", o.Key, strings.TrimSpace(value.String()), origin)
+	}
+	return w.Flush()
+}
+
+// redact replaces the secrets in stored credentials, so that they are not
+// printed.
+func redact(key string, value any) any {
+	if !strings.HasPrefix(key, "auths.") {
+		return value
+	}
+	ac, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	redacted := maps.Clone(ac)
+	for _, k := range redactedFields {
+		if _, ok := redacted[k]; ok {
+			redacted[k] = "<redacted>"
+		}
+	}
+	return redacted
+}
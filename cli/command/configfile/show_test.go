@@ -0,0 +1,73 @@
+package configfile
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newShowTestCli(t *testing.T) *test.FakeCli {
+	t.Helper()
+	cfg := configfile.New(filepath.Join("home", ".docker", "config.json"))
+	cfg.PsFormat = "user"
+	cfg.AuthConfigs["registry.example.com"] = types.AuthConfig{Username: "user", Password: "secret"}
+	system, err := configfile.LoadLayer(configfile.OriginSystem, filepath.Join("etc", "config.json"), false, strings.NewReader(`{
+	"psFormat": "system",
+	"features": {"feature": "system"}
+}`))
+	assert.NilError(t, err)
+	project, err := configfile.LoadLayer(configfile.OriginProject, filepath.Join("project", ".docker", "config.json"), true, strings.NewReader(`{
+	"imagesFormat": "project"
+}`))
+	assert.NilError(t, err)
+	assert.NilError(t, cfg.SetLayers(system, project))
+	cfg.CredentialsStore = "desktop"
+
+	fakeCLI := test.NewFakeCli(nil)
+	fakeCLI.SetConfigFile(cfg)
+	return fakeCLI
+}
+
+func TestShow(t *testing.T) {
+	fakeCLI := newShowTestCli(t)
+	cmd := newShowCommand(fakeCLI)
+	cmd.SetArgs([]string{})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), `{
+	"auths": {
+		"registry.example.com": {
+			"auth": "<redacted>"
+		}
+	},
+	"credsStore": "desktop",
+	"features": {
+		"feature": "system"
+	},
+	"imagesFormat": "project",
+	"psFormat": "user"
+}
+`))
+}
+
+func TestShowOrigin(t *testing.T) {
+	fakeCLI := newShowTestCli(t)
+	cmd := newShowCommand(fakeCLI)
+	cmd.SetArgs([]string{"--origin"})
+	assert.NilError(t, cmd.Execute())
+	expected := strings.Join([]string{
+		`KEY                          VALUE                   ORIGIN`,
+		`auths.registry.example.com   {"auth":"<redacted>"}   user (` + filepath.Join("home", ".docker", "config.json") + `)`,
+		`credsStore                   "desktop"               default`,
+		`features.feature             "system"                system (` + filepath.Join("etc", "config.json") + `)`,
+		`imagesFormat                 "project"               project (` + filepath.Join("project", ".docker", "config.json") + `)`,
+		`psFormat                     "user"                  user (` + filepath.Join("home", ".docker", "config.json") + `)`,
+		``,
+	}, "\n")
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), expected))
+}
@@ -7,6 +7,7 @@
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/completion"
 	"github.com/docker/cli/cli/command/formatter"
 	flagsHelper "github.com/docker/cli/cli/flags"
 	"github.com/docker/cli/opts"
@@ -25,11 +26,13 @@ type psOptions struct {
 	last        int
 	format      string
 	filter      opts.FilterOpt
+	sort        string
+	reverse     bool
 }
 
 // newPsCommand creates a new cobra.Command for "docker container ps"
 func newPsCommand(dockerCLI command.Cli) *cobra.Command {
-	options := psOptions{filter: opts.NewFilterOpt()}
+	options := psOptions{filter: opts.NewFilterOptWithComparisons()}
 
 	cmd := &cobra.Command{
 		Use:   "ps [OPTIONS]",
@@ -57,7 +60,10 @@ func newPsCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.IntVarP(&options.last, "last", "n", -1, "Show n last created containers (includes all states)")
 	flags.StringVar(&options.format, "format", "", flagsHelper.FormatHelp)
 	flags.VarP(&options.filter, "filter", "f", "Filter output based on conditions provided")
+	flags.StringVar(&options.sort, "sort", "", `Sort output by "size", "created", or "name"`)
+	flags.BoolVar(&options.reverse, "reverse", false, "Reverse the sort order")
 
+	_ = cmd.RegisterFlagCompletionFunc("sort", completion.FromList(formatter.SortKeys...))
 	return cmd
 }
 
@@ -80,6 +86,11 @@ func buildContainerListOptions(options *psOptions) (client.ContainerListOptions,
 		listOptions.Limit = 1
 	}
 
+	// Sorting or filtering by size requires the size to be calculated.
+	if !options.quiet && !listOptions.Size && !options.sizeChanged && options.listOptions().SortsOrCompares(formatter.SortKeySize) {
+		listOptions.Size = true
+	}
+
 	// always validate template when `--format` is used, for consistency
 	if len(options.format) > 0 {
 		tmpl, err := templates.Parse(options.format)
@@ -122,6 +133,11 @@ func runPs(ctx context.Context, dockerCLI command.Cli, options *psOptions) error
 		_, _ = dockerCLI.Err().Write([]byte("WARNING: Ignoring custom format, because both --format and --quiet are set.\n"))
 	}
 
+	sortOptions := options.listOptions()
+	if err := sortOptions.Validate(); err != nil {
+		return err
+	}
+
 	listOptions, err := buildContainerListOptions(options)
 	if err != nil {
 		return err
@@ -131,11 +147,24 @@ func runPs(ctx context.Context, dockerCLI command.Cli, options *psOptions) error
 	if err != nil {
 		return err
 	}
+	containers, err := formatter.FilterAndSortContainers(res.Items, sortOptions)
+	if err != nil {
+		return err
+	}
 
 	containerCtx := formatter.Context{
 		Output: dockerCLI.Out(),
 		Format: formatter.NewContainerFormat(options.format, options.quiet, listOptions.Size),
 		Trunc:  !options.noTrunc,
 	}
-	return formatter.ContainerWrite(containerCtx, res.Items)
+	return formatter.ContainerWrite(containerCtx, containers)
+}
+
+// listOptions returns the client-side sort and comparison filter options.
+func (o *psOptions) listOptions() formatter.ListOptions {
+	return formatter.ListOptions{
+		Sort:        o.sort,
+		Reverse:     o.reverse,
+		Comparisons: o.filter.Comparisons(),
+	}
 }
@@ -344,3 +344,25 @@ func TestContainerListWithFormat(t *testing.T) {
 		golden.Assert(t, cli.OutBuffer().String(), "container-list-quiet.golden")
 	})
 }
+
+func TestContainerListSortAndCompare(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		containerListFunc: func(options client.ContainerListOptions) (client.ContainerListResult, error) {
+			// Filtering on size must request the size to be calculated.
+			assert.Check(t, options.Size)
+			return client.ContainerListResult{
+				Items: []container.Summary{
+					*builders.Container("c1", builders.WithSize(100)),
+					*builders.Container("c2", builders.WithSize(3000)),
+					*builders.Container("c3", builders.WithSize(2000)),
+				},
+			}, nil
+		},
+	})
+	cmd := newListCommand(cli)
+	cmd.SetArgs([]string{"--filter", "size>1kB", "--sort", "size", "--format", "{{.Names}}"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "c2\nc3\n"))
+}
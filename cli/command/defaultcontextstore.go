@@ -21,6 +21,12 @@
 	// that's set in the CLI's configuration file, but takes no effect if the
 	// "DOCKER_HOST" env-var is set (which takes precedence.
 	EnvOverrideContext = "DOCKER_CONTEXT"
+
+	// EnvOverrideProfile is the name of the environment variable that can be
+	// used to select the configuration profile to use. If set, it overrides
+	// the profile that's set in the CLI's configuration file, but can be
+	// overridden by the "--profile" command line option.
+	EnvOverrideProfile = "DOCKER_PROFILE"
 )
 
 // DefaultContext contains the default context data for all endpoints
@@ -0,0 +1,255 @@
+package formatter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/opts"
+	"github.com/docker/go-units"
+	"github.com/fvbommel/sortorder"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/api/types/volume"
+)
+
+// Fields that can be used to sort lists, and to compare in comparison
+// filters (for fields other than "name").
+const (
+	SortKeySize    = "size"
+	SortKeyCreated = "created"
+	SortKeyName    = "name"
+)
+
+// SortKeys is the list of keys accepted by [ListOptions.Sort].
+var SortKeys = []string{SortKeySize, SortKeyCreated, SortKeyName}
+
+// ListOptions holds client-side options to sort and filter the items of a list
+// before they are written by the formatter. Sorting and filtering happen on
+// the same values that are presented through the formatter's context, so that
+// both table output and custom formats are consistent.
+type ListOptions struct {
+	// Sort is the field to sort by; one of [SortKeys]. Items are sorted
+	// largest first for "size", newest first for "created", and
+	// alphabetically for "name".
+	Sort string
+	// Reverse reverses the sort order.
+	Reverse bool
+	// Comparisons is a list of comparison filters to apply, such as
+	// "size>500MB" or "created<72h".
+	Comparisons []opts.ComparisonFilter
+}
+
+// Validate validates the sort key and comparison filters.
+func (o ListOptions) Validate() error {
+	if o.Sort != "" && !slices.Contains(SortKeys, o.Sort) {
+		return fmt.Errorf("invalid sort key %q: must be one of %s", o.Sort, strings.Join(SortKeys, ", "))
+	}
+	if o.Reverse && o.Sort == "" {
+		return errors.New("--reverse requires --sort to be set")
+	}
+	_, err := o.matcher(time.Now())
+	return err
+}
+
+// SortsOrCompares returns whether the given field is used either as sort key,
+// or in one of the comparison filters.
+func (o ListOptions) SortsOrCompares(field string) bool {
+	if o.Sort == field {
+		return true
+	}
+	for _, c := range o.Comparisons {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterAndSortImages applies the list options to the given images.
+func FilterAndSortImages(images []image.Summary, o ListOptions) ([]image.Summary, error) {
+	return applyListOptions(images, o, func(img image.Summary) listAttributes {
+		name := "<none>"
+		if len(img.RepoTags) > 0 {
+			name = slices.Min(img.RepoTags)
+		}
+		var created time.Time
+		if img.Created > 0 {
+			created = time.Unix(img.Created, 0)
+		}
+		return listAttributes{name: name, size: img.Size, created: created}
+	})
+}
+
+// FilterAndSortContainers applies the list options to the given containers. The
+// size of a container is the size of its writable layer, and is only known if
+// the containers were listed with their size.
+func FilterAndSortContainers(containers []container.Summary, o ListOptions) ([]container.Summary, error) {
+	return applyListOptions(containers, o, func(c container.Summary) listAttributes {
+		var name string
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		return listAttributes{name: name, size: c.SizeRw, created: time.Unix(c.Created, 0)}
+	})
+}
+
+// FilterAndSortVolumes applies the list options to the given volumes. The size
+// of a volume is only known if the daemon provides usage data for it.
+func FilterAndSortVolumes(volumes []volume.Volume, o ListOptions) ([]volume.Volume, error) {
+	return applyListOptions(volumes, o, func(v volume.Volume) listAttributes {
+		size := int64(-1)
+		if v.UsageData != nil {
+			size = v.UsageData.Size
+		}
+		created, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		return listAttributes{name: v.Name, size: size, created: created}
+	})
+}
+
+// listAttributes are the attributes of a list item to sort and filter on.
+type listAttributes struct {
+	name    string
+	size    int64     // size in bytes, or -1 if unknown.
+	created time.Time // creation time, or zero if unknown.
+}
+
+func applyListOptions[T any](items []T, o ListOptions, attrs func(T) listAttributes) ([]T, error) {
+	if o.Sort == "" && len(o.Comparisons) == 0 {
+		return items, nil
+	}
+	match, err := o.matcher(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		item  T
+		attrs listAttributes
+	}
+	entries := make([]entry, 0, len(items))
+	for _, item := range items {
+		a := attrs(item)
+		if match(a) {
+			entries = append(entries, entry{item: item, attrs: a})
+		}
+	}
+
+	if o.Sort != "" {
+		slices.SortStableFunc(entries, func(a, b entry) int {
+			var n int
+			switch o.Sort {
+			case SortKeySize:
+				n = cmp.Compare(b.attrs.size, a.attrs.size)
+			case SortKeyCreated:
+				n = b.attrs.created.Compare(a.attrs.created)
+			case SortKeyName:
+				switch {
+				case a.attrs.name == b.attrs.name:
+					n = 0
+				case sortorder.NaturalLess(a.attrs.name, b.attrs.name):
+					n = -1
+				default:
+					n = 1
+				}
+			}
+			if o.Reverse {
+				n = -n
+			}
+			return n
+		})
+	}
+
+	out := make([]T, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.item)
+	}
+	return out, nil
+}
+
+// matcher returns a function that returns whether the given attributes
+// match all comparison filters.
+func (o ListOptions) matcher(now time.Time) (func(listAttributes) bool, error) {
+	var matchers []func(listAttributes) bool
+	for _, c := range o.Comparisons {
+		m, err := comparisonMatcher(c, now)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return func(a listAttributes) bool {
+		for _, m := range matchers {
+			if !m(a) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// comparisonMatcher returns a matcher for a comparison filter;
+//
+//   - "size" compares the size in bytes with a human-readable size, such as
+//     "500MB". Items with an unknown size never match.
+//   - "created" compares the age of the item if the value is a duration (so
+//     "created<72h" matches items created less than 72 hours ago), or the
+//     creation time if the value is a timestamp (so "created<2024-01-01"
+//     matches items created before 2024). Items with an unknown creation time
+//     never match.
+func comparisonMatcher(c opts.ComparisonFilter, now time.Time) (func(listAttributes) bool, error) {
+	switch c.Field {
+	case SortKeySize:
+		v, err := units.FromHumanSize(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter '%s': %w", c, err)
+		}
+		return func(a listAttributes) bool {
+			return a.size >= 0 && compareOp(c.Operator, cmp.Compare(a.size, v))
+		}, nil
+	case SortKeyCreated:
+		if d, err := time.ParseDuration(c.Value); err == nil {
+			return func(a listAttributes) bool {
+				return !a.created.IsZero() && compareOp(c.Operator, cmp.Compare(now.Sub(a.created), d))
+			}, nil
+		}
+		ts, err := parseTimestamp(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter '%s': value must be a duration (e.g. 72h) or a timestamp (e.g. 2006-01-02)", c)
+		}
+		return func(a listAttributes) bool {
+			return !a.created.IsZero() && compareOp(c.Operator, a.created.Compare(ts))
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid filter '%s': comparison is only supported for %s and %s", c, SortKeySize, SortKeyCreated)
+	}
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp: %s", value)
+}
+
+// compareOp returns whether the result of a comparison (-1, 0, or 1)
+// satisfies the operator.
+func compareOp(op string, result int) bool {
+	switch op {
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,151 @@
+package formatter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/cli/opts"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/api/types/volume"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestListOptionsValidate(t *testing.T) {
+	tests := []struct {
+		doc         string
+		options     ListOptions
+		expectedErr string
+	}{
+		{
+			doc: "empty",
+		},
+		{
+			doc:     "valid",
+			options: ListOptions{Sort: "size", Reverse: true, Comparisons: []opts.ComparisonFilter{{Field: "size", Operator: ">", Value: "500MB"}, {Field: "created", Operator: "<", Value: "2024-01-01"}}},
+		},
+		{
+			doc:         "invalid sort key",
+			options:     ListOptions{Sort: "color"},
+			expectedErr: `invalid sort key "color": must be one of size, created, name`,
+		},
+		{
+			doc:         "reverse without sort",
+			options:     ListOptions{Reverse: true},
+			expectedErr: "--reverse requires --sort to be set",
+		},
+		{
+			doc:         "invalid size",
+			options:     ListOptions{Comparisons: []opts.ComparisonFilter{{Field: "size", Operator: ">", Value: "big"}}},
+			expectedErr: "invalid filter 'size>big'",
+		},
+		{
+			doc:         "invalid created",
+			options:     ListOptions{Comparisons: []opts.ComparisonFilter{{Field: "created", Operator: "<", Value: "yesterday"}}},
+			expectedErr: "invalid filter 'created<yesterday': value must be a duration (e.g. 72h) or a timestamp (e.g. 2006-01-02)",
+		},
+		{
+			doc:         "unsupported field",
+			options:     ListOptions{Comparisons: []opts.ComparisonFilter{{Field: "name", Operator: ">", Value: "foo"}}},
+			expectedErr: "invalid filter 'name>foo': comparison is only supported for size and created",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			err := tc.options.Validate()
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+			} else {
+				assert.Check(t, err)
+			}
+		})
+	}
+}
+
+func TestFilterAndSortImages(t *testing.T) {
+	now := time.Now()
+	images := []image.Summary{
+		{ID: "a", RepoTags: []string{"b:latest"}, Size: 600_000_000, Created: now.Add(-100 * time.Hour).Unix()},
+		{ID: "b", RepoTags: []string{"c:latest", "a:latest"}, Size: 100_000_000, Created: now.Add(-1 * time.Hour).Unix()},
+		{ID: "c", Size: 900_000_000, Created: now.Add(-10 * time.Hour).Unix()},
+	}
+	ids := func(images []image.Summary) []string {
+		var out []string
+		for _, img := range images {
+			out = append(out, img.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		doc      string
+		options  ListOptions
+		expected []string
+	}{
+		{doc: "no options", expected: []string{"a", "b", "c"}},
+		{doc: "sort by size", options: ListOptions{Sort: "size"}, expected: []string{"c", "a", "b"}},
+		{doc: "sort by size reversed", options: ListOptions{Sort: "size", Reverse: true}, expected: []string{"b", "a", "c"}},
+		{doc: "sort by created", options: ListOptions{Sort: "created"}, expected: []string{"b", "c", "a"}},
+		{doc: "sort by name", options: ListOptions{Sort: "name"}, expected: []string{"c", "b", "a"}},
+		{
+			doc:      "size greater than",
+			options:  ListOptions{Sort: "name", Comparisons: []opts.ComparisonFilter{{Field: "size", Operator: ">", Value: "500MB"}}},
+			expected: []string{"c", "a"},
+		},
+		{
+			doc:      "created less than duration",
+			options:  ListOptions{Comparisons: []opts.ComparisonFilter{{Field: "created", Operator: "<", Value: "72h"}}},
+			expected: []string{"b", "c"},
+		},
+		{
+			doc: "combined",
+			options: ListOptions{Comparisons: []opts.ComparisonFilter{
+				{Field: "created", Operator: "<=", Value: "72h"},
+				{Field: "size", Operator: ">=", Value: "900MB"},
+			}},
+			expected: []string{"c"},
+		},
+		{
+			doc:      "created before timestamp",
+			options:  ListOptions{Comparisons: []opts.ComparisonFilter{{Field: "created", Operator: "<", Value: now.Add(-50 * time.Hour).Format(time.RFC3339)}}},
+			expected: []string{"a"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			out, err := FilterAndSortImages(images, tc.options)
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(ids(out), tc.expected))
+		})
+	}
+}
+
+func TestFilterAndSortContainers(t *testing.T) {
+	containers := []container.Summary{
+		{ID: "1", Names: []string{"/web"}, SizeRw: 10, Created: 300},
+		{ID: "2", Names: []string{"/db"}, SizeRw: 2000, Created: 100},
+		{ID: "3", Names: []string{"/cache"}, SizeRw: 0, Created: 200},
+	}
+	out, err := FilterAndSortContainers(containers, ListOptions{Sort: "name", Comparisons: []opts.ComparisonFilter{{Field: "size", Operator: "<", Value: "1kB"}}})
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(out, 2))
+	assert.Check(t, is.Equal(out[0].ID, "3"))
+	assert.Check(t, is.Equal(out[1].ID, "1"))
+}
+
+func TestFilterAndSortVolumes(t *testing.T) {
+	volumes := []volume.Volume{
+		{Name: "no-usage", CreatedAt: "2024-01-01T00:00:00Z"},
+		{Name: "small", CreatedAt: "2024-03-01T00:00:00Z", UsageData: &volume.UsageData{Size: 10}},
+		{Name: "large", CreatedAt: "2024-02-01T00:00:00Z", UsageData: &volume.UsageData{Size: 1000}},
+	}
+	out, err := FilterAndSortVolumes(volumes, ListOptions{Sort: "created", Reverse: true})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{out[0].Name, out[1].Name, out[2].Name}, []string{"no-usage", "large", "small"}))
+
+	// volumes without usage data never match size comparisons.
+	out, err = FilterAndSortVolumes(volumes, ListOptions{Comparisons: []opts.ComparisonFilter{{Field: "size", Operator: ">=", Value: "0"}}})
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(out, 2))
+}
@@ -0,0 +1,397 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/command/formatter"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/registryclient"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// mediaTypeInToto is the media type of the layers of an attestation
+	// manifest, which are in-toto statements.
+	mediaTypeInToto = "application/vnd.in-toto+json"
+
+	// Kinds of attestations, as accepted by "docker image attestations --type".
+	attestationKindSBOM       = "sbom"
+	attestationKindProvenance = "provenance"
+)
+
+type attestationsOptions struct {
+	image           string
+	remote          bool
+	insecure        bool
+	platform        string
+	attestationType string
+	output          string
+	format          string
+}
+
+// newAttestationsCommand creates a new "docker image attestations" command.
+func newAttestationsCommand(dockerCLI command.Cli) *cobra.Command {
+	var opts attestationsOptions
+
+	cmd := &cobra.Command{
+		Use:   "attestations [OPTIONS] IMAGE",
+		Short: "Show the SBOM and provenance attestations of an image",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.image = args[0]
+			return runAttestations(cmd.Context(), dockerCLI, opts)
+		},
+		ValidArgsFunction:     completion.ImageNames(dockerCLI, 1),
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.remote, "remote", false, "Show the attestations of the image in its registry, without pulling it")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry (with --remote)")
+	flags.StringVar(&opts.platform, "platform", "", `Only show the attestations of the given platform (e.g., "linux/amd64")`)
+	flags.StringVar(&opts.attestationType, "type", "", `Only show attestations of the given type ("`+attestationKindSBOM+`", "`+attestationKindProvenance+`", or a predicate type)`)
+	flags.StringVarP(&opts.output, "output", "o", "", "Write the predicates of the attestations to a directory")
+	flags.StringVar(&opts.format, "format", "", flagsHelper.FormatHelp)
+
+	_ = cmd.RegisterFlagCompletionFunc("platform", completion.Platforms())
+	_ = cmd.RegisterFlagCompletionFunc("type", completion.FromList(attestationKindSBOM, attestationKindProvenance))
+	return cmd
+}
+
+// attestation is an in-toto statement in an attestation manifest.
+type attestation struct {
+	platform      ocispec.Platform
+	subject       digest.Digest // digest of the image manifest it is for
+	manifest      digest.Digest // digest of the attestation manifest
+	layer         ocispec.Descriptor
+	predicateType string
+	predicate     json.RawMessage
+}
+
+// inTotoStatement is an in-toto statement, as stored in the layers of an
+// attestation manifest.
+type inTotoStatement struct {
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// contentFetcher returns the content of a manifest or blob of an image.
+type contentFetcher func(ctx context.Context, desc ocispec.Descriptor) ([]byte, error)
+
+func runAttestations(ctx context.Context, dockerCLI command.Cli, opts attestationsOptions) error {
+	var platform *ocispec.Platform
+	if opts.platform != "" {
+		p, err := platforms.Parse(opts.platform)
+		if err != nil {
+			return fmt.Errorf("invalid platform: %w", err)
+		}
+		platform = &p
+	}
+
+	var (
+		root  ocispec.Descriptor
+		fetch contentFetcher
+	)
+	if opts.remote {
+		var err error
+		root, fetch, err = remoteContent(ctx, command.NewRegistryClient(dockerCLI, opts.insecure), opts.image)
+		if err != nil {
+			return err
+		}
+	} else {
+		if opts.insecure {
+			return errors.New("--insecure can only be used with --remote")
+		}
+		dir, err := os.MkdirTemp("", "docker-attestations-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		root, fetch, err = localContent(ctx, dockerCLI, opts.image, dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	attestations, err := fetchAttestations(ctx, fetch, root)
+	if err != nil {
+		return err
+	}
+	filtered := attestations[:0]
+	for _, a := range attestations {
+		if platform != nil && !platforms.OnlyStrict(*platform).Match(a.platform) {
+			continue
+		}
+		if opts.attestationType != "" && opts.attestationType != a.predicateType && opts.attestationType != attestationKind(a.predicateType) {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	if len(filtered) == 0 {
+		return errdefs.ErrNotFound.WithMessage("no attestations found for " + opts.image)
+	}
+
+	if opts.output != "" {
+		if err := writePredicates(opts.output, filtered); err != nil {
+			return err
+		}
+	}
+
+	attestationsCtx := formatter.Context{
+		Output: dockerCLI.Out(),
+		Format: newAttestationsFormat(opts.format),
+	}
+	return attestationsFormatWrite(attestationsCtx, filtered)
+}
+
+// remoteContent returns the descriptor of the image in its registry, and a
+// function to fetch its content from the registry.
+func remoteContent(ctx context.Context, registryClient registryclient.RegistryClient, ref string) (ocispec.Descriptor, contentFetcher, error) {
+	namedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	namedRef = reference.TagNameOnly(namedRef)
+	repo := reference.TrimNamed(namedRef)
+
+	desc, _, err := registryClient.GetRawManifest(ctx, namedRef)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, func(ctx context.Context, desc ocispec.Descriptor) ([]byte, error) {
+		if !isIndexMediaType(desc.MediaType) && !isManifestMediaType(desc.MediaType) {
+			return registryClient.GetBlob(ctx, repo, desc.Digest)
+		}
+		manifestRef, err := reference.WithDigest(repo, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		_, raw, err := registryClient.GetRawManifest(ctx, manifestRef)
+		return raw, err
+	}, nil
+}
+
+// localContent saves the image to an OCI image layout in dir, and returns a
+// descriptor of the index of the layout, and a function to read its content.
+// Attestations are only stored by the containerd image store.
+func localContent(ctx context.Context, dockerCLI command.Cli, ref string, dir string) (ocispec.Descriptor, contentFetcher, error) {
+	responseBody, err := dockerCLI.Client().ImageSave(ctx, []string{ref})
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	defer responseBody.Close()
+
+	if err := writeOCILayout(dir, responseBody); err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to export image %s: %w", ref, err)
+	}
+	root := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex}
+	return root, func(_ context.Context, desc ocispec.Descriptor) ([]byte, error) {
+		if desc.Digest == "" {
+			return os.ReadFile(filepath.Join(dir, ocispec.ImageIndexFile))
+		}
+		if err := desc.Digest.Validate(); err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(filepath.Join(dir, ocispec.ImageBlobsDir, desc.Digest.Algorithm().String(), desc.Digest.Encoded()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", desc.Digest, err)
+		}
+		return b, nil
+	}, nil
+}
+
+// fetchAttestations returns the attestations in the attestation manifests
+// of the image index (and any image indexes it refers to), in the order of
+// the manifests in the index. An image without an index has no attestations.
+func fetchAttestations(ctx context.Context, fetch contentFetcher, root ocispec.Descriptor) ([]attestation, error) {
+	if !isIndexMediaType(root.MediaType) {
+		return nil, nil
+	}
+	raw, err := fetch(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return nil, fmt.Errorf("invalid image index %s: %w", root.Digest, err)
+	}
+
+	var (
+		attestations   []attestation
+		imagePlatforms = make(map[digest.Digest]ocispec.Platform)
+	)
+	for _, desc := range index.Manifests {
+		if desc.Platform != nil && desc.Annotations[manifesttypes.AnnotationReferenceType] != manifesttypes.AttestationManifestType {
+			imagePlatforms[desc.Digest] = *desc.Platform
+		}
+	}
+	for _, desc := range index.Manifests {
+		switch {
+		case isIndexMediaType(desc.MediaType):
+			nested, err := fetchAttestations(ctx, fetch, desc)
+			if err != nil {
+				return nil, err
+			}
+			attestations = append(attestations, nested...)
+		case desc.Annotations[manifesttypes.AnnotationReferenceType] == manifesttypes.AttestationManifestType:
+			subject := digest.Digest(desc.Annotations[manifesttypes.AnnotationReferenceDigest])
+			platform, ok := imagePlatforms[subject]
+			if !ok {
+				platform = ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+			}
+			found, err := fetchAttestationManifest(ctx, fetch, desc)
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range found {
+				a.platform, a.subject = platform, subject
+				attestations = append(attestations, a)
+			}
+		}
+	}
+	return attestations, nil
+}
+
+// fetchAttestationManifest fetches the in-toto statements in the layers of an
+// attestation manifest.
+func fetchAttestationManifest(ctx context.Context, fetch contentFetcher, desc ocispec.Descriptor) ([]attestation, error) {
+	raw, err := fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid attestation manifest %s: %w", desc.Digest, err)
+	}
+	var attestations []attestation
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != mediaTypeInToto {
+			continue
+		}
+		b, err := fetch(ctx, layer)
+		if err != nil {
+			return nil, err
+		}
+		var statement inTotoStatement
+		if err := json.Unmarshal(b, &statement); err != nil {
+			return nil, fmt.Errorf("invalid in-toto statement %s: %w", layer.Digest, err)
+		}
+		attestations = append(attestations, attestation{
+			manifest:      desc.Digest,
+			layer:         layer,
+			predicateType: statement.PredicateType,
+			predicate:     statement.Predicate,
+		})
+	}
+	return attestations, nil
+}
+
+// attestationKind returns the kind of attestation for a predicate type, or
+// an empty string if it is not a known kind.
+func attestationKind(predicateType string) string {
+	switch {
+	case strings.HasPrefix(predicateType, "https://spdx.dev/Document"),
+		strings.HasPrefix(predicateType, "https://cyclonedx.org/bom"):
+		return attestationKindSBOM
+	case strings.HasPrefix(predicateType, "https://slsa.dev/provenance/"):
+		return attestationKindProvenance
+	default:
+		return ""
+	}
+}
+
+// summary returns a short, human-readable summary of the predicate of a
+// known predicate type, or an empty string otherwise.
+func (a attestation) summary() string {
+	switch {
+	case strings.HasPrefix(a.predicateType, "https://spdx.dev/Document"):
+		var p struct {
+			SPDXVersion string            `json:"spdxVersion"`
+			Packages    []json.RawMessage `json:"packages"`
+		}
+		if json.Unmarshal(a.predicate, &p) != nil {
+			return ""
+		}
+		return fmt.Sprintf("%s, %d packages", p.SPDXVersion, len(p.Packages))
+	case strings.HasPrefix(a.predicateType, "https://cyclonedx.org/bom"):
+		var p struct {
+			SpecVersion string            `json:"specVersion"`
+			Components  []json.RawMessage `json:"components"`
+		}
+		if json.Unmarshal(a.predicate, &p) != nil {
+			return ""
+		}
+		return fmt.Sprintf("CycloneDX %s, %d components", p.SpecVersion, len(p.Components))
+	case a.predicateType == "https://slsa.dev/provenance/v0.2":
+		var p struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Materials []json.RawMessage `json:"materials"`
+		}
+		if json.Unmarshal(a.predicate, &p) != nil {
+			return ""
+		}
+		return fmt.Sprintf("SLSA v0.2, built by %s, %d materials", p.Builder.ID, len(p.Materials))
+	case a.predicateType == "https://slsa.dev/provenance/v1":
+		var p struct {
+			BuildDefinition struct {
+				ResolvedDependencies []json.RawMessage `json:"resolvedDependencies"`
+			} `json:"buildDefinition"`
+			RunDetails struct {
+				Builder struct {
+					ID string `json:"id"`
+				} `json:"builder"`
+			} `json:"runDetails"`
+		}
+		if json.Unmarshal(a.predicate, &p) != nil {
+			return ""
+		}
+		return fmt.Sprintf("SLSA v1, built by %s, %d dependencies", p.RunDetails.Builder.ID, len(p.BuildDefinition.ResolvedDependencies))
+	default:
+		return ""
+	}
+}
+
+// writePredicates writes the predicate of each attestation to a file in a
+// subdirectory of dir that is named after its platform, such as
+// "linux_amd64/sbom.json". If a platform has more than one attestation of the
+// same kind, a number is added to the name of the file.
+func writePredicates(dir string, attestations []attestation) error {
+	seen := make(map[string]int)
+	for _, a := range attestations {
+		platformDir := strings.ReplaceAll(platforms.Format(a.platform), "/", "_")
+		name := attestationKind(a.predicateType)
+		if name == "" {
+			name = "attestation"
+		}
+		key := platformDir + "/" + name
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			name += "-" + strconv.Itoa(n)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, platformDir), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, platformDir, name+".json"), a.predicate, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/test"
+	"github.com/moby/moby/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+const (
+	testSPDX       = `{"spdxVersion":"SPDX-2.3","packages":[{"name":"busybox"},{"name":"musl"}]}`
+	testProvenance = `{"builder":{"id":"https://example.com/builder"},"materials":[{"uri":"pkg:docker/alpine"}]}`
+)
+
+// addTestAttestation adds an attestation manifest for the image with the
+// given in-toto predicates, by predicate type, and returns its descriptor.
+func addTestAttestation(t *testing.T, registry *fakeRegistryClient, subject ocispec.Descriptor, predicates map[string]string) ocispec.Descriptor {
+	t.Helper()
+	var layers []ocispec.Descriptor
+	for _, predicateType := range []string{"https://spdx.dev/Document", "https://slsa.dev/provenance/v0.2"} {
+		predicate, ok := predicates[predicateType]
+		if !ok {
+			continue
+		}
+		layers = append(layers, registry.addBlob(mediaTypeInToto, map[string]any{
+			"_type":         "https://in-toto.io/Statement/v0.1",
+			"predicateType": predicateType,
+			"predicate":     json.RawMessage(predicate),
+		}))
+	}
+	config := registry.addBlob(ocispec.MediaTypeImageConfig, ocispec.Image{})
+	desc := registry.addManifest("", ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    layers,
+	})
+	desc.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+	desc.Annotations = map[string]string{
+		manifesttypes.AnnotationReferenceType:   manifesttypes.AttestationManifestType,
+		manifesttypes.AnnotationReferenceDigest: subject.Digest.String(),
+	}
+	return desc
+}
+
+// newAttestationsRegistry returns a registry with a multi-platform image,
+// tagged "example.com/app:latest", that has an SBOM and provenance for
+// linux/amd64, and only provenance for linux/arm64.
+func newAttestationsRegistry(t *testing.T) (*fakeRegistryClient, ocispec.Descriptor) {
+	t.Helper()
+	registry := newFakeRegistryClient()
+	amd64 := addTestImage(registry, ocispec.Platform{OS: "linux", Architecture: "amd64"}, "amd64 layer")
+	arm64 := addTestImage(registry, ocispec.Platform{OS: "linux", Architecture: "arm64"}, "arm64 layer")
+	index := registry.addManifest("example.com/app:latest", ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{
+			amd64,
+			arm64,
+			addTestAttestation(t, registry, amd64, map[string]string{
+				"https://spdx.dev/Document":        testSPDX,
+				"https://slsa.dev/provenance/v0.2": testProvenance,
+			}),
+			addTestAttestation(t, registry, arm64, map[string]string{
+				"https://slsa.dev/provenance/v0.2": testProvenance,
+			}),
+		},
+	})
+	return registry, index
+}
+
+// makeLayoutArchive returns an image archive, as produced by "docker save",
+// with the content of the registry and an index.json that refers to the
+// given index.
+func makeLayoutArchive(t *testing.T, registry *fakeRegistryClient, index ocispec.Descriptor) []byte {
+	t.Helper()
+	layoutIndex, err := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{index},
+	})
+	assert.NilError(t, err)
+	files := map[string][]byte{
+		ocispec.ImageLayoutFile: []byte(`{"imageLayoutVersion":"1.0.0"}`),
+		ocispec.ImageIndexFile:  layoutIndex,
+	}
+	for dgst, b := range registry.content {
+		files["blobs/"+dgst.Algorithm().String()+"/"+dgst.Encoded()] = b
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(content)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestAttestations(t *testing.T) {
+	registry, index := newAttestationsRegistry(t)
+	archive := makeLayoutArchive(t, registry, index)
+
+	testCases := []struct {
+		name        string
+		args        []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "remote",
+			args: []string{"--remote", "example.com/app"},
+			expected: `PLATFORM      TYPE         SUMMARY
+linux/amd64   sbom         SPDX-2.3, 2 packages
+linux/amd64   provenance   SLSA v0.2, built by https://example.com/builder, 1 materials
+linux/arm64   provenance   SLSA v0.2, built by https://example.com/builder, 1 materials
+`,
+		},
+		{
+			name: "local",
+			args: []string{"example.com/app"},
+			expected: `PLATFORM      TYPE         SUMMARY
+linux/amd64   sbom         SPDX-2.3, 2 packages
+linux/amd64   provenance   SLSA v0.2, built by https://example.com/builder, 1 materials
+linux/arm64   provenance   SLSA v0.2, built by https://example.com/builder, 1 materials
+`,
+		},
+		{
+			name:     "platform",
+			args:     []string{"--remote", "--platform", "linux/arm64", "--format", "{{.Platform}} {{.PredicateType}}", "example.com/app"},
+			expected: "linux/arm64 https://slsa.dev/provenance/v0.2\n",
+		},
+		{
+			name:     "type",
+			args:     []string{"--type", "sbom", "--format", "{{.Platform}} {{.Type}}", "example.com/app"},
+			expected: "linux/amd64 sbom\n",
+		},
+		{
+			name:     "predicate type",
+			args:     []string{"--remote", "--type", "https://slsa.dev/provenance/v0.2", "--format", "{{.Platform}}", "example.com/app"},
+			expected: "linux/amd64\nlinux/arm64\n",
+		},
+		{
+			name:        "no match",
+			args:        []string{"--remote", "--platform", "linux/s390x", "example.com/app"},
+			expectedErr: "no attestations found for example.com/app",
+		},
+		{
+			name:        "insecure without remote",
+			args:        []string{"--insecure", "example.com/app"},
+			expectedErr: "--insecure can only be used with --remote",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{
+				imageSaveFunc: func(images []string, _ ...client.ImageSaveOption) (client.ImageSaveResult, error) {
+					assert.Check(t, is.DeepEqual(images, []string{"example.com/app"}))
+					return io.NopCloser(bytes.NewReader(archive)), nil
+				},
+			})
+			cli.SetRegistryClient(registry)
+			cmd := newAttestationsCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			err := cmd.Execute()
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+		})
+	}
+}
+
+func TestAttestationsOutput(t *testing.T) {
+	registry, _ := newAttestationsRegistry(t)
+	dir := t.TempDir()
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetRegistryClient(registry)
+	cmd := newAttestationsCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--remote", "-o", dir, "example.com/app"})
+	assert.NilError(t, cmd.Execute())
+
+	for name, expected := range map[string]string{
+		"linux_amd64/sbom.json":       testSPDX,
+		"linux_amd64/provenance.json": testProvenance,
+		"linux_arm64/provenance.json": testProvenance,
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(string(b), expected), name)
+	}
+}
+
+func TestAttestationsSinglePlatform(t *testing.T) {
+	registry := newFakeRegistryClient()
+	desc := addTestImage(registry, ocispec.Platform{OS: "linux", Architecture: "amd64"}, "layer")
+	registry.manifests["example.com/app:latest"] = desc
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetRegistryClient(registry)
+	cmd := newAttestationsCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--remote", "example.com/app"})
+	assert.Check(t, is.ErrorContains(cmd.Execute(), "no attestations found"))
+}
@@ -1,31 +1,41 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/internal/registryclient"
 	"github.com/moby/moby/api/types/image"
 	"github.com/moby/moby/client"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type fakeClient struct {
 	client.Client
-	imageTagFunc     func(options client.ImageTagOptions) (client.ImageTagResult, error)
-	imageSaveFunc    func(images []string, options ...client.ImageSaveOption) (client.ImageSaveResult, error)
-	imageRemoveFunc  func(image string, options client.ImageRemoveOptions) (client.ImageRemoveResult, error)
-	imagePushFunc    func(ref string, options client.ImagePushOptions) (client.ImagePushResponse, error)
-	infoFunc         func() (client.SystemInfoResult, error)
-	imagePullFunc    func(ref string, options client.ImagePullOptions) (client.ImagePullResponse, error)
-	imagePruneFunc   func(options client.ImagePruneOptions) (client.ImagePruneResult, error)
-	imageLoadFunc    func(input io.Reader, options ...client.ImageLoadOption) (client.ImageLoadResult, error)
-	imageListFunc    func(options client.ImageListOptions) (client.ImageListResult, error)
-	imageInspectFunc func(img string) (client.ImageInspectResult, error)
-	imageImportFunc  func(source client.ImageImportSource, ref string, options client.ImageImportOptions) (client.ImageImportResult, error)
-	imageHistoryFunc func(img string, options ...client.ImageHistoryOption) (client.ImageHistoryResult, error)
-	imageBuildFunc   func(context.Context, io.Reader, client.ImageBuildOptions) (client.ImageBuildResult, error)
+	imageTagFunc      func(options client.ImageTagOptions) (client.ImageTagResult, error)
+	imageSaveFunc     func(images []string, options ...client.ImageSaveOption) (client.ImageSaveResult, error)
+	imageRemoveFunc   func(image string, options client.ImageRemoveOptions) (client.ImageRemoveResult, error)
+	imagePushFunc     func(ref string, options client.ImagePushOptions) (client.ImagePushResponse, error)
+	infoFunc          func() (client.SystemInfoResult, error)
+	imagePullFunc     func(ref string, options client.ImagePullOptions) (client.ImagePullResponse, error)
+	imagePruneFunc    func(options client.ImagePruneOptions) (client.ImagePruneResult, error)
+	imageLoadFunc     func(input io.Reader, options ...client.ImageLoadOption) (client.ImageLoadResult, error)
+	imageListFunc     func(options client.ImageListOptions) (client.ImageListResult, error)
+	imageInspectFunc  func(img string) (client.ImageInspectResult, error)
+	imageImportFunc   func(source client.ImageImportSource, ref string, options client.ImageImportOptions) (client.ImageImportResult, error)
+	imageHistoryFunc  func(img string, options ...client.ImageHistoryOption) (client.ImageHistoryResult, error)
+	imageBuildFunc    func(context.Context, io.Reader, client.ImageBuildOptions) (client.ImageBuildResult, error)
+	containerListFunc func(options client.ContainerListOptions) (client.ContainerListResult, error)
 }
 
 type fakeStreamResult struct {
@@ -130,3 +140,132 @@ func (cli *fakeClient) ImageBuild(ctx context.Context, buildContext io.Reader, o
 	}
 	return client.ImageBuildResult{Body: io.NopCloser(strings.NewReader(""))}, nil
 }
+
+func (cli *fakeClient) ContainerList(_ context.Context, options client.ContainerListOptions) (client.ContainerListResult, error) {
+	if cli.containerListFunc != nil {
+		return cli.containerListFunc(options)
+	}
+	return client.ContainerListResult{}, nil
+}
+
+// fakeRegistryClient is a registry client that serves manifests and blobs
+// from memory. Manifests are stored by their reference and by their digest.
+//
+// Blobs and manifests that are pushed are recorded, and are not served.
+type fakeRegistryClient struct {
+	registryclient.RegistryClient
+	manifests map[string]ocispec.Descriptor
+	content   map[digest.Digest][]byte
+
+	mu              sync.Mutex
+	existingBlobs   map[string]bool   // "name@digest" of blobs that exist in the target
+	mountedBlobs    []string          // "name@digest" of mounted blobs
+	pushedBlobs     []string          // "name@digest" of pushed blobs
+	pushedManifests map[string][]byte // pushed manifests by reference
+}
+
+func newFakeRegistryClient() *fakeRegistryClient {
+	return &fakeRegistryClient{
+		manifests:       make(map[string]ocispec.Descriptor),
+		content:         make(map[digest.Digest][]byte),
+		existingBlobs:   make(map[string]bool),
+		pushedManifests: make(map[string][]byte),
+	}
+}
+
+// addBlob adds a blob, and returns its descriptor.
+func (c *fakeRegistryClient) addBlob(mediaType string, v any) ocispec.Descriptor {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	dgst := digest.FromBytes(b)
+	c.content[dgst] = b
+	return ocispec.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(b))}
+}
+
+// addManifest adds a manifest or index, tagged with the given (normalized)
+// reference if it is not empty, and returns its descriptor.
+func (c *fakeRegistryClient) addManifest(ref string, mediaType string, v any) ocispec.Descriptor {
+	desc := c.addBlob(mediaType, v)
+	c.manifests[desc.Digest.String()] = desc
+	if ref != "" {
+		c.manifests[ref] = desc
+	}
+	return desc
+}
+
+func (c *fakeRegistryClient) GetRawManifest(_ context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+	key := ref.String()
+	if canonical, ok := ref.(reference.Canonical); ok {
+		key = canonical.Digest().String()
+	}
+	desc, ok := c.manifests[key]
+	if !ok {
+		return ocispec.Descriptor{}, nil, errdefs.ErrNotFound.WithMessage("no such manifest: " + ref.String())
+	}
+	return desc, c.content[desc.Digest], nil
+}
+
+func (c *fakeRegistryClient) GetUpstreamManifest(ctx context.Context, ref reference.Named, _ ...string) (ocispec.Descriptor, []byte, error) {
+	return c.GetRawManifest(ctx, ref)
+}
+
+func (c *fakeRegistryClient) GetBlob(_ context.Context, _ reference.Named, dgst digest.Digest) ([]byte, error) {
+	b, ok := c.content[dgst]
+	if !ok {
+		return nil, errors.New("blob unknown: " + dgst.String())
+	}
+	return b, nil
+}
+
+// configSize returns the size of the config of the given image manifest.
+func (c *fakeRegistryClient) configSize(desc ocispec.Descriptor) int64 {
+	var m ocispec.Manifest
+	if err := json.Unmarshal(c.content[desc.Digest], &m); err != nil {
+		panic(err)
+	}
+	return m.Config.Size
+}
+
+func (c *fakeRegistryClient) BlobExists(_ context.Context, ref reference.Named, dgst digest.Digest) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.existingBlobs[ref.Name()+"@"+dgst.String()], nil
+}
+
+func (c *fakeRegistryClient) MountBlob(_ context.Context, source reference.Canonical, target reference.Named) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mountedBlobs = append(c.mountedBlobs, target.Name()+"@"+source.Digest().String())
+	return nil
+}
+
+func (c *fakeRegistryClient) OpenBlob(_ context.Context, _ reference.Named, dgst digest.Digest) (io.ReadCloser, error) {
+	b, ok := c.content[dgst]
+	if !ok {
+		return nil, errors.New("blob unknown: " + dgst.String())
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (c *fakeRegistryClient) PutBlob(_ context.Context, ref reference.Named, desc ocispec.Descriptor, content io.Reader) error {
+	b, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	if digest.FromBytes(b) != desc.Digest {
+		return errors.New("digest mismatch: " + desc.Digest.String())
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pushedBlobs = append(c.pushedBlobs, ref.Name()+"@"+desc.Digest.String())
+	return nil
+}
+
+func (c *fakeRegistryClient) PutRawManifest(_ context.Context, ref reference.Named, _ string, raw []byte) (digest.Digest, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pushedManifests[ref.String()] = raw
+	return digest.FromBytes(raw), nil
+}
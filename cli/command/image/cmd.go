@@ -32,12 +32,16 @@ func newImageCommand(dockerCli command.Cli) *cobra.Command {
 		DisableFlagsInUseLine: true,
 	}
 	cmd.AddCommand(
+		newAttestationsCommand(dockerCli),
 		newBuildCommand(dockerCli),
+		newCopyCommand(dockerCli),
 		newHistoryCommand(dockerCli),
 		newImportCommand(dockerCli),
 		newLoadCommand(dockerCli),
+		newOutdatedCommand(dockerCli),
 		newPullCommand(dockerCli),
 		newPushCommand(dockerCli),
+		newRetagCommand(dockerCli),
 		newSaveCommand(dockerCli),
 		newTagCommand(dockerCli),
 		newListCommand(dockerCli),
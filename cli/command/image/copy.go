@@ -0,0 +1,367 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/completion"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/registryclient"
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxConcurrentBlobCopies is the maximum number of blobs that are copied
+// concurrently by "docker image copy".
+const maxConcurrentBlobCopies = 3
+
+type copyOptions struct {
+	source    string
+	target    string
+	platforms []string
+	quiet     bool
+	insecure  bool
+}
+
+// newCopyCommand creates a new "docker image copy" command.
+func newCopyCommand(dockerCLI command.Cli) *cobra.Command {
+	var opts copyOptions
+
+	cmd := &cobra.Command{
+		Use:   "copy [OPTIONS] SOURCE_IMAGE TARGET_IMAGE",
+		Short: "Copy an image from one registry or repository to another",
+		Args:  cli.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.source = args[0]
+			opts.target = args[1]
+			return runCopy(cmd.Context(), dockerCLI, opts)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&opts.platforms, "platform", nil, `Copy only the given platforms of a multi-platform image (e.g. "linux/amd64,linux/arm64")`)
+	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Suppress progress output")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with insecure registries")
+
+	_ = cmd.RegisterFlagCompletionFunc("platform", completion.Platforms())
+	return cmd
+}
+
+func runCopy(ctx context.Context, dockerCLI command.Cli, opts copyOptions) error {
+	source, err := reference.ParseNormalizedNamed(opts.source)
+	if err != nil {
+		return err
+	}
+	source = reference.TagNameOnly(source)
+	target, err := reference.ParseNormalizedNamed(opts.target)
+	if err != nil {
+		return err
+	}
+	if _, ok := target.(reference.Digested); ok {
+		return fmt.Errorf("invalid target image %s: must not contain a digest", opts.target)
+	}
+	if reference.IsNameOnly(target) {
+		// Use the tag of the source, or push by digest if the source is
+		// referenced by digest.
+		if tagged, ok := source.(reference.NamedTagged); ok {
+			target, err = reference.WithTag(target, tagged.Tag())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var matcher platforms.MatchComparer
+	if len(opts.platforms) > 0 {
+		ps := make([]ocispec.Platform, 0, len(opts.platforms))
+		for _, p := range opts.platforms {
+			platform, err := platforms.Parse(p)
+			if err != nil {
+				return err
+			}
+			ps = append(ps, platform)
+		}
+		matcher = platforms.Any(ps...)
+	}
+
+	out := io.Discard
+	if !opts.quiet {
+		out = dockerCLI.Out()
+	}
+	c := &imageCopier{
+		registryClient: command.NewRegistryClient(dockerCLI, opts.insecure),
+		source:         reference.TrimNamed(source),
+		target:         reference.TrimNamed(target),
+		out:            out,
+	}
+	desc, err := c.copy(ctx, source, target, matcher)
+	if err != nil {
+		return err
+	}
+	if reference.IsNameOnly(target) {
+		target, err = reference.WithDigest(target, desc.Digest)
+		if err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(dockerCLI.Out(), "%s: digest: %s size: %d\n", reference.FamiliarString(target), desc.Digest, desc.Size)
+	return nil
+}
+
+// imageCopier copies images between repositories, in the same or in different
+// registries.
+type imageCopier struct {
+	registryClient registryclient.RegistryClient
+	source         reference.Named // source repository
+	target         reference.Named // target repository
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// copiedManifest is a manifest to copy, with the blobs it references.
+type copiedManifest struct {
+	desc  ocispec.Descriptor
+	raw   []byte
+	blobs []ocispec.Descriptor
+}
+
+// copy copies the image with the source reference to the target reference,
+// and returns the descriptor of the copied manifest or image index.
+//
+// If matcher is not nil, only the manifests of the matching platforms (and
+// their attestations) are copied from an image index, and a new index with
+// only those manifests is pushed. Otherwise, the image is copied as-is, and
+// its digest is preserved.
+func (c *imageCopier) copy(ctx context.Context, source, target reference.Named, matcher platforms.MatchComparer) (ocispec.Descriptor, error) {
+	desc, raw, err := c.registryClient.GetRawManifest(ctx, source)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	var manifests []copiedManifest
+	switch {
+	case isIndexMediaType(desc.MediaType):
+		var index ocispec.Index
+		if err := json.Unmarshal(raw, &index); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("invalid image index for %s: %w", reference.FamiliarString(source), err)
+		}
+		selected := selectManifests(index.Manifests, matcher)
+		if len(selected) == 0 {
+			return ocispec.Descriptor{}, fmt.Errorf("image %s does not provide any of the specified platforms", reference.FamiliarString(source))
+		}
+		for _, d := range selected {
+			m, err := c.fetchManifest(ctx, d)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			manifests = append(manifests, m)
+		}
+		if len(selected) != len(index.Manifests) {
+			index.Manifests = selected
+			raw, err = json.MarshalIndent(index, "", "   ")
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			desc = ocispec.Descriptor{MediaType: desc.MediaType, Digest: digest.FromBytes(raw), Size: int64(len(raw))}
+		}
+	case isManifestMediaType(desc.MediaType):
+		m, err := parseCopiedManifest(desc, raw)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if matcher != nil {
+			var img ocispec.Image
+			configJSON, err := c.registryClient.GetBlob(ctx, c.source, m.blobs[0].Digest)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			if err := json.Unmarshal(configJSON, &img); err != nil {
+				return ocispec.Descriptor{}, fmt.Errorf("invalid image config for %s: %w", reference.FamiliarString(source), err)
+			}
+			if !matcher.Match(img.Platform) {
+				return ocispec.Descriptor{}, fmt.Errorf("image %s does not provide any of the specified platforms", reference.FamiliarString(source))
+			}
+		}
+		manifests = append(manifests, m)
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("%s is not an image: unsupported media type %q", reference.FamiliarString(source), desc.MediaType)
+	}
+
+	if err := c.copyBlobs(ctx, manifests); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	// Push the manifests of an image index by digest, before pushing the index
+	// itself to the target.
+	if isIndexMediaType(desc.MediaType) {
+		for _, m := range manifests {
+			ref, err := reference.WithDigest(c.target, m.desc.Digest)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			if _, err := c.registryClient.PutRawManifest(ctx, ref, m.desc.MediaType, m.raw); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			c.progress("%s: Copied manifest", shortDigest(m.desc.Digest))
+		}
+	}
+	if reference.IsNameOnly(target) {
+		target, err = reference.WithDigest(target, desc.Digest)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	if _, err := c.registryClient.PutRawManifest(ctx, target, desc.MediaType, raw); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// selectManifests returns the manifests of an image index that match the
+// platform, and the attestation manifests for those. If matcher is nil,
+// all manifests are returned.
+func selectManifests(manifests []ocispec.Descriptor, matcher platforms.MatchComparer) []ocispec.Descriptor {
+	if matcher == nil {
+		return manifests
+	}
+	selected := make(map[digest.Digest]bool)
+	for _, d := range manifests {
+		if d.Platform != nil && d.Annotations[manifesttypes.AnnotationReferenceType] != manifesttypes.AttestationManifestType && matcher.Match(*d.Platform) {
+			selected[d.Digest] = true
+		}
+	}
+	var result []ocispec.Descriptor
+	for _, d := range manifests {
+		if selected[d.Digest] || (d.Annotations[manifesttypes.AnnotationReferenceType] == manifesttypes.AttestationManifestType && selected[digest.Digest(d.Annotations[manifesttypes.AnnotationReferenceDigest])]) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// fetchManifest fetches a manifest of an image index from the source
+// repository.
+func (c *imageCopier) fetchManifest(ctx context.Context, desc ocispec.Descriptor) (copiedManifest, error) {
+	if !isManifestMediaType(desc.MediaType) {
+		return copiedManifest{}, fmt.Errorf("cannot copy manifest %s: unsupported media type %q", desc.Digest, desc.MediaType)
+	}
+	ref, err := reference.WithDigest(c.source, desc.Digest)
+	if err != nil {
+		return copiedManifest{}, err
+	}
+	_, raw, err := c.registryClient.GetRawManifest(ctx, ref)
+	if err != nil {
+		return copiedManifest{}, err
+	}
+	return parseCopiedManifest(desc, raw)
+}
+
+// parseCopiedManifest returns the manifest with the blobs it references; the
+// image config, followed by the layers. Non-distributable layers, which are
+// referenced by URL, are not included.
+func parseCopiedManifest(desc ocispec.Descriptor, raw []byte) (copiedManifest, error) {
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return copiedManifest{}, fmt.Errorf("invalid image manifest %s: %w", desc.Digest, err)
+	}
+	m := copiedManifest{desc: desc, raw: raw, blobs: []ocispec.Descriptor{manifest.Config}}
+	for _, layer := range manifest.Layers {
+		if len(layer.URLs) > 0 {
+			continue
+		}
+		m.blobs = append(m.blobs, layer)
+	}
+	return m, nil
+}
+
+// copyBlobs copies the blobs of the manifests that do not exist in the target
+// repository. Blobs are mounted from the source repository if it is in the
+// same registry, and copied otherwise.
+func (c *imageCopier) copyBlobs(ctx context.Context, manifests []copiedManifest) error {
+	var blobs []ocispec.Descriptor
+	for _, m := range manifests {
+		for _, b := range m.blobs {
+			if !slices.ContainsFunc(blobs, func(d ocispec.Descriptor) bool { return d.Digest == b.Digest }) {
+				blobs = append(blobs, b)
+			}
+		}
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(maxConcurrentBlobCopies)
+	for _, b := range blobs {
+		eg.Go(func() error {
+			return c.copyBlob(ctx, b)
+		})
+	}
+	return eg.Wait()
+}
+
+func (c *imageCopier) copyBlob(ctx context.Context, desc ocispec.Descriptor) error {
+	exists, err := c.registryClient.BlobExists(ctx, c.target, desc.Digest)
+	if err != nil {
+		return err
+	}
+	if exists {
+		c.progress("%s: Already exists", shortDigest(desc.Digest))
+		return nil
+	}
+
+	if reference.Domain(c.source) == reference.Domain(c.target) {
+		source, err := reference.WithDigest(c.source, desc.Digest)
+		if err != nil {
+			return err
+		}
+		err = c.registryClient.MountBlob(ctx, source, c.target)
+		if err == nil {
+			c.progress("%s: Mounted from %s", shortDigest(desc.Digest), reference.FamiliarName(c.source))
+			return nil
+		}
+		// Fall back to copying the blob if it could not be mounted.
+		logrus.Debugf("failed to mount blob %s: %v", desc.Digest, err)
+	}
+
+	rc, err := c.registryClient.OpenBlob(ctx, c.source, desc.Digest)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := c.registryClient.PutBlob(ctx, c.target, desc, rc); err != nil {
+		return err
+	}
+	c.progress("%s: Copied %s", shortDigest(desc.Digest), units.HumanSizeWithPrecision(float64(desc.Size), 3))
+	return nil
+}
+
+func (c *imageCopier) progress(format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = fmt.Fprintf(c.out, format+"\n", args...)
+}
+
+// shortDigest returns the first 12 characters of the encoded digest, as used
+// in the progress output of push and pull.
+func shortDigest(dgst digest.Digest) string {
+	if err := dgst.Validate(); err != nil {
+		return dgst.String()
+	}
+	if enc := dgst.Encoded(); len(enc) > 12 {
+		return enc[:12]
+	}
+	return dgst.Encoded()
+}
@@ -0,0 +1,152 @@
+package image
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// addTestImage adds an image manifest with a config and a single layer to the
+// registry, and returns its descriptor with the platform set.
+func addTestImage(registry *fakeRegistryClient, platform ocispec.Platform, layer string) ocispec.Descriptor {
+	registry.content[digest.FromString(layer)] = []byte(layer)
+	config := registry.addBlob(ocispec.MediaTypeImageConfig, ocispec.Image{Platform: platform})
+	desc := registry.addManifest("", ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers: []ocispec.Descriptor{{
+			MediaType: ocispec.MediaTypeImageLayerGzip,
+			Digest:    digest.FromString(layer),
+			Size:      int64(len(layer)),
+		}},
+	})
+	desc.Platform = &platform
+	return desc
+}
+
+func TestCopy(t *testing.T) {
+	newRegistry := func() (*fakeRegistryClient, ocispec.Descriptor, []ocispec.Descriptor) {
+		registry := newFakeRegistryClient()
+		amd64 := addTestImage(registry, ocispec.Platform{OS: "linux", Architecture: "amd64"}, "amd64 layer")
+		arm64 := addTestImage(registry, ocispec.Platform{OS: "linux", Architecture: "arm64"}, "arm64 layer")
+		attestation := addTestImage(registry, ocispec.Platform{OS: "unknown", Architecture: "unknown"}, "arm64 attestation")
+		attestation.Annotations = map[string]string{
+			manifesttypes.AnnotationReferenceType:   manifesttypes.AttestationManifestType,
+			manifesttypes.AnnotationReferenceDigest: arm64.Digest.String(),
+		}
+		manifests := []ocispec.Descriptor{amd64, arm64, attestation}
+		index := registry.addManifest("source.example.com/app:1.0", ocispec.MediaTypeImageIndex, ocispec.Index{
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: manifests,
+		})
+		registry.manifests["source.example.com/app:single"] = amd64
+		return registry, index, manifests
+	}
+
+	t.Run("all platforms", func(t *testing.T) {
+		registry, index, manifests := newRegistry()
+		cli := test.NewFakeCli(&fakeClient{})
+		cli.SetRegistryClient(registry)
+		cmd := newCopyCommand(cli)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"source.example.com/app:1.0", "target.example.com/app"})
+		assert.NilError(t, cmd.Execute())
+
+		// The index is copied as-is, and all blobs are copied.
+		assert.Check(t, is.DeepEqual(registry.pushedManifests["target.example.com/app:1.0"], registry.content[index.Digest]))
+		for _, m := range manifests {
+			assert.Check(t, is.DeepEqual(registry.pushedManifests["target.example.com/app@"+m.Digest.String()], registry.content[m.Digest]))
+		}
+		assert.Check(t, is.Len(registry.pushedBlobs, 6))
+		assert.Check(t, is.Len(registry.mountedBlobs, 0))
+		assert.Check(t, strings.HasSuffix(cli.OutBuffer().String(), "target.example.com/app:1.0: digest: "+index.Digest.String()+" size: "+strconv.Itoa(len(registry.content[index.Digest]))+"\n"))
+	})
+
+	t.Run("platform subset", func(t *testing.T) {
+		registry, index, manifests := newRegistry()
+		cli := test.NewFakeCli(&fakeClient{})
+		cli.SetRegistryClient(registry)
+		cmd := newCopyCommand(cli)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--platform", "linux/arm64", "-q", "source.example.com/app:1.0", "target.example.com/app:arm64"})
+		assert.NilError(t, cmd.Execute())
+
+		// A new index is pushed with only the arm64 image and its attestation.
+		raw := registry.pushedManifests["target.example.com/app:arm64"]
+		var pushed ocispec.Index
+		assert.NilError(t, json.Unmarshal(raw, &pushed))
+		assert.Check(t, is.DeepEqual(pushed.Manifests, manifests[1:]))
+		assert.Check(t, digest.FromBytes(raw) != index.Digest)
+		_, ok := registry.pushedManifests["target.example.com/app@"+manifests[0].Digest.String()]
+		assert.Check(t, !ok, "amd64 manifest should not be copied")
+		assert.Check(t, is.Len(registry.pushedBlobs, 4))
+		assert.Check(t, is.Equal(cli.OutBuffer().String(), "target.example.com/app:arm64: digest: "+digest.FromBytes(raw).String()+" size: "+strconv.Itoa(len(raw))+"\n"))
+	})
+
+	t.Run("same registry", func(t *testing.T) {
+		registry, _, manifests := newRegistry()
+		var manifest ocispec.Manifest
+		assert.NilError(t, json.Unmarshal(registry.content[manifests[0].Digest], &manifest))
+		registry.existingBlobs["source.example.com/other@"+manifest.Config.Digest.String()] = true
+
+		cli := test.NewFakeCli(&fakeClient{})
+		cli.SetRegistryClient(registry)
+		cmd := newCopyCommand(cli)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"source.example.com/app:single", "source.example.com/other:latest"})
+		assert.NilError(t, cmd.Execute())
+
+		assert.Check(t, is.Len(registry.pushedBlobs, 0))
+		assert.Check(t, is.DeepEqual(registry.mountedBlobs, []string{"source.example.com/other@" + manifest.Layers[0].Digest.String()}))
+		assert.Check(t, is.DeepEqual(registry.pushedManifests["source.example.com/other:latest"], registry.content[manifests[0].Digest]))
+		out := cli.OutBuffer().String()
+		assert.Check(t, is.Contains(out, shortDigest(manifest.Config.Digest)+": Already exists\n"))
+		assert.Check(t, is.Contains(out, shortDigest(manifest.Layers[0].Digest)+": Mounted from source.example.com/app\n"))
+	})
+
+	for _, tc := range []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{
+			name:        "target with digest",
+			args:        []string{"source.example.com/app:1.0", "target.example.com/app@" + digest.FromString("foo").String()},
+			expectedErr: "must not contain a digest",
+		},
+		{
+			name:        "platform not in index",
+			args:        []string{"--platform", "linux/s390x", "source.example.com/app:1.0", "target.example.com/app"},
+			expectedErr: "image source.example.com/app:1.0 does not provide any of the specified platforms",
+		},
+		{
+			name:        "platform does not match",
+			args:        []string{"--platform", "linux/arm64", "source.example.com/app:single", "target.example.com/app"},
+			expectedErr: "image source.example.com/app:single does not provide any of the specified platforms",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			registry, _, _ := newRegistry()
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetRegistryClient(registry)
+			cmd := newCopyCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			assert.Check(t, is.ErrorContains(cmd.Execute(), tc.expectedErr))
+			assert.Check(t, is.Len(registry.pushedManifests, 0))
+		})
+	}
+}
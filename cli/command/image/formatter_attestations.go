@@ -0,0 +1,108 @@
+package image
+
+import (
+	"github.com/containerd/platforms"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/go-units"
+)
+
+const (
+	defaultAttestationsTableFormat = "table {{.Platform}}\t{{.Type}}\t{{.Summary}}"
+
+	attestationPlatformHeader      = "PLATFORM"
+	attestationTypeHeader          = "TYPE"
+	attestationPredicateTypeHeader = "PREDICATE TYPE"
+	attestationSubjectHeader       = "SUBJECT"
+	attestationManifestHeader      = "MANIFEST"
+	attestationDigestHeader        = "DIGEST"
+	attestationSummaryHeader       = "SUMMARY"
+)
+
+// newAttestationsFormat returns a Format for rendering using an
+// attestationContext.
+func newAttestationsFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultAttestationsTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// attestationsFormatWrite writes the attestations of an image using the
+// context.
+func attestationsFormatWrite(fmtCtx formatter.Context, attestations []attestation) error {
+	attestationCtx := &attestationContext{
+		HeaderContext: formatter.HeaderContext{
+			Header: formatter.SubHeaderContext{
+				"Platform":      attestationPlatformHeader,
+				"Type":          attestationTypeHeader,
+				"PredicateType": attestationPredicateTypeHeader,
+				"Subject":       attestationSubjectHeader,
+				"Manifest":      attestationManifestHeader,
+				"Digest":        attestationDigestHeader,
+				"Size":          formatter.SizeHeader,
+				"Summary":       attestationSummaryHeader,
+			},
+		},
+	}
+	return fmtCtx.Write(attestationCtx, func(format func(subContext formatter.SubContext) error) error {
+		for _, a := range attestations {
+			if err := format(&attestationContext{a: a}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type attestationContext struct {
+	formatter.HeaderContext
+	a attestation
+}
+
+func (c *attestationContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+// Platform returns the platform of the image that the attestation is for.
+func (c *attestationContext) Platform() string {
+	return platforms.Format(c.a.platform)
+}
+
+// Type returns the kind of attestation ("sbom" or "provenance"), or its
+// predicate type if it is not a known kind.
+func (c *attestationContext) Type() string {
+	if kind := attestationKind(c.a.predicateType); kind != "" {
+		return kind
+	}
+	return c.a.predicateType
+}
+
+func (c *attestationContext) PredicateType() string {
+	return c.a.predicateType
+}
+
+// Subject returns the digest of the image manifest that the attestation is
+// for.
+func (c *attestationContext) Subject() string {
+	return c.a.subject.String()
+}
+
+// Manifest returns the digest of the attestation manifest.
+func (c *attestationContext) Manifest() string {
+	return c.a.manifest.String()
+}
+
+// Digest returns the digest of the in-toto statement.
+func (c *attestationContext) Digest() string {
+	return c.a.layer.Digest.String()
+}
+
+// Size returns the size of the in-toto statement.
+func (c *attestationContext) Size() string {
+	return units.HumanSizeWithPrecision(float64(c.a.layer.Size), 3)
+}
+
+func (c *attestationContext) Summary() string {
+	return c.a.summary()
+}
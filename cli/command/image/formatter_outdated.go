@@ -0,0 +1,113 @@
+package image
+
+import (
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/go-units"
+)
+
+const (
+	defaultOutdatedTableFormat = "table {{.Repository}}\t{{.Tag}}\t{{.ID}}\t{{.CreatedSince}}\t{{.Status}}"
+
+	outdatedRepositoryHeader   = "REPOSITORY"
+	outdatedTagHeader          = "TAG"
+	outdatedIDHeader           = "IMAGE ID"
+	outdatedLocalDigestHeader  = "LOCAL DIGEST"
+	outdatedRemoteDigestHeader = "REMOTE DIGEST"
+	outdatedStatusHeader       = "STATUS"
+)
+
+// newOutdatedFormat returns a Format for rendering using an outdatedContext.
+func newOutdatedFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultOutdatedTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// outdatedFormatWrite writes the local images, and how they compare to their
+// registry, using the context.
+func outdatedFormatWrite(fmtCtx formatter.Context, images []outdatedImage) error {
+	outdatedCtx := &outdatedContext{
+		HeaderContext: formatter.HeaderContext{
+			Header: formatter.SubHeaderContext{
+				"Repository":   outdatedRepositoryHeader,
+				"Tag":          outdatedTagHeader,
+				"ID":           outdatedIDHeader,
+				"CreatedSince": formatter.CreatedSinceHeader,
+				"CreatedAt":    formatter.CreatedAtHeader,
+				"LocalDigest":  outdatedLocalDigestHeader,
+				"RemoteDigest": outdatedRemoteDigestHeader,
+				"Status":       outdatedStatusHeader,
+			},
+		},
+	}
+	return fmtCtx.Write(outdatedCtx, func(format func(subContext formatter.SubContext) error) error {
+		for _, img := range images {
+			if err := format(&outdatedContext{trunc: fmtCtx.Trunc, i: img}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type outdatedContext struct {
+	formatter.HeaderContext
+	trunc bool
+	i     outdatedImage
+}
+
+func (c *outdatedContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *outdatedContext) Repository() string {
+	return reference.FamiliarName(c.i.ref)
+}
+
+func (c *outdatedContext) Tag() string {
+	return c.i.ref.Tag()
+}
+
+func (c *outdatedContext) ID() string {
+	if c.trunc {
+		return formatter.TruncateID(c.i.img.ID)
+	}
+	return c.i.img.ID
+}
+
+// CreatedSince returns the age of the local image.
+func (c *outdatedContext) CreatedSince() string {
+	if c.i.img.Created == 0 {
+		return ""
+	}
+	return units.HumanDuration(time.Now().UTC().Sub(time.Unix(c.i.img.Created, 0))) + " ago"
+}
+
+func (c *outdatedContext) CreatedAt() string {
+	return time.Unix(c.i.img.Created, 0).String()
+}
+
+// LocalDigest returns the digests of the local image in the repository, as
+// a comma-separated list.
+func (c *outdatedContext) LocalDigest() string {
+	digests := make([]string, 0, len(c.i.local))
+	for _, d := range c.i.local {
+		digests = append(digests, d.String())
+	}
+	return strings.Join(digests, ",")
+}
+
+// RemoteDigest returns the digest that the tag resolves to in the registry.
+func (c *outdatedContext) RemoteDigest() string {
+	return c.i.remote.String()
+}
+
+func (c *outdatedContext) Status() string {
+	return c.i.status
+}
@@ -6,6 +6,7 @@
 import (
 	"bytes"
 	"context"
+	"errors"
 
 	"github.com/containerd/platforms"
 	"github.com/docker/cli/cli"
@@ -23,6 +24,8 @@ type inspectOptions struct {
 	format   string
 	refs     []string
 	platform string
+	remote   bool
+	insecure bool
 }
 
 // newInspectCommand creates a new cobra.Command for `docker image inspect`
@@ -51,6 +54,8 @@ func newInspectCommand(dockerCLI command.Cli) *cobra.Command {
 If the image or the server is not multi-platform capable, the command will error out if the platform does not match.
 'os[/arch[/variant]]': Explicit platform (eg. linux/amd64)`)
 	flags.SetAnnotation("platform", "version", []string{"1.49"})
+	flags.BoolVar(&opts.remote, "remote", false, "Inspect the image in its registry, without pulling it")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry (with --remote)")
 
 	_ = cmd.RegisterFlagCompletionFunc("platform", completion.Platforms())
 	return cmd
@@ -66,6 +71,17 @@ func runInspect(ctx context.Context, dockerCLI command.Cli, opts inspectOptions)
 		platform = &p
 	}
 
+	if opts.remote {
+		registryClient := command.NewRegistryClient(dockerCLI, opts.insecure)
+		return inspect.Inspect(dockerCLI.Out(), opts.refs, opts.format, func(ref string) (any, []byte, error) {
+			resp, err := inspectRemote(ctx, registryClient, ref, platform)
+			return resp, nil, err
+		})
+	}
+	if opts.insecure {
+		return errors.New("--insecure can only be used with --remote")
+	}
+
 	apiClient := dockerCLI.Client()
 	return inspect.Inspect(dockerCLI.Out(), opts.refs, opts.format, func(ref string) (any, []byte, error) {
 		var buf bytes.Buffer
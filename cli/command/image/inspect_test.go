@@ -5,9 +5,13 @@
 	"io"
 	"testing"
 
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
 	"github.com/docker/cli/internal/test"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
 	"github.com/moby/moby/api/types/image"
 	"github.com/moby/moby/client"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/golden"
@@ -94,3 +98,109 @@ func TestNewInspectCommandSuccess(t *testing.T) {
 		})
 	}
 }
+
+func TestInspectRemote(t *testing.T) {
+	registry := newFakeRegistryClient()
+	addImage := func(platform ocispec.Platform, env string, layerSize int64) ocispec.Descriptor {
+		var img dockerspec.DockerOCIImage
+		img.Platform = platform
+		img.Config.Env = []string{env}
+		img.Config.Entrypoint = []string{"/app"}
+		img.RootFS = ocispec.RootFS{Type: "layers", DiffIDs: []digest.Digest{digest.FromString(env)}}
+		config := registry.addBlob(ocispec.MediaTypeImageConfig, img)
+		return registry.addManifest("", ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    config,
+			Layers: []ocispec.Descriptor{{
+				MediaType: ocispec.MediaTypeImageLayerGzip,
+				Digest:    digest.FromString(env),
+				Size:      layerSize,
+			}},
+		})
+	}
+
+	amd64 := ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := ocispec.Platform{OS: "linux", Architecture: "arm64"}
+	single := addImage(amd64, "SINGLE=1", 100)
+	registry.manifests["docker.io/library/single:latest"] = single
+
+	amd64Desc := addImage(amd64, "PLATFORM=amd64", 1000)
+	amd64Desc.Platform = &amd64
+	arm64Desc := addImage(arm64, "PLATFORM=arm64", 2000)
+	arm64Desc.Platform = &arm64
+	attestation := addImage(ocispec.Platform{OS: "unknown", Architecture: "unknown"}, "ATTESTATION=1", 10)
+	attestation.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+	attestation.Annotations = map[string]string{
+		manifesttypes.AnnotationReferenceType:   manifesttypes.AttestationManifestType,
+		manifesttypes.AnnotationReferenceDigest: amd64Desc.Digest.String(),
+	}
+	index := registry.addManifest("example.com/multi:1.0", ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{amd64Desc, arm64Desc, attestation},
+	})
+
+	testCases := []struct {
+		name        string
+		args        []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "single manifest",
+			args:     []string{"--remote", "--format", "{{.Os}}/{{.Architecture}} {{.Config.Env}} {{.Config.Entrypoint}} {{.Size}} {{.RepoTags}}", "single"},
+			expected: "linux/amd64 [SINGLE=1] [/app] 100 [single:latest]\n",
+		},
+		{
+			name:     "digest",
+			args:     []string{"--remote", "--format", "{{.ID}} {{.RepoTags}} {{.RepoDigests}}", "example.com/multi@" + index.Digest.String()},
+			expected: index.Digest.String() + " [] [example.com/multi@" + index.Digest.String() + "]\n",
+		},
+		{
+			name:     "platform",
+			args:     []string{"--remote", "--platform", "linux/arm64", "--format", "{{.Architecture}} {{.Config.Env}} {{.Size}}", "example.com/multi:1.0"},
+			expected: "arm64 [PLATFORM=arm64] 2000\n",
+		},
+		{
+			name:     "manifests",
+			args:     []string{"--remote", "--platform", "linux/amd64", "--format", "{{range .Manifests}}{{.Kind}} {{.Size.Content}}{{with .AttestationData}} {{.For}}{{end}}\n{{end}}", "example.com/multi:1.0"},
+			expected: fmt.Sprintf("image %d\nimage %d\nattestation %d %s\n\n", amd64Desc.Size+registry.configSize(amd64Desc)+1000, arm64Desc.Size+registry.configSize(arm64Desc)+2000, attestation.Size+registry.configSize(attestation)+10, amd64Desc.Digest),
+		},
+		{
+			name:        "platform not in index",
+			args:        []string{"--remote", "--platform", "linux/s390x", "example.com/multi:1.0"},
+			expectedErr: "image with reference example.com/multi:1.0 was found but does not provide the specified platform (linux/s390x)",
+		},
+		{
+			name:        "platform does not match",
+			args:        []string{"--remote", "--platform", "linux/arm64", "single"},
+			expectedErr: "image with reference single was found but does not provide the specified platform (linux/arm64)",
+		},
+		{
+			name:        "not found",
+			args:        []string{"--remote", "missing"},
+			expectedErr: "no such manifest: docker.io/library/missing:latest",
+		},
+		{
+			name:        "insecure without remote",
+			args:        []string{"--insecure", "single"},
+			expectedErr: "--insecure can only be used with --remote",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetRegistryClient(registry)
+			cmd := newInspectCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			err := cmd.Execute()
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+		})
+	}
+}
@@ -31,11 +31,13 @@ type imagesOptions struct {
 	format      string
 	filter      opts.FilterOpt
 	tree        bool
+	sort        string
+	reverse     bool
 }
 
 // newImagesCommand creates a new `docker images` command
 func newImagesCommand(dockerCLI command.Cli) *cobra.Command {
-	options := imagesOptions{filter: opts.NewFilterOpt()}
+	options := imagesOptions{filter: opts.NewFilterOptWithComparisons()}
 
 	cmd := &cobra.Command{
 		Use:   "images [OPTIONS] [REPOSITORY[:TAG]]",
@@ -70,11 +72,14 @@ func newImagesCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.BoolVar(&options.showDigests, "digests", false, "Show digests")
 	flags.StringVar(&options.format, "format", "", flagsHelper.FormatHelp)
 	flags.VarP(&options.filter, "filter", "f", "Filter output based on conditions provided")
+	flags.StringVar(&options.sort, "sort", "", `Sort output by "size", "created", or "name"`)
+	flags.BoolVar(&options.reverse, "reverse", false, "Reverse the sort order")
 
 	flags.BoolVar(&options.tree, "tree", false, "List multi-platform images as a tree (EXPERIMENTAL)")
 	flags.SetAnnotation("tree", "version", []string{"1.47"})
 	flags.SetAnnotation("tree", "experimentalCLI", nil)
 
+	_ = cmd.RegisterFlagCompletionFunc("sort", completion.FromList(formatter.SortKeys...))
 	return cmd
 }
 
@@ -91,6 +96,15 @@ func runImages(ctx context.Context, dockerCLI command.Cli, options imagesOptions
 		filters.Add("reference", options.matchName)
 	}
 
+	listOptions := formatter.ListOptions{
+		Sort:        options.sort,
+		Reverse:     options.reverse,
+		Comparisons: options.filter.Comparisons(),
+	}
+	if err := listOptions.Validate(); err != nil {
+		return 0, err
+	}
+
 	useTree, err := shouldUseTree(options)
 	if err != nil {
 		return 0, err
@@ -113,6 +127,10 @@ func runImages(ctx context.Context, dockerCLI command.Cli, options imagesOptions
 			images = slices.DeleteFunc(images, isDangling)
 		}
 	}
+	images, err = formatter.FilterAndSortImages(images, listOptions)
+	if err != nil {
+		return 0, err
+	}
 
 	format := options.format
 	if len(format) == 0 {
@@ -129,6 +147,8 @@ func runImages(ctx context.Context, dockerCLI command.Cli, options imagesOptions
 			images:   images,
 			filters:  filters,
 			expanded: options.tree,
+			sort:     options.sort,
+			reverse:  options.reverse,
 		})
 	}
 
@@ -33,6 +33,15 @@ func TestNewImagesCommandErrors(t *testing.T) {
 			imageListFunc: func(options client.ImageListOptions) (client.ImageListResult, error) {
 				return client.ImageListResult{}, errors.New("something went wrong")
 			},
+		}, {
+			name:          "invalid-sort",
+			args:          []string{"--sort", "color"},
+			expectedError: `invalid sort key "color"`,
+		},
+		{
+			name:          "invalid-comparison",
+			args:          []string{"--filter", "size>huge"},
+			expectedError: "invalid filter 'size>huge'",
 		},
 	}
 	for _, tc := range testCases {
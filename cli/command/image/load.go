@@ -5,6 +5,7 @@
 	"errors"
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/containerd/platforms"
 	"github.com/docker/cli/cli"
@@ -43,7 +44,7 @@ func newLoadCommand(dockerCLI command.Cli) *cobra.Command {
 
 	flags := cmd.Flags()
 
-	flags.StringVarP(&opts.input, "input", "i", "", "Read from tar archive file, instead of STDIN")
+	flags.StringVarP(&opts.input, "input", "i", "", "Read from tar archive file or OCI image layout directory, instead of STDIN")
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Suppress the load output")
 	flags.StringSliceVar(&opts.platform, "platform", []string{}, `Load only the given platform(s). Formatted as a comma-separated list of "os[/arch[/variant]]" (e.g., "linux/amd64,linux/arm64/v8").`)
 	_ = flags.SetAnnotation("platform", "version", []string{"1.48"})
@@ -64,6 +65,18 @@ func runLoad(ctx context.Context, dockerCli command.Cli, opts loadOptions) error
 			return errors.New("requested load from stdin, but stdin is empty")
 		}
 	default:
+		if ok, err := isOCILayoutDir(opts.input); err != nil && !os.IsNotExist(err) {
+			return err
+		} else if ok {
+			rc, err := tarOCILayout(opts.input)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = rc.Close() }()
+			input = rc
+			break
+		}
+
 		// We use sequential.Open to use sequential file access on Windows, avoiding
 		// depleting the standby list un-necessarily. On Linux, this equates to a regular os.Open.
 		file, err := sequential.Open(opts.input)
@@ -0,0 +1,188 @@
+package image
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/go-archive"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// formatTar is the default format of "docker image save"; a tar archive
+	// as produced by the daemon.
+	formatTar = "tar"
+
+	// formatOCIDir writes an OCI image layout directory.
+	formatOCIDir = "oci-dir"
+)
+
+// isOCILayoutEntry returns whether the given (cleaned) archive path is part
+// of an OCI image layout. Other entries produced by the daemon, such as the
+// legacy "manifest.json" and "repositories" files, are not.
+func isOCILayoutEntry(name string) bool {
+	switch name {
+	case ocispec.ImageLayoutFile, ocispec.ImageIndexFile:
+		return true
+	default:
+		return name == ocispec.ImageBlobsDir || strings.HasPrefix(name, ocispec.ImageBlobsDir+"/")
+	}
+}
+
+// writeOCILayout extracts the OCI image layout from the tar archive produced
+// by the daemon into dir. The directory is created if it does not exist, and
+// must be empty otherwise. The image index is written as-is, which preserves
+// its annotations and the platform of each of the manifests it references.
+//
+// The layout is extracted into a temporary directory next to dir, which is
+// renamed to dir when complete, so that no partial layout is left behind if
+// saving fails.
+func writeOCILayout(dir string, r io.Reader) (retErr error) {
+	if err := prepareOCILayoutDir(dir); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			_ = os.RemoveAll(tmpDir)
+		}
+	}()
+	if err := os.Chmod(tmpDir, 0o755); err != nil {
+		return err
+	}
+	if err := extractOCILayout(tmpDir, r); err != nil {
+		return err
+	}
+	// dir is empty if it exists; see prepareOCILayoutDir.
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Rename(tmpDir, dir)
+}
+
+// extractOCILayout extracts the OCI image layout from the tar archive into
+// the given (empty) directory.
+func extractOCILayout(dir string, r io.Reader) error {
+	var foundLayout, foundIndex bool
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read image archive: %w", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in image archive: %q", hdr.Name)
+		}
+		if !isOCILayoutEntry(name) {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+			switch name {
+			case ocispec.ImageLayoutFile:
+				foundLayout = true
+			case ocispec.ImageIndexFile:
+				foundIndex = true
+			}
+		default:
+			// Blobs are content-addressed regular files; anything else
+			// (symlinks, devices) has no place in an image layout.
+			return fmt.Errorf("unsupported file type in image archive: %q", hdr.Name)
+		}
+	}
+
+	if !foundLayout || !foundIndex {
+		return errors.New("the daemon did not produce an OCI image layout; saving to an OCI layout directory requires a daemon that includes the OCI layout in its image archives")
+	}
+	return nil
+}
+
+// prepareOCILayoutDir makes sure dir is empty if it exists, and that its
+// parent directory exists.
+func prepareOCILayoutDir(dir string) error {
+	if dir == "" {
+		return errors.New("an output directory must be specified with --output when using --format=" + formatOCIDir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("invalid output path: %w", err)
+		}
+		return os.MkdirAll(filepath.Dir(dir), 0o755)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("invalid output path: directory %q is not empty", dir)
+	}
+	return nil
+}
+
+func writeFile(target string, r io.Reader) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// isOCILayoutDir returns whether dir is a directory containing an OCI image
+// layout.
+func isOCILayoutDir(dir string) (bool, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return false, err
+	}
+	if !fi.IsDir() {
+		return false, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, ocispec.ImageLayoutFile)); err != nil {
+		if os.IsNotExist(err) {
+			return false, fmt.Errorf("%s is not an OCI image layout: missing %s", dir, ocispec.ImageLayoutFile)
+		}
+		return false, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ocispec.ImageIndexFile)); err != nil {
+		if os.IsNotExist(err) {
+			return false, fmt.Errorf("%s is not an OCI image layout: missing %s", dir, ocispec.ImageIndexFile)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// tarOCILayout returns a tar stream of the OCI image layout in dir, which
+// can be loaded by the daemon.
+func tarOCILayout(dir string) (io.ReadCloser, error) {
+	return archive.TarWithOptions(dir, &archive.TarOptions{
+		IncludeFiles: []string{ocispec.ImageLayoutFile, ocispec.ImageIndexFile, ocispec.ImageBlobsDir},
+	})
+}
@@ -0,0 +1,159 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"github.com/moby/moby/client"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
+)
+
+const testIndex = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:0000000000000000000000000000000000000000000000000000000000000001","size":1,"platform":{"architecture":"arm64","os":"linux","variant":"v8"},"annotations":{"org.opencontainers.image.ref.name":"latest"}}]}`
+
+func makeImageArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"blobs/", "blobs/sha256/", "oci-layout", "index.json", "manifest.json", "blobs/sha256/0000000000000000000000000000000000000000000000000000000000000001"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if name[len(name)-1] == '/' {
+			hdr = &tar.Header{Name: name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		assert.NilError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func testLayoutFiles() map[string]string {
+	return map[string]string{
+		"blobs/":        "",
+		"blobs/sha256/": "",
+		"oci-layout":    `{"imageLayoutVersion":"1.0.0"}`,
+		"index.json":    testIndex,
+		"manifest.json": `[]`,
+		"blobs/sha256/0000000000000000000000000000000000000000000000000000000000000001": "x",
+	}
+}
+
+func TestWriteOCILayout(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "layout")
+	err := writeOCILayout(dir, bytes.NewReader(makeImageArchive(t, testLayoutFiles())))
+	assert.NilError(t, err)
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(string(index), testIndex))
+
+	_, err = os.Stat(filepath.Join(dir, "manifest.json"))
+	assert.Check(t, os.IsNotExist(err), "legacy manifest.json should not be written")
+
+	ok, err := isOCILayoutDir(dir)
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+}
+
+func TestWriteOCILayoutErrors(t *testing.T) {
+	t.Run("not empty", func(t *testing.T) {
+		dir := fs.NewDir(t, "layout", fs.WithFile("foo", ""))
+		err := writeOCILayout(dir.Path(), bytes.NewReader(makeImageArchive(t, testLayoutFiles())))
+		assert.Check(t, is.ErrorContains(err, "is not empty"))
+	})
+	t.Run("no layout", func(t *testing.T) {
+		err := writeOCILayout(t.TempDir(), bytes.NewReader(makeImageArchive(t, map[string]string{"manifest.json": "[]"})))
+		assert.Check(t, is.ErrorContains(err, "did not produce an OCI image layout"))
+	})
+	t.Run("partial layout is removed", func(t *testing.T) {
+		parent := t.TempDir()
+		dir := filepath.Join(parent, "layout")
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: "oci-layout", Mode: 0o644, Typeflag: tar.TypeReg, Size: 2}))
+		_, err := tw.Write([]byte("{}"))
+		assert.NilError(t, err)
+		// truncated archive: the next entry is missing its content.
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: "index.json", Mode: 0o644, Typeflag: tar.TypeReg, Size: 100}))
+		err = writeOCILayout(dir, bytes.NewReader(buf.Bytes()))
+		assert.Check(t, err != nil)
+
+		entries, err := os.ReadDir(parent)
+		assert.NilError(t, err)
+		assert.Check(t, is.Len(entries, 0))
+	})
+	t.Run("path traversal", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: "../index.json", Mode: 0o644, Typeflag: tar.TypeReg}))
+		assert.NilError(t, tw.Close())
+		err := writeOCILayout(t.TempDir(), &buf)
+		assert.Check(t, is.ErrorContains(err, "invalid path in image archive"))
+	})
+}
+
+func TestSaveOCILayout(t *testing.T) {
+	archive := makeImageArchive(t, testLayoutFiles())
+	dir := filepath.Join(t.TempDir(), "layout")
+	cli := test.NewFakeCli(&fakeClient{
+		imageSaveFunc: func(images []string, options ...client.ImageSaveOption) (client.ImageSaveResult, error) {
+			return io.NopCloser(bytes.NewReader(archive)), nil
+		},
+	})
+	cmd := newSaveCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--format", "oci-dir", "-o", dir, "arg1"})
+	assert.NilError(t, cmd.Execute())
+
+	_, err := os.Stat(filepath.Join(dir, "oci-layout"))
+	assert.NilError(t, err)
+}
+
+func TestLoadOCILayout(t *testing.T) {
+	dir := fs.NewDir(t, "layout",
+		fs.WithFile("oci-layout", `{"imageLayoutVersion":"1.0.0"}`),
+		fs.WithFile("index.json", testIndex),
+		fs.WithDir("blobs", fs.WithDir("sha256",
+			fs.WithFile("0000000000000000000000000000000000000000000000000000000000000001", "x"),
+		)),
+		fs.WithFile("unrelated.txt", "ignored"),
+	)
+
+	var names []string
+	cli := test.NewFakeCli(&fakeClient{
+		imageLoadFunc: func(input io.Reader, options ...client.ImageLoadOption) (client.ImageLoadResult, error) {
+			tr := tar.NewReader(input)
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				assert.NilError(t, err)
+				names = append(names, hdr.Name)
+			}
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		},
+	})
+	cmd := newLoadCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--input", dir.Path()})
+	assert.NilError(t, cmd.Execute())
+
+	assert.Check(t, is.Contains(names, "oci-layout"))
+	assert.Check(t, is.Contains(names, "index.json"))
+	assert.Check(t, is.Contains(names, "blobs/sha256/0000000000000000000000000000000000000000000000000000000000000001"))
+	assert.Check(t, !is.Contains(names, "unrelated.txt")().Success())
+}
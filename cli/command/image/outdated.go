@@ -0,0 +1,183 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/command/formatter"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/client"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// Status of a local image, compared to the image in its registry.
+const (
+	outdatedStatusOutdated = "outdated"
+	outdatedStatusCurrent  = "current"
+	outdatedStatusMissing  = "missing" // the tag does not exist in the registry
+	outdatedStatusUnknown  = "unknown" // the image was not pulled from (or pushed to) the registry
+)
+
+type outdatedOptions struct {
+	refs           []string
+	all            bool
+	pull           bool
+	format         string
+	insecure       bool
+	maxConcurrency int
+}
+
+// newOutdatedCommand creates a new "docker image outdated" command.
+func newOutdatedCommand(dockerCLI command.Cli) *cobra.Command {
+	var opts outdatedOptions
+
+	cmd := &cobra.Command{
+		Use:   "outdated [OPTIONS] [REPOSITORY[:TAG]...]",
+		Short: "List local images that are outdated compared to their registry",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.refs = args
+			return runOutdated(cmd.Context(), dockerCLI, opts)
+		},
+		ValidArgsFunction:     completion.ImageNames(dockerCLI, -1),
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.all, "all", "a", false, "Show all tagged images, including those that are up to date")
+	flags.BoolVar(&opts.pull, "pull", false, "Pull the outdated images")
+	flags.StringVar(&opts.format, "format", "", flagsHelper.FormatHelp)
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry")
+	flags.IntVar(&opts.maxConcurrency, "max-concurrent-requests", 4, "Maximum number of concurrent requests to registries")
+	return cmd
+}
+
+// outdatedImage is a tag of a local image, and the digest that the tag
+// resolves to in its registry.
+type outdatedImage struct {
+	ref    reference.NamedTagged
+	img    image.Summary
+	local  []digest.Digest // digests of the local image in the repository
+	remote digest.Digest
+	status string
+}
+
+func runOutdated(ctx context.Context, dockerCLI command.Cli, opts outdatedOptions) error {
+	if opts.maxConcurrency < 1 {
+		return errors.New("--max-concurrent-requests must be at least 1")
+	}
+
+	filters := make(client.Filters)
+	if len(opts.refs) > 0 {
+		filters.Add("reference", opts.refs...)
+	}
+	res, err := dockerCLI.Client().ImageList(ctx, client.ImageListOptions{Filters: filters})
+	if err != nil {
+		return err
+	}
+	images := localTags(res.Items)
+
+	registryClient := command.NewRegistryClient(dockerCLI, opts.insecure)
+	var (
+		mu   sync.Mutex
+		errs []error
+		eg   errgroup.Group
+	)
+	eg.SetLimit(opts.maxConcurrency)
+	for i := range images {
+		eg.Go(func() error {
+			img := &images[i]
+			desc, _, err := registryClient.GetUpstreamManifest(ctx, img.ref)
+			switch {
+			case errdefs.IsNotFound(err):
+				img.status = outdatedStatusMissing
+			case err != nil:
+				img.status = outdatedStatusUnknown
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("failed to check %s: %w", reference.FamiliarString(img.ref), err))
+				mu.Unlock()
+			case len(img.local) == 0:
+				img.remote = desc.Digest
+				img.status = outdatedStatusUnknown
+			case slices.Contains(img.local, desc.Digest):
+				img.remote = desc.Digest
+				img.status = outdatedStatusCurrent
+			default:
+				img.remote = desc.Digest
+				img.status = outdatedStatusOutdated
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	var outdated []outdatedImage
+	for _, img := range images {
+		if img.status == outdatedStatusOutdated {
+			outdated = append(outdated, img)
+		}
+	}
+	if !opts.all {
+		images = outdated
+	}
+	outdatedCtx := formatter.Context{
+		Output: dockerCLI.Out(),
+		Format: newOutdatedFormat(opts.format),
+		Trunc:  true,
+	}
+	if err := outdatedFormatWrite(outdatedCtx, images); err != nil {
+		return err
+	}
+
+	if opts.pull {
+		for _, img := range outdated {
+			if err := runPull(ctx, dockerCLI, pullOptions{remote: reference.FamiliarString(img.ref), quiet: true}); err != nil {
+				errs = append(errs, fmt.Errorf("failed to pull %s: %w", reference.FamiliarString(img.ref), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// localTags returns the tags of the images, sorted by name, with the digests
+// of the image in the repository of each tag.
+func localTags(images []image.Summary) []outdatedImage {
+	var tags []outdatedImage
+	for _, img := range images {
+		for _, repoTag := range img.RepoTags {
+			namedRef, err := reference.ParseNormalizedNamed(repoTag)
+			if err != nil {
+				continue
+			}
+			tagged, ok := namedRef.(reference.NamedTagged)
+			if !ok {
+				continue
+			}
+			t := outdatedImage{ref: tagged, img: img}
+			for _, repoDigest := range img.RepoDigests {
+				digested, err := reference.ParseNormalizedNamed(repoDigest)
+				if err != nil {
+					continue
+				}
+				if canonical, ok := digested.(reference.Canonical); ok && canonical.Name() == tagged.Name() {
+					t.local = append(t.local, canonical.Digest())
+				}
+			}
+			tags = append(tags, t)
+		}
+	}
+	slices.SortFunc(tags, func(a, b outdatedImage) int {
+		return strings.Compare(a.ref.String(), b.ref.String())
+	})
+	return tags
+}
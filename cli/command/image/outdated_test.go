@@ -0,0 +1,151 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/internal/test"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/client"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// erroringRegistryClient is a registry client that fails to fetch manifests.
+type erroringRegistryClient struct {
+	fakeRegistryClient
+	err error
+}
+
+func (c *erroringRegistryClient) GetUpstreamManifest(context.Context, reference.Named, ...string) (ocispec.Descriptor, []byte, error) {
+	return ocispec.Descriptor{}, nil, c.err
+}
+
+func TestOutdated(t *testing.T) {
+	registry := newFakeRegistryClient()
+	current := registry.addManifest("docker.io/library/alpine:3", ocispec.MediaTypeImageIndex, ocispec.Index{MediaType: ocispec.MediaTypeImageIndex})
+	latest := registry.addManifest("docker.io/library/alpine:latest", ocispec.MediaTypeImageIndex, ocispec.Index{MediaType: ocispec.MediaTypeImageIndex, Annotations: map[string]string{"new": "true"}})
+	registry.addManifest("example.com/app:1.0", ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest})
+	stale := digest.FromString("stale")
+
+	created := time.Now().Add(-72 * time.Hour).Unix()
+	images := []image.Summary{
+		{
+			ID:          "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			RepoTags:    []string{"alpine:3", "alpine:latest"},
+			RepoDigests: []string{"alpine@" + current.Digest.String(), "alpine@" + stale.String()},
+			Created:     created,
+		},
+		{
+			// Built locally, and never pushed.
+			ID:       "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+			RepoTags: []string{"example.com/app:1.0"},
+			Created:  created,
+		},
+		{
+			ID:          "sha256:3333333333333333333333333333333333333333333333333333333333333333",
+			RepoTags:    []string{"example.com/app:old"},
+			RepoDigests: []string{"example.com/app@" + stale.String()},
+			Created:     created,
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		expected string
+		pulled   []string
+	}{
+		{
+			name: "outdated",
+			expected: `REPOSITORY   TAG       IMAGE ID       CREATED      STATUS
+alpine       latest    111111111111   3 days ago   outdated
+`,
+		},
+		{
+			name: "all",
+			args: []string{"--all", "--format", "{{.Repository}}:{{.Tag}} {{.Status}} {{.RemoteDigest}}"},
+			expected: "alpine:3 current " + current.Digest.String() + "\n" +
+				"alpine:latest outdated " + latest.Digest.String() + "\n" +
+				"example.com/app:1.0 unknown " + registry.manifests["example.com/app:1.0"].Digest.String() + "\n" +
+				"example.com/app:old missing \n",
+		},
+		{
+			name: "pull",
+			args: []string{"--pull", "--format", "{{.Repository}}:{{.Tag}}"},
+			expected: "alpine:latest\n" +
+				"docker.io/library/alpine:latest\n",
+			pulled: []string{"alpine:latest"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pulled []string
+			cli := test.NewFakeCli(&fakeClient{
+				imageListFunc: func(client.ImageListOptions) (client.ImageListResult, error) {
+					return client.ImageListResult{Items: images}, nil
+				},
+				imagePullFunc: func(ref string, _ client.ImagePullOptions) (client.ImagePullResponse, error) {
+					pulled = append(pulled, ref)
+					return fakeStreamResult{ReadCloser: http.NoBody}, nil
+				},
+			})
+			cli.SetRegistryClient(registry)
+			cmd := newOutdatedCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			assert.NilError(t, cmd.Execute())
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+			assert.Check(t, is.DeepEqual(pulled, tc.pulled))
+		})
+	}
+}
+
+func TestOutdatedErrors(t *testing.T) {
+	t.Run("registry error", func(t *testing.T) {
+		cli := test.NewFakeCli(&fakeClient{
+			imageListFunc: func(client.ImageListOptions) (client.ImageListResult, error) {
+				return client.ImageListResult{Items: []image.Summary{{ID: "sha256:1111", RepoTags: []string{"example.com/app:1.0"}}}}, nil
+			},
+		})
+		cli.SetRegistryClient(&erroringRegistryClient{err: errors.New("connection refused")})
+		cmd := newOutdatedCommand(cli)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--all", "--format", "{{.Tag}} {{.Status}}"})
+		err := cmd.Execute()
+		assert.Check(t, is.ErrorContains(err, "failed to check example.com/app:1.0: connection refused"))
+		assert.Check(t, is.Equal(cli.OutBuffer().String(), "1.0 unknown\n"))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		cli := test.NewFakeCli(&fakeClient{
+			imageListFunc: func(options client.ImageListOptions) (client.ImageListResult, error) {
+				assert.Check(t, is.DeepEqual(options.Filters, client.Filters{"reference": {"alpine": true}}))
+				return client.ImageListResult{}, nil
+			},
+		})
+		cli.SetRegistryClient(newFakeRegistryClient())
+		cmd := newOutdatedCommand(cli)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"alpine"})
+		assert.NilError(t, cmd.Execute())
+	})
+
+	t.Run("invalid concurrency", func(t *testing.T) {
+		cmd := newOutdatedCommand(test.NewFakeCli(&fakeClient{}))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--max-concurrent-requests", "0"})
+		assert.Check(t, is.Error(cmd.Execute(), "--max-concurrent-requests must be at least 1"))
+	})
+}
@@ -25,9 +25,12 @@ func init() {
 }
 
 type pruneOptions struct {
-	force  bool
-	all    bool
-	filter opts.FilterOpt
+	force      bool
+	all        bool
+	filter     opts.FilterOpt
+	dryRun     bool
+	policyFile string
+	policy     retentionPolicy
 }
 
 // newPruneCommand returns a new cobra prune command for images
@@ -59,6 +62,14 @@ func newPruneCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.BoolVarP(&options.all, "all", "a", false, "Remove all unused images, not just dangling ones")
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "until=<timestamp>")`)
 
+	flags.StringVar(&options.policyFile, "policy", "", "Read the retention policy from a JSON file")
+	flags.IntVar(&options.policy.KeepLast, "keep-last", 0, "Keep the N most recently created images of each repository")
+	flags.Var(&options.policy.KeepUsedWithin, "keep-used-within", `Keep images created, pulled, or tagged within the given duration (e.g. "72h")`)
+	flags.StringSliceVar(&options.policy.Keep, "keep", nil, `Never remove images matching the given reference pattern (e.g. "myorg/*")`)
+	flags.StringSliceVar(&options.policy.KeepLabels, "keep-label", nil, `Never remove images with the given label (e.g. "com.example.keep" or "com.example.keep=true")`)
+	flags.Var(&options.policy.MaxSize, "max-size", `Remove the oldest images until the total size is within the given budget (e.g. "20GB")`)
+	flags.BoolVar(&options.dryRun, "dry-run", false, "Show the images that would be removed by the retention policy, without removing them")
+
 	return cmd
 }
 
@@ -70,6 +81,28 @@ func newPruneCommand(dockerCLI command.Cli) *cobra.Command {
 )
 
 func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions) (spaceReclaimed uint64, output string, err error) {
+	policy := options.policy
+	if options.policyFile != "" {
+		p, err := loadRetentionPolicy(options.policyFile)
+		if err != nil {
+			return 0, "", err
+		}
+		// Options set through flags take precedence over the policy file.
+		policy = p.merge(policy)
+	}
+	if err := policy.validate(); err != nil {
+		return 0, "", err
+	}
+	if !policy.isEmpty() {
+		if options.all {
+			return 0, "", errors.New("conflicting options: --all cannot be used with a retention policy")
+		}
+		return runRetentionPrune(ctx, dockerCli, policy, options)
+	}
+	if options.dryRun {
+		return 0, "", errors.New("--dry-run can only be used with a retention policy")
+	}
+
 	pruneFilters := command.PruneFilters(dockerCli, options.filter.Value())
 	pruneFilters.Add("dangling", strconv.FormatBool(!options.all))
 
@@ -113,6 +146,36 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 	return res.Report.SpaceReclaimed, sb.String(), nil
 }
 
+const retentionWarning = `WARNING! This will remove the image references listed above.
+Are you sure you want to continue?`
+
+// runRetentionPrune removes the images that are not retained by the given
+// retention policy.
+func runRetentionPrune(ctx context.Context, dockerCli command.Cli, policy retentionPolicy, options pruneOptions) (spaceReclaimed uint64, output string, err error) {
+	plan, err := getRetentionPlan(ctx, dockerCli.Client(), policy, options.filter)
+	if err != nil {
+		return 0, "", err
+	}
+	if options.dryRun {
+		printRetentionPlan(dockerCli.Out(), plan)
+		return 0, "", nil
+	}
+	if len(plan.remove) == 0 {
+		return 0, "", nil
+	}
+	if !options.force {
+		printRetentionPlan(dockerCli.Out(), plan)
+		r, err := prompt.Confirm(ctx, dockerCli.In(), dockerCli.Out(), retentionWarning)
+		if err != nil {
+			return 0, "", err
+		}
+		if !r {
+			return 0, "", cancelledErr{errors.New("image prune has been cancelled")}
+		}
+	}
+	return executeRetentionPlan(ctx, dockerCli.Client(), plan)
+}
+
 type cancelledErr struct{ error }
 
 func (cancelledErr) Cancelled() {}
@@ -120,6 +183,24 @@ func (cancelledErr) Cancelled() {}
 // pruneFn calls the Image Prune API for use in "docker system prune",
 // and returns the amount of space reclaimed and a detailed output string.
 func pruneFn(ctx context.Context, dockerCLI command.Cli, options pruner.PruneOptions) (uint64, string, error) {
+	if options.ImagePolicy != "" {
+		if options.All {
+			return 0, "", errors.New("conflicting options: --all cannot be used with --image-policy")
+		}
+		policy, err := loadRetentionPolicy(options.ImagePolicy)
+		if err != nil {
+			return 0, "", err
+		}
+		if !options.Confirmed {
+			// Dry-run: show the image references that would be removed.
+			plan, err := getRetentionPlan(ctx, dockerCLI.Client(), policy, options.Filter)
+			if err != nil {
+				return 0, "", err
+			}
+			return 0, retentionConfirmMessage(plan, options.ImagePolicy), cancelledErr{errors.New("image prune has been cancelled")}
+		}
+		return runRetentionPrune(ctx, dockerCLI, policy, pruneOptions{force: true, filter: options.Filter})
+	}
 	if !options.Confirmed {
 		// Dry-run: perform validation and produce confirmation before pruning.
 		var confirmMsg string
@@ -136,3 +217,18 @@ func pruneFn(ctx context.Context, dockerCLI command.Cli, options pruner.PruneOpt
 		filter: options.Filter,
 	})
 }
+
+// retentionConfirmMessage returns the confirmation message for "docker system
+// prune", which lists the image references in the plan.
+func retentionConfirmMessage(plan retentionPlan, policyFile string) string {
+	if len(plan.remove) == 0 {
+		return "no images, as all images are retained by the retention policy in " + policyFile
+	}
+	var sb strings.Builder
+	sb.WriteString("images not retained by the retention policy in " + policyFile + ":")
+	for _, c := range plan.remove {
+		sb.WriteString("\n      - " + displayRef(c) + " (" + c.reason + ")")
+	}
+	sb.WriteString("\n    Estimated reclaimable space: " + units.HumanSize(float64(plan.spaceReclaimed)))
+	return sb.String()
+}
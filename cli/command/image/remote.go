@@ -0,0 +1,191 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/registryclient"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+	"github.com/moby/moby/api/types/image"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func isIndexMediaType(mediaType string) bool {
+	return mediaType == ocispec.MediaTypeImageIndex || mediaType == manifestlist.MediaTypeManifestList
+}
+
+func isManifestMediaType(mediaType string) bool {
+	return mediaType == ocispec.MediaTypeImageManifest || mediaType == schema2.MediaTypeManifest
+}
+
+// inspectRemote fetches the manifest (or image index) and image config of an
+// image from its registry, and presents them in the same format as a local
+// image.
+//
+// If the image is a multi-platform image, the Manifests field lists all
+// manifests in the index, and the image config of the given platform is used.
+// If no platform is given, the platform matching the local machine is used,
+// or the first image in the index if there is no match.
+//
+// The size of the image is the size of its compressed layers, as stored in
+// the registry.
+func inspectRemote(ctx context.Context, registryClient registryclient.RegistryClient, ref string, platform *ocispec.Platform) (image.InspectResponse, error) {
+	namedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return image.InspectResponse{}, err
+	}
+	namedRef = reference.TagNameOnly(namedRef)
+	repo := reference.TrimNamed(namedRef)
+
+	desc, raw, err := registryClient.GetRawManifest(ctx, namedRef)
+	if err != nil {
+		return image.InspectResponse{}, err
+	}
+	canonical, err := reference.WithDigest(repo, desc.Digest)
+	if err != nil {
+		return image.InspectResponse{}, err
+	}
+	resp := image.InspectResponse{
+		ID:          desc.Digest.String(),
+		RepoTags:    []string{},
+		RepoDigests: []string{reference.FamiliarString(canonical)},
+		Descriptor:  &desc,
+	}
+	if tagged, ok := namedRef.(reference.NamedTagged); ok {
+		resp.RepoTags = append(resp.RepoTags, reference.FamiliarString(tagged))
+	}
+
+	var manifest ocispec.Manifest
+	switch {
+	case isIndexMediaType(desc.MediaType):
+		var index ocispec.Index
+		if err := json.Unmarshal(raw, &index); err != nil {
+			return image.InspectResponse{}, fmt.Errorf("invalid image index for %s: %w", ref, err)
+		}
+		var selected int
+		resp.Manifests, selected, manifest, err = remoteManifests(ctx, registryClient, repo, index, platform)
+		if err != nil {
+			return image.InspectResponse{}, err
+		}
+		if selected < 0 {
+			if platform != nil {
+				return image.InspectResponse{}, fmt.Errorf("image with reference %s was found but does not provide the specified platform (%s)", ref, platforms.FormatAll(*platform))
+			}
+			return image.InspectResponse{}, fmt.Errorf("image with reference %s does not contain any image manifests", ref)
+		}
+	case isManifestMediaType(desc.MediaType):
+		if err := json.Unmarshal(raw, &manifest); err != nil {
+			return image.InspectResponse{}, fmt.Errorf("invalid image manifest for %s: %w", ref, err)
+		}
+	default:
+		return image.InspectResponse{}, fmt.Errorf("%s is not an image: unsupported media type %q", ref, desc.MediaType)
+	}
+
+	configJSON, err := registryClient.GetBlob(ctx, repo, manifest.Config.Digest)
+	if err != nil {
+		return image.InspectResponse{}, fmt.Errorf("failed to fetch image config for %s: %w", ref, err)
+	}
+	var img dockerspec.DockerOCIImage
+	if err := json.Unmarshal(configJSON, &img); err != nil {
+		return image.InspectResponse{}, fmt.Errorf("invalid image config for %s: %w", ref, err)
+	}
+	if platform != nil && !platforms.OnlyStrict(*platform).Match(img.Platform) {
+		return image.InspectResponse{}, fmt.Errorf("image with reference %s was found but does not provide the specified platform (%s)", ref, platforms.FormatAll(*platform))
+	}
+
+	resp.Author = img.Author
+	if img.Created != nil {
+		resp.Created = img.Created.Format(time.RFC3339Nano)
+	}
+	resp.Config = &img.Config
+	resp.Architecture = img.Architecture
+	resp.Variant = img.Variant
+	resp.Os = img.OS
+	resp.OsVersion = img.OSVersion
+	resp.RootFS = image.RootFS{Type: img.RootFS.Type}
+	for _, diffID := range img.RootFS.DiffIDs {
+		resp.RootFS.Layers = append(resp.RootFS.Layers, diffID.String())
+	}
+	for _, layer := range manifest.Layers {
+		resp.Size += layer.Size
+	}
+	return resp, nil
+}
+
+// remoteManifests fetches the manifests in the image index, and returns a
+// summary for each of them. It also returns the index and content of the image
+// manifest that matches the platform, or -1 if there is no match.
+func remoteManifests(ctx context.Context, registryClient registryclient.RegistryClient, repo reference.Named, index ocispec.Index, platform *ocispec.Platform) ([]image.ManifestSummary, int, ocispec.Manifest, error) {
+	matcher := platforms.Only(platforms.DefaultSpec())
+	if platform != nil {
+		matcher = platforms.OnlyStrict(*platform)
+	}
+
+	var (
+		summaries = make([]image.ManifestSummary, 0, len(index.Manifests))
+		selected  = -1
+		first     = -1
+		manifests = make([]ocispec.Manifest, len(index.Manifests))
+	)
+	for i, desc := range index.Manifests {
+		summary := image.ManifestSummary{
+			ID:         desc.Digest.String(),
+			Descriptor: desc,
+			Kind:       image.ManifestKindUnknown,
+		}
+		summary.Size.Content = desc.Size
+
+		if isManifestMediaType(desc.MediaType) {
+			manifestRef, err := reference.WithDigest(repo, desc.Digest)
+			if err != nil {
+				return nil, -1, ocispec.Manifest{}, err
+			}
+			_, raw, err := registryClient.GetRawManifest(ctx, manifestRef)
+			if err != nil {
+				return nil, -1, ocispec.Manifest{}, err
+			}
+			if err := json.Unmarshal(raw, &manifests[i]); err != nil {
+				return nil, -1, ocispec.Manifest{}, fmt.Errorf("invalid image manifest %s: %w", desc.Digest, err)
+			}
+			summary.Size.Content += manifests[i].Config.Size
+			for _, layer := range manifests[i].Layers {
+				summary.Size.Content += layer.Size
+			}
+
+			switch {
+			case desc.Annotations[manifesttypes.AnnotationReferenceType] == manifesttypes.AttestationManifestType:
+				summary.Kind = image.ManifestKindAttestation
+				summary.AttestationData = &image.AttestationProperties{
+					For: digest.Digest(desc.Annotations[manifesttypes.AnnotationReferenceDigest]),
+				}
+			case desc.Platform != nil:
+				summary.Kind = image.ManifestKindImage
+				summary.ImageData = &image.ImageProperties{Platform: *desc.Platform}
+				if first < 0 {
+					first = i
+				}
+				if selected < 0 && matcher.Match(*desc.Platform) {
+					selected = i
+				}
+			}
+		}
+		summary.Size.Total = summary.Size.Content
+		summaries = append(summaries, summary)
+	}
+
+	if selected < 0 && platform == nil {
+		selected = first
+	}
+	if selected < 0 {
+		return summaries, -1, ocispec.Manifest{}, nil
+	}
+	return summaries, selected, manifests[selected], nil
+}
@@ -0,0 +1,226 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/cli/internal/jsonstream"
+	"github.com/moby/moby/client"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+type retagOptions struct {
+	from           string
+	to             string
+	dryRun         bool
+	push           bool
+	untag          bool
+	maxConcurrency int
+}
+
+// newRetagCommand creates a new "docker image retag" command.
+func newRetagCommand(dockerCLI command.Cli) *cobra.Command {
+	var opts retagOptions
+
+	cmd := &cobra.Command{
+		Use:   "retag [OPTIONS] --from PATTERN --to REPLACEMENT",
+		Short: "Tag local images by mapping their references with a regular expression",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRetag(cmd.Context(), dockerCLI, opts)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.from, "from", "", `Regular expression matching the references to retag (e.g. "old.registry/(.*)")`)
+	flags.StringVar(&opts.to, "to", "", `Replacement for matching references, which can refer to submatches (e.g. "new.registry/$1")`)
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Show the tags that would be created, without creating them")
+	flags.BoolVar(&opts.push, "push", false, "Push the new tags to their registry")
+	flags.BoolVar(&opts.untag, "untag", false, "Remove the original tags after retagging (and pushing)")
+	flags.IntVar(&opts.maxConcurrency, "max-concurrent-pushes", 3, "Maximum number of concurrent pushes")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	_ = cmd.RegisterFlagCompletionFunc("from", cobra.NoFileCompletions)
+	_ = cmd.RegisterFlagCompletionFunc("to", cobra.NoFileCompletions)
+	return cmd
+}
+
+// retagMapping maps a local image reference to a new reference.
+type retagMapping struct {
+	source string
+	target string
+}
+
+// planRetag applies the regular expression mapping to the given references.
+// The expression must match the whole familiar reference (for example,
+// "alpine:latest" or "old.registry/app:1.0"). References without a tag get
+// the default ("latest") tag.
+func planRetag(from *regexp.Regexp, to string, refs []string) ([]retagMapping, error) {
+	var (
+		mappings []retagMapping
+		errs     []error
+		targets  = make(map[string]string)
+	)
+	for _, ref := range refs {
+		if !from.MatchString(ref) {
+			continue
+		}
+		newRef := from.ReplaceAllString(ref, to)
+		named, err := reference.ParseNormalizedNamed(newRef)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid reference for %s: %s: %w", ref, newRef, err))
+			continue
+		}
+		if _, ok := named.(reference.Digested); ok {
+			errs = append(errs, fmt.Errorf("invalid reference for %s: %s: cannot tag with a digest", ref, newRef))
+			continue
+		}
+		target := reference.FamiliarString(reference.TagNameOnly(named))
+		if target == ref {
+			continue
+		}
+		if other, ok := targets[target]; ok {
+			errs = append(errs, fmt.Errorf("conflicting mapping: both %s and %s map to %s", other, ref, target))
+			continue
+		}
+		targets[target] = ref
+		mappings = append(mappings, retagMapping{source: ref, target: target})
+	}
+	return mappings, errors.Join(errs...)
+}
+
+func runRetag(ctx context.Context, dockerCLI command.Cli, opts retagOptions) error {
+	if opts.maxConcurrency < 1 {
+		return errors.New("--max-concurrent-pushes must be at least 1")
+	}
+	from, err := regexp.Compile("^(?:" + opts.from + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid --from pattern: %w", err)
+	}
+
+	apiClient := dockerCLI.Client()
+	res, err := apiClient.ImageList(ctx, client.ImageListOptions{})
+	if err != nil {
+		return err
+	}
+	var refs []string
+	for _, img := range res.Items {
+		for _, tag := range img.RepoTags {
+			if named, err := reference.ParseNormalizedNamed(tag); err == nil {
+				refs = append(refs, reference.FamiliarString(named))
+			}
+		}
+	}
+	slices.Sort(refs)
+
+	mappings, err := planRetag(from, opts.to, refs)
+	if err != nil {
+		return err
+	}
+	if len(mappings) == 0 {
+		_, _ = fmt.Fprintln(dockerCLI.Err(), "No images matching", opts.from)
+		return nil
+	}
+
+	out := dockerCLI.Out()
+	if opts.dryRun {
+		for _, m := range mappings {
+			_, _ = fmt.Fprintf(out, "%s -> %s\n", m.source, m.target)
+		}
+		return nil
+	}
+
+	var errs []error
+	tagged := make([]retagMapping, 0, len(mappings))
+	for _, m := range mappings {
+		if _, err := apiClient.ImageTag(ctx, client.ImageTagOptions{Source: m.source, Target: m.target}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to tag %s as %s: %w", m.source, m.target, err))
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "Tagged %s as %s\n", m.source, m.target)
+		tagged = append(tagged, m)
+	}
+
+	done := tagged
+	if opts.push {
+		var pushErrs []error
+		done, pushErrs = pushRetagged(ctx, dockerCLI, tagged, opts.maxConcurrency)
+		errs = append(errs, pushErrs...)
+	}
+
+	if opts.untag {
+		for _, m := range done {
+			if _, err := apiClient.ImageRemove(ctx, m.source, client.ImageRemoveOptions{}); err != nil {
+				errs = append(errs, fmt.Errorf("failed to untag %s: %w", m.source, err))
+				continue
+			}
+			_, _ = fmt.Fprintln(out, "Untagged", m.source)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pushRetagged pushes the new tags, with at most maxConcurrency pushes
+// running concurrently. It returns the mappings that were pushed successfully.
+func pushRetagged(ctx context.Context, dockerCLI command.Cli, mappings []retagMapping, maxConcurrency int) ([]retagMapping, []error) {
+	var (
+		mu     sync.Mutex
+		pushed = make([]bool, len(mappings))
+		errs   []error
+	)
+	var eg errgroup.Group
+	eg.SetLimit(maxConcurrency)
+	for i, m := range mappings {
+		eg.Go(func() error {
+			err := pushQuiet(ctx, dockerCLI, m.target)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to push %s: %w", m.target, err))
+				return nil
+			}
+			pushed[i] = true
+			_, _ = fmt.Fprintln(dockerCLI.Out(), "Pushed", m.target)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	var done []retagMapping
+	for i, m := range mappings {
+		if pushed[i] {
+			done = append(done, m)
+		}
+	}
+	return done, errs
+}
+
+// pushQuiet pushes the given reference, discarding the progress output.
+func pushQuiet(ctx context.Context, dockerCLI command.Cli, ref string) error {
+	encodedAuth, err := command.RetrieveAuthTokenFromImage(dockerCLI.ConfigFile(), ref)
+	if err != nil {
+		return err
+	}
+	responseBody, err := dockerCLI.Client().ImagePush(ctx, ref, client.ImagePushOptions{
+		RegistryAuth: encodedAuth,
+	})
+	if err != nil {
+		return err
+	}
+	defer responseBody.Close()
+	return jsonstream.Display(ctx, responseBody, streams.NewOut(io.Discard))
+}
@@ -0,0 +1,141 @@
+package image
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/client"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestPlanRetag(t *testing.T) {
+	refs := []string{
+		"alpine:latest",
+		"old.registry/app:1.0",
+		"old.registry/team/tool:2",
+		"other.registry/app:1.0",
+	}
+	mappings, err := planRetag(regexp.MustCompile(`^(?:old.registry/(.*))$`), "new.registry/$1", refs)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(mappings, []retagMapping{
+		{source: "old.registry/app:1.0", target: "new.registry/app:1.0"},
+		{source: "old.registry/team/tool:2", target: "new.registry/team/tool:2"},
+	}, cmp.AllowUnexported(retagMapping{})))
+
+	// Docker Hub references use their familiar form, and references without
+	// a tag get the default tag.
+	mappings, err = planRetag(regexp.MustCompile(`^(?:([^:]+):.*)$`), "mirror.local/${1}", []string{"alpine:3.20"})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(mappings, []retagMapping{{source: "alpine:3.20", target: "mirror.local/alpine:latest"}}, cmp.AllowUnexported(retagMapping{})))
+}
+
+func TestPlanRetagErrors(t *testing.T) {
+	_, err := planRetag(regexp.MustCompile(`^(?:.*/(app):.*)$`), "new.registry/$1", []string{"a.registry/app:1", "b.registry/app:2"})
+	assert.Check(t, is.ErrorContains(err, "conflicting mapping: both a.registry/app:1 and b.registry/app:2 map to new.registry/app:latest"))
+
+	_, err = planRetag(regexp.MustCompile(`^(?:.*)$`), "Invalid Reference", []string{"alpine:latest"})
+	assert.Check(t, is.ErrorContains(err, "invalid reference for alpine:latest"))
+}
+
+func retagFakeClient(tagged, pushed, removed *[]string) *fakeClient {
+	return &fakeClient{
+		imageListFunc: func(client.ImageListOptions) (client.ImageListResult, error) {
+			return client.ImageListResult{Items: []image.Summary{
+				{ID: "sha256:1", RepoTags: []string{"old.registry/app:1.0", "old.registry/app:latest"}},
+				{ID: "sha256:2", RepoTags: []string{"old.registry/fail:1.0"}},
+				{ID: "sha256:3", RepoTags: []string{"alpine:latest"}},
+			}}, nil
+		},
+		imageTagFunc: func(options client.ImageTagOptions) (client.ImageTagResult, error) {
+			*tagged = append(*tagged, options.Source+"="+options.Target)
+			return client.ImageTagResult{}, nil
+		},
+		imagePushFunc: func(ref string, options client.ImagePushOptions) (client.ImagePushResponse, error) {
+			if ref == "new.registry/fail:1.0" {
+				return nil, errors.New("denied")
+			}
+			*pushed = append(*pushed, ref)
+			return fakeStreamResult{ReadCloser: http.NoBody}, nil
+		},
+		imageRemoveFunc: func(img string, options client.ImageRemoveOptions) (client.ImageRemoveResult, error) {
+			*removed = append(*removed, img)
+			return client.ImageRemoveResult{}, nil
+		},
+	}
+}
+
+func TestRetagDryRun(t *testing.T) {
+	var tagged, pushed, removed []string
+	cli := test.NewFakeCli(retagFakeClient(&tagged, &pushed, &removed))
+	cmd := newRetagCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"--from", "old.registry/(.*)", "--to", "new.registry/$1", "--dry-run", "--push", "--untag"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), `old.registry/app:1.0 -> new.registry/app:1.0
+old.registry/app:latest -> new.registry/app:latest
+old.registry/fail:1.0 -> new.registry/fail:1.0
+`))
+	assert.Check(t, is.Len(tagged, 0))
+	assert.Check(t, is.Len(pushed, 0))
+	assert.Check(t, is.Len(removed, 0))
+}
+
+func TestRetagPushAndUntag(t *testing.T) {
+	var tagged, pushed, removed []string
+	cli := test.NewFakeCli(retagFakeClient(&tagged, &pushed, &removed))
+	cmd := newRetagCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--from", "old.registry/(.*)", "--to", "new.registry/$1", "--push", "--untag", "--max-concurrent-pushes", "1"})
+	err := cmd.Execute()
+	assert.Check(t, is.ErrorContains(err, "failed to push new.registry/fail:1.0: denied"))
+
+	assert.Check(t, is.DeepEqual(tagged, []string{
+		"old.registry/app:1.0=new.registry/app:1.0",
+		"old.registry/app:latest=new.registry/app:latest",
+		"old.registry/fail:1.0=new.registry/fail:1.0",
+	}))
+	assert.Check(t, is.DeepEqual(pushed, []string{"new.registry/app:1.0", "new.registry/app:latest"}))
+	// Only references that were pushed successfully are untagged.
+	assert.Check(t, is.DeepEqual(removed, []string{"old.registry/app:1.0", "old.registry/app:latest"}))
+}
+
+func TestRetagErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "missing flags",
+			args:          []string{},
+			expectedError: `required flag(s) "from", "to" not set`,
+		},
+		{
+			name:          "invalid pattern",
+			args:          []string{"--from", "(", "--to", "foo"},
+			expectedError: "invalid --from pattern",
+		},
+		{
+			name:          "invalid concurrency",
+			args:          []string{"--from", "foo", "--to", "bar", "--max-concurrent-pushes", "0"},
+			expectedError: "--max-concurrent-pushes must be at least 1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := newRetagCommand(test.NewFakeCli(&fakeClient{}))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,469 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/opts"
+	"github.com/docker/go-units"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/client"
+)
+
+// retentionPolicy describes which images to keep when pruning images with
+// a retention policy. Images that are not retained by the policy are removed.
+//
+// A retention policy can be loaded from a JSON file, for example:
+//
+//	{
+//	  "keepLast": 3,
+//	  "keepUsedWithin": "168h",
+//	  "keep": ["docker.io/library/*"],
+//	  "keepLabels": ["com.example.keep"],
+//	  "maxSize": "20GB"
+//	}
+type retentionPolicy struct {
+	// KeepLast is the number of most recently created images to keep for
+	// each repository.
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// KeepUsedWithin keeps images that were created, pulled, or tagged
+	// within the given duration.
+	KeepUsedWithin duration `json:"keepUsedWithin,omitempty"`
+
+	// Keep is a list of reference patterns (for example, "myorg/*" or
+	// "alpine:3.*") for images that must never be removed.
+	Keep []string `json:"keep,omitempty"`
+
+	// KeepLabels is a list of labels ("key" or "key=value") for images that
+	// must never be removed.
+	KeepLabels []string `json:"keepLabels,omitempty"`
+
+	// MaxSize is the total size budget (for example, "20GB") for images.
+	// If the images retained by the policy exceed the budget, the oldest
+	// ones are removed until the total size is within the budget.
+	MaxSize size `json:"maxSize,omitempty"`
+}
+
+// isEmpty returns whether the policy has no rules.
+func (p retentionPolicy) isEmpty() bool {
+	return p.KeepLast == 0 && p.KeepUsedWithin == 0 && p.MaxSize == 0
+}
+
+// hasRetentionRules returns whether the policy has rules that select which
+// images to retain (as opposed to only a size budget).
+func (p retentionPolicy) hasRetentionRules() bool {
+	return p.KeepLast > 0 || p.KeepUsedWithin > 0
+}
+
+func (p retentionPolicy) validate() error {
+	if p.KeepLast < 0 {
+		return fmt.Errorf("invalid retention policy: keepLast must be a positive number: %d", p.KeepLast)
+	}
+	if p.KeepUsedWithin < 0 {
+		return errors.New("invalid retention policy: keepUsedWithin must be a positive duration")
+	}
+	if p.MaxSize < 0 {
+		return errors.New("invalid retention policy: maxSize must be a positive size")
+	}
+	for _, pattern := range p.Keep {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid retention policy: invalid reference pattern %q: %w", pattern, err)
+		}
+	}
+	for _, l := range p.KeepLabels {
+		if k, _, _ := strings.Cut(l, "="); k == "" {
+			return fmt.Errorf("invalid retention policy: invalid label %q", l)
+		}
+	}
+	if p.isEmpty() && (len(p.Keep) > 0 || len(p.KeepLabels) > 0) {
+		// Without other rules, the policy would not be used, and the images
+		// to keep would be removed if they are dangling.
+		return errors.New("invalid retention policy: keep and keepLabels require keepLast, keepUsedWithin, or maxSize to be set")
+	}
+	return nil
+}
+
+// merge overlays the non-zero values of other on p.
+func (p retentionPolicy) merge(other retentionPolicy) retentionPolicy {
+	if other.KeepLast != 0 {
+		p.KeepLast = other.KeepLast
+	}
+	if other.KeepUsedWithin != 0 {
+		p.KeepUsedWithin = other.KeepUsedWithin
+	}
+	if other.MaxSize != 0 {
+		p.MaxSize = other.MaxSize
+	}
+	p.Keep = append(p.Keep, other.Keep...)
+	p.KeepLabels = append(p.KeepLabels, other.KeepLabels...)
+	return p
+}
+
+// loadRetentionPolicy loads a retention policy from a JSON file.
+func loadRetentionPolicy(filename string) (retentionPolicy, error) {
+	var p retentionPolicy
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return p, fmt.Errorf("failed to read retention policy: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return p, fmt.Errorf("failed to parse retention policy %s: %w", filename, err)
+	}
+	return p, p.validate()
+}
+
+// duration is a [time.Duration] that is (un)marshaled as a string, such as
+// "72h". It implements [pflag.Value] to allow it to be used as a flag.
+//
+// [pflag.Value]: https://pkg.go.dev/github.com/spf13/pflag#Value
+type duration time.Duration
+
+func (d *duration) Set(v string) error {
+	n, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	*d = duration(n)
+	return nil
+}
+
+func (d *duration) String() string {
+	if *d == 0 {
+		return ""
+	}
+	return time.Duration(*d).String()
+}
+
+func (*duration) Type() string {
+	return "duration"
+}
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return d.Set(s)
+}
+
+// size is a size in bytes that is (un)marshaled as a human-readable string,
+// such as "20GB". It implements [pflag.Value] to allow it to be used as a flag.
+//
+// [pflag.Value]: https://pkg.go.dev/github.com/spf13/pflag#Value
+type size int64
+
+func (s *size) Set(v string) error {
+	n, err := units.FromHumanSize(v)
+	if err != nil {
+		return err
+	}
+	*s = size(n)
+	return nil
+}
+
+func (s *size) String() string {
+	if *s == 0 {
+		return ""
+	}
+	return units.HumanSize(float64(*s))
+}
+
+func (*size) Type() string {
+	return "bytes"
+}
+
+func (s size) MarshalJSON() ([]byte, error) {
+	return json.Marshal(units.HumanSize(float64(s)))
+}
+
+func (s *size) UnmarshalJSON(b []byte) error {
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	return s.Set(v)
+}
+
+// pruneCandidate is a reference to an image that is considered for removal;
+// either a tag, or the ID of an untagged image.
+type pruneCandidate struct {
+	ref      string
+	repo     string
+	img      *image.Summary
+	created  time.Time
+	lastUsed time.Time
+	reason   string
+}
+
+// retentionPlan is the result of applying a retention policy to the local
+// images.
+type retentionPlan struct {
+	remove []pruneCandidate
+	// spaceReclaimed is the sum of the size of images that have all their
+	// references removed. It's an estimate, as layers can be shared between
+	// images.
+	spaceReclaimed int64
+	totalSize      int64
+}
+
+// planRetention determines which image references to remove according to
+// the given policy. Images used by containers (inUse), or matching one of the
+// keep patterns or labels, are never removed. lastTagged holds the time each
+// image was last pulled or tagged, if known.
+func planRetention(policy retentionPolicy, images []image.Summary, inUse map[string]bool, lastTagged map[string]time.Time, now time.Time) retentionPlan {
+	var (
+		plan       retentionPlan
+		candidates []*pruneCandidate
+		refCount   = make(map[string]int)
+	)
+	for i := range images {
+		img := &images[i]
+		plan.totalSize += img.Size
+		if inUse[img.ID] || matchesLabels(img.Labels, policy.KeepLabels) {
+			continue
+		}
+		created := time.Unix(img.Created, 0)
+		lastUsed := created
+		if t := lastTagged[img.ID]; t.After(lastUsed) {
+			lastUsed = t
+		}
+		if len(img.RepoTags) == 0 {
+			refCount[img.ID]++
+			candidates = append(candidates, &pruneCandidate{ref: img.ID, img: img, created: created, lastUsed: lastUsed})
+			continue
+		}
+		protected := false
+		for _, tag := range img.RepoTags {
+			if matchesPatterns(tag, policy.Keep) {
+				protected = true
+				break
+			}
+		}
+		if protected {
+			continue
+		}
+		for _, tag := range img.RepoTags {
+			repo := tag
+			if named, err := reference.ParseNormalizedNamed(tag); err == nil {
+				repo = reference.FamiliarName(named)
+			}
+			refCount[img.ID]++
+			candidates = append(candidates, &pruneCandidate{ref: tag, repo: repo, img: img, created: created, lastUsed: lastUsed})
+		}
+	}
+
+	// Images that are retained by one of the retention rules.
+	retained := make(map[*pruneCandidate]bool)
+	if !policy.hasRetentionRules() {
+		for _, c := range candidates {
+			retained[c] = true
+		}
+	}
+	if policy.KeepUsedWithin > 0 {
+		cutOff := now.Add(-time.Duration(policy.KeepUsedWithin))
+		for _, c := range candidates {
+			if c.lastUsed.After(cutOff) {
+				retained[c] = true
+			}
+		}
+	}
+	if policy.KeepLast > 0 {
+		byRepo := make(map[string][]*pruneCandidate)
+		for _, c := range candidates {
+			if c.repo != "" {
+				byRepo[c.repo] = append(byRepo[c.repo], c)
+			}
+		}
+		for _, refs := range byRepo {
+			slices.SortStableFunc(refs, func(a, b *pruneCandidate) int {
+				return b.created.Compare(a.created)
+			})
+			// Count images, not tags, so that all tags of the same image
+			// within a repository (e.g. "app:1.2" and "app:latest") are kept.
+			keep := make(map[string]bool)
+			for _, c := range refs {
+				if !keep[c.img.ID] && len(keep) == policy.KeepLast {
+					continue
+				}
+				keep[c.img.ID] = true
+				retained[c] = true
+			}
+		}
+	}
+
+	removed := make(map[string]int)
+	remaining := plan.totalSize
+	removeRef := func(c *pruneCandidate, reason string) {
+		c.reason = reason
+		plan.remove = append(plan.remove, *c)
+		removed[c.img.ID]++
+		if removed[c.img.ID] == refCount[c.img.ID] {
+			plan.spaceReclaimed += c.img.Size
+			remaining -= c.img.Size
+		}
+	}
+
+	// Sort oldest first, so that the plan is presented in a stable order,
+	// and the size budget removes the oldest images first.
+	slices.SortStableFunc(candidates, func(a, b *pruneCandidate) int {
+		if n := a.created.Compare(b.created); n != 0 {
+			return n
+		}
+		return strings.Compare(a.ref, b.ref)
+	})
+	for _, c := range candidates {
+		if !retained[c] {
+			removeRef(c, "not retained by policy")
+		}
+	}
+	if policy.MaxSize > 0 {
+		for _, c := range candidates {
+			if remaining <= int64(policy.MaxSize) {
+				break
+			}
+			if retained[c] {
+				removeRef(c, "exceeds size budget")
+			}
+		}
+	}
+	return plan
+}
+
+func matchesPatterns(ref string, patterns []string) bool {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, _ := reference.FamiliarMatch(pattern, named); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesLabels(labels map[string]string, keepLabels []string) bool {
+	for _, l := range keepLabels {
+		k, v, hasValue := strings.Cut(l, "=")
+		if actual, ok := labels[k]; ok && (!hasValue || actual == v) {
+			return true
+		}
+	}
+	return false
+}
+
+// getRetentionPlan collects the local images and containers, and applies the
+// retention policy to them.
+func getRetentionPlan(ctx context.Context, apiClient client.APIClient, policy retentionPolicy, filter opts.FilterOpt) (retentionPlan, error) {
+	imgs, err := apiClient.ImageList(ctx, client.ImageListOptions{All: false, Filters: filter.Value()})
+	if err != nil {
+		return retentionPlan{}, err
+	}
+	ctrs, err := apiClient.ContainerList(ctx, client.ContainerListOptions{All: true})
+	if err != nil {
+		return retentionPlan{}, err
+	}
+	inUse := make(map[string]bool, len(ctrs.Items))
+	for _, c := range ctrs.Items {
+		inUse[c.ImageID] = true
+	}
+	lastTagged := make(map[string]time.Time)
+	if policy.KeepUsedWithin > 0 {
+		for _, img := range imgs.Items {
+			if inUse[img.ID] {
+				continue
+			}
+			res, err := apiClient.ImageInspect(ctx, img.ID)
+			if err != nil {
+				return retentionPlan{}, err
+			}
+			if res.Metadata.LastTagTime.IsZero() {
+				continue
+			}
+			lastTagged[img.ID] = res.Metadata.LastTagTime
+		}
+	}
+	return planRetention(policy, imgs.Items, inUse, lastTagged, time.Now()), nil
+}
+
+// printRetentionPlan prints the image references to remove as a table.
+func printRetentionPlan(out io.Writer, plan retentionPlan) {
+	if len(plan.remove) == 0 {
+		_, _ = fmt.Fprintln(out, "No images to remove")
+		return
+	}
+	w := tabwriter.NewWriter(out, 10, 1, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "IMAGE\tID\tCREATED\tSIZE\tREASON")
+	for _, c := range plan.remove {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			displayRef(c), formatter.TruncateID(c.img.ID),
+			units.HumanDuration(time.Since(c.created))+" ago",
+			units.HumanSize(float64(c.img.Size)), c.reason,
+		)
+	}
+	_ = w.Flush()
+	_, _ = fmt.Fprintln(out, "Estimated reclaimable space:", units.HumanSize(float64(plan.spaceReclaimed)))
+}
+
+// executeRetentionPlan removes the image references in the plan. Untagging
+// the last tag of an image removes the image.
+func executeRetentionPlan(ctx context.Context, apiClient client.APIClient, plan retentionPlan) (spaceReclaimed uint64, output string, _ error) {
+	var (
+		sb   strings.Builder
+		errs []error
+	)
+	removed := make(map[string]bool)
+	for _, c := range plan.remove {
+		res, err := apiClient.ImageRemove(ctx, c.ref, client.ImageRemoveOptions{PruneChildren: true})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, del := range res.Items {
+			if sb.Len() == 0 {
+				sb.WriteString("Deleted Images:\n")
+			}
+			if del.Untagged != "" {
+				sb.WriteString("untagged: " + del.Untagged + "\n")
+			} else if del.Deleted != "" {
+				sb.WriteString("deleted: " + del.Deleted + "\n")
+				if del.Deleted == c.img.ID && !removed[c.img.ID] {
+					removed[c.img.ID] = true
+					spaceReclaimed += uint64(c.img.Size)
+				}
+			}
+		}
+	}
+	return spaceReclaimed, sb.String(), errors.Join(errs...)
+}
+
+// displayRef returns the familiar form of the candidate's reference, or
+// "<none>" for untagged images.
+func displayRef(c pruneCandidate) string {
+	if c.repo == "" {
+		return "<none>"
+	}
+	if named, err := reference.ParseNormalizedNamed(c.ref); err == nil {
+		return reference.FamiliarString(named)
+	}
+	return c.ref
+}
@@ -0,0 +1,260 @@
+package image
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/cli/cli/command/system/pruner"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/cli/opts"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/client"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
+)
+
+func retentionTestImages(now time.Time) []image.Summary {
+	day := 24 * time.Hour
+	return []image.Summary{
+		{ID: "sha256:app1", RepoTags: []string{"example.com/app:1"}, Created: now.Add(-30 * day).Unix(), Size: 100},
+		{ID: "sha256:app2", RepoTags: []string{"example.com/app:2"}, Created: now.Add(-20 * day).Unix(), Size: 100},
+		{ID: "sha256:app3", RepoTags: []string{"example.com/app:3", "example.com/app:latest"}, Created: now.Add(-10 * day).Unix(), Size: 100},
+		{ID: "sha256:base", RepoTags: []string{"alpine:3.20"}, Created: now.Add(-40 * day).Unix(), Size: 10},
+		{ID: "sha256:labeled", RepoTags: []string{"tools:old"}, Created: now.Add(-50 * day).Unix(), Size: 10, Labels: map[string]string{"com.example.keep": "true"}},
+		{ID: "sha256:dangling", Created: now.Add(-60 * day).Unix(), Size: 50},
+		{ID: "sha256:running", RepoTags: []string{"example.com/app:0"}, Created: now.Add(-90 * day).Unix(), Size: 100},
+	}
+}
+
+func planRefs(plan retentionPlan) []string {
+	var refs []string
+	for _, c := range plan.remove {
+		refs = append(refs, c.ref)
+	}
+	return refs
+}
+
+func TestPlanRetention(t *testing.T) {
+	now := time.Now()
+	inUse := map[string]bool{"sha256:running": true}
+
+	testCases := []struct {
+		name       string
+		policy     retentionPolicy
+		lastTagged map[string]time.Time
+		expected   []string
+		reclaimed  int64
+	}{
+		{
+			name:      "keep last",
+			policy:    retentionPolicy{KeepLast: 2},
+			expected:  []string{"sha256:dangling", "example.com/app:1"},
+			reclaimed: 150,
+		},
+		{
+			name:      "keep last with patterns and labels",
+			policy:    retentionPolicy{KeepLast: 1, Keep: []string{"alpine:*"}, KeepLabels: []string{"com.example.keep=true"}},
+			expected:  []string{"sha256:dangling", "example.com/app:1", "example.com/app:2"},
+			reclaimed: 250,
+		},
+		{
+			name:      "keep used within",
+			policy:    retentionPolicy{KeepUsedWithin: duration(25 * 24 * time.Hour)},
+			expected:  []string{"sha256:dangling", "tools:old", "alpine:3.20", "example.com/app:1"},
+			reclaimed: 170,
+		},
+		{
+			name:       "keep used within recently pulled",
+			policy:     retentionPolicy{KeepUsedWithin: duration(25 * 24 * time.Hour)},
+			lastTagged: map[string]time.Time{"sha256:base": now.Add(-time.Hour)},
+			expected:   []string{"sha256:dangling", "tools:old", "example.com/app:1"},
+			reclaimed:  160,
+		},
+		{
+			name:      "size budget",
+			policy:    retentionPolicy{MaxSize: 300, KeepLabels: []string{"com.example.keep"}},
+			expected:  []string{"sha256:dangling", "alpine:3.20", "example.com/app:1", "example.com/app:2"},
+			reclaimed: 260,
+		},
+		{
+			name:      "nothing to remove",
+			policy:    retentionPolicy{MaxSize: 1000},
+			expected:  nil,
+			reclaimed: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := planRetention(tc.policy, retentionTestImages(now), inUse, tc.lastTagged, now)
+			assert.Check(t, is.DeepEqual(planRefs(plan), tc.expected))
+			assert.Check(t, is.Equal(plan.spaceReclaimed, tc.reclaimed))
+		})
+	}
+}
+
+func TestLoadRetentionPolicy(t *testing.T) {
+	dir := fs.NewDir(t, "policy",
+		fs.WithFile("valid.json", `{"keepLast": 3, "keepUsedWithin": "72h", "keep": ["myorg/*"], "keepLabels": ["keep"], "maxSize": "20GB"}`),
+		fs.WithFile("unknown.json", `{"keepFirst": 3}`),
+		fs.WithFile("invalid.json", `{"keepLast": -1}`),
+	)
+
+	p, err := loadRetentionPolicy(dir.Join("valid.json"))
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(p, retentionPolicy{
+		KeepLast:       3,
+		KeepUsedWithin: duration(72 * time.Hour),
+		Keep:           []string{"myorg/*"},
+		KeepLabels:     []string{"keep"},
+		MaxSize:        20_000_000_000,
+	}))
+
+	_, err = loadRetentionPolicy(dir.Join("unknown.json"))
+	assert.Check(t, is.ErrorContains(err, `unknown field "keepFirst"`))
+
+	_, err = loadRetentionPolicy(dir.Join("invalid.json"))
+	assert.Check(t, is.ErrorContains(err, "keepLast must be a positive number"))
+}
+
+func TestPruneRetentionDryRun(t *testing.T) {
+	now := time.Now()
+	cli := test.NewFakeCli(&fakeClient{
+		imageListFunc: func(client.ImageListOptions) (client.ImageListResult, error) {
+			return client.ImageListResult{Items: retentionTestImages(now)}, nil
+		},
+		containerListFunc: func(options client.ContainerListOptions) (client.ContainerListResult, error) {
+			assert.Check(t, options.All)
+			return client.ContainerListResult{Items: []container.Summary{{ImageID: "sha256:running"}}}, nil
+		},
+		imageRemoveFunc: func(string, client.ImageRemoveOptions) (client.ImageRemoveResult, error) {
+			t.Error("image should not be removed in dry-run mode")
+			return client.ImageRemoveResult{}, nil
+		},
+	})
+	cmd := newPruneCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"--keep-last", "2", "--dry-run"})
+	assert.NilError(t, cmd.Execute())
+
+	out := cli.OutBuffer().String()
+	assert.Check(t, is.Contains(out, "example.com/app:1"))
+	assert.Check(t, is.Contains(out, "<none>"))
+	assert.Check(t, !is.Contains(out, "example.com/app:latest")().Success())
+	assert.Check(t, is.Contains(out, "Estimated reclaimable space: 150B"))
+}
+
+func TestPruneRetentionForce(t *testing.T) {
+	now := time.Now()
+	var removed []string
+	cli := test.NewFakeCli(&fakeClient{
+		imageListFunc: func(client.ImageListOptions) (client.ImageListResult, error) {
+			return client.ImageListResult{Items: retentionTestImages(now)}, nil
+		},
+		imageRemoveFunc: func(img string, _ client.ImageRemoveOptions) (client.ImageRemoveResult, error) {
+			removed = append(removed, img)
+			return client.ImageRemoveResult{Items: []image.DeleteResponse{{Untagged: img}}}, nil
+		},
+	})
+	cmd := newPruneCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"--keep-last", "1", "--keep", "alpine", "--keep-label", "com.example.keep", "--force"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.DeepEqual(removed, []string{"example.com/app:0", "sha256:dangling", "example.com/app:1", "example.com/app:2"}))
+}
+
+func TestPruneRetentionErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "dry-run without policy",
+			args:          []string{"--dry-run"},
+			expectedError: "--dry-run can only be used with a retention policy",
+		},
+		{
+			name:          "all with policy",
+			args:          []string{"--all", "--keep-last", "1"},
+			expectedError: "--all cannot be used with a retention policy",
+		},
+		{
+			name:          "invalid size",
+			args:          []string{"--max-size", "lots"},
+			expectedError: `invalid argument "lots" for "--max-size" flag`,
+		},
+		{
+			name:          "keep without rules",
+			args:          []string{"--keep", "myorg/*"},
+			expectedError: "keep and keepLabels require keepLast, keepUsedWithin, or maxSize to be set",
+		},
+		{
+			name:          "keep-label without rules",
+			args:          []string{"--force", "--keep-label", "com.example.keep"},
+			expectedError: "keep and keepLabels require keepLast, keepUsedWithin, or maxSize to be set",
+		},
+		{
+			name:          "missing policy file",
+			args:          []string{"--policy", "no-such-file.json"},
+			expectedError: "failed to read retention policy",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := newPruneCommand(test.NewFakeCli(&fakeClient{}))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+}
+
+func TestPruneFnRetentionPolicy(t *testing.T) {
+	dir := fs.NewDir(t, "policy", fs.WithFile("policy.json", `{"keepLast": 1}`))
+	policyFile := dir.Join("policy.json")
+
+	now := time.Now()
+	var listFilters client.Filters
+	var removed []string
+	cli := test.NewFakeCli(&fakeClient{
+		imageListFunc: func(options client.ImageListOptions) (client.ImageListResult, error) {
+			listFilters = options.Filters
+			return client.ImageListResult{Items: retentionTestImages(now)}, nil
+		},
+		imageRemoveFunc: func(img string, _ client.ImageRemoveOptions) (client.ImageRemoveResult, error) {
+			removed = append(removed, img)
+			return client.ImageRemoveResult{Items: []image.DeleteResponse{{Untagged: img}}}, nil
+		},
+	})
+	filter := opts.NewFilterOpt()
+	assert.NilError(t, filter.Set("label=com.example.app"))
+
+	// The confirmation message lists the image references to remove.
+	_, msg, err := pruneFn(context.Background(), cli, pruner.PruneOptions{ImagePolicy: policyFile, Filter: filter})
+	assert.Check(t, errdefs.IsCanceled(err))
+	assert.Check(t, is.Contains(msg, "images not retained by the retention policy in "+policyFile+":"))
+	assert.Check(t, is.Contains(msg, "- example.com/app:1 ("))
+	assert.Check(t, !is.Contains(msg, "example.com/app:latest")().Success())
+	assert.Check(t, is.Contains(msg, "Estimated reclaimable space:"))
+	assert.Check(t, is.DeepEqual(listFilters, filter.Value()))
+	assert.Check(t, is.Len(removed, 0))
+
+	// The filter is used when pruning as well.
+	listFilters = nil
+	_, _, err = pruneFn(context.Background(), cli, pruner.PruneOptions{ImagePolicy: policyFile, Filter: filter, Confirmed: true})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(listFilters, filter.Value()))
+	assert.Check(t, is.Contains(removed, "example.com/app:1"))
+
+	_, _, err = pruneFn(context.Background(), cli, pruner.PruneOptions{ImagePolicy: policyFile, All: true})
+	assert.Check(t, is.ErrorContains(err, "--all cannot be used with --image-policy"))
+
+	_, _, err = pruneFn(context.Background(), cli, pruner.PruneOptions{ImagePolicy: dir.Join("missing.json")})
+	assert.Check(t, is.ErrorContains(err, "failed to read retention policy"))
+}
@@ -19,6 +19,7 @@
 type saveOptions struct {
 	images   []string
 	output   string
+	format   string
 	platform []string
 }
 
@@ -44,9 +45,11 @@ func newSaveCommand(dockerCLI command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 
 	flags.StringVarP(&opts.output, "output", "o", "", "Write to a file, instead of STDOUT")
+	flags.StringVar(&opts.format, "format", formatTar, `Output format ("`+formatTar+`" or "`+formatOCIDir+`")`)
 	flags.StringSliceVar(&opts.platform, "platform", []string{}, `Save only the given platform(s). Formatted as a comma-separated list of "os[/arch[/variant]]" (e.g., "linux/amd64,linux/arm64/v8")`)
 	_ = flags.SetAnnotation("platform", "version", []string{"1.48"})
 
+	_ = cmd.RegisterFlagCompletionFunc("format", completion.FromList(formatTar, formatOCIDir))
 	_ = cmd.RegisterFlagCompletionFunc("platform", completion.Platforms())
 	return cmd
 }
@@ -67,6 +70,14 @@ func runSave(ctx context.Context, dockerCLI command.Cli, opts saveOptions) error
 		options = append(options, client.ImageSaveWithPlatforms(platformList...))
 	}
 
+	switch opts.format {
+	case "", formatTar:
+	case formatOCIDir:
+		return saveOCILayout(ctx, dockerCLI, opts, options)
+	default:
+		return fmt.Errorf(`invalid format %q: must be "%s" or "%s"`, opts.format, formatTar, formatOCIDir)
+	}
+
 	var output io.Writer
 	if opts.output == "" {
 		if dockerCLI.Out().IsTerminal() {
@@ -91,3 +102,21 @@ func runSave(ctx context.Context, dockerCLI command.Cli, opts saveOptions) error
 	_, err = io.Copy(output, responseBody)
 	return err
 }
+
+// saveOCILayout saves the images to an OCI image layout directory.
+func saveOCILayout(ctx context.Context, dockerCLI command.Cli, opts saveOptions, options []client.ImageSaveOption) error {
+	if opts.output == "" {
+		return errors.New("an output directory must be specified with --output when using --format=" + formatOCIDir)
+	}
+
+	responseBody, err := dockerCLI.Client().ImageSave(ctx, opts.images, options...)
+	if err != nil {
+		return err
+	}
+	defer responseBody.Close()
+
+	if err := writeOCILayout(opts.output, responseBody); err != nil {
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+	return nil
+}
@@ -56,6 +56,16 @@ func TestNewSaveCommandErrors(t *testing.T) {
 			args:          []string{"--platform", "<invalid>", "arg1"},
 			expectedError: `invalid platform`,
 		},
+		{
+			name:          "invalid format",
+			args:          []string{"--format", "zip", "arg1"},
+			expectedError: `invalid format "zip"`,
+		},
+		{
+			name:          "oci-dir without output",
+			args:          []string{"--format", "oci-dir", "arg1"},
+			expectedError: `an output directory must be specified with --output`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -26,6 +26,12 @@ type treeOptions struct {
 	images   []imagetypes.Summary
 	filters  client.Filters
 	expanded bool
+
+	// sort and reverse are the "--sort" and "--reverse" options. The images
+	// are already sorted by them, except for the names of the images, as
+	// each tag of an image is listed separately.
+	sort    string
+	reverse bool
 }
 
 type treeView struct {
@@ -127,7 +133,19 @@ func runTree(ctx context.Context, dockerCLI command.Cli, opts treeOptions) (int,
 		}
 	}
 
-	slices.SortFunc(view.images, func(a, b topImage) int {
+	sortTopImages(view.images, opts.sort, opts.reverse)
+
+	printImageTree(dockerCLI, view)
+	return len(view.images), nil
+}
+
+// sortTopImages sorts the images by name, with untagged images last. Images
+// that are sorted by another key than "name" keep their order.
+func sortTopImages(images []topImage, sortKey string, reverse bool) {
+	if sortKey != "" && sortKey != formatter.SortKeyName {
+		return
+	}
+	slices.SortStableFunc(images, func(a, b topImage) int {
 		nameA := ""
 		if len(a.Names) > 0 {
 			nameA = a.Names[0]
@@ -136,18 +154,22 @@ func runTree(ctx context.Context, dockerCLI command.Cli, opts treeOptions) (int,
 		if len(b.Names) > 0 {
 			nameB = b.Names[0]
 		}
+		var n int
 		// Empty names sort last
 		if (nameA == "") != (nameB == "") {
 			if nameB == "" {
-				return -1
+				n = -1
+			} else {
+				n = 1
 			}
-			return 1
+		} else {
+			n = strings.Compare(nameA, nameB)
+		}
+		if reverse {
+			n = -n
 		}
-		return strings.Compare(nameA, nameB)
+		return n
 	})
-
-	printImageTree(dockerCLI, view)
-	return len(view.images), nil
 }
 
 type imageDetails struct {
@@ -7,6 +7,7 @@
 
 	"github.com/docker/cli/internal/test"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/golden"
 )
 
@@ -353,3 +354,44 @@ func TestPrintImageTreeGolden(t *testing.T) {
 		})
 	}
 }
+
+func TestSortTopImages(t *testing.T) {
+	newImages := func() []topImage {
+		return []topImage{
+			{Names: []string{"b:latest"}, created: 3},
+			{Names: []string{}, created: 2},
+			{Names: []string{"a:latest"}, created: 1},
+		}
+	}
+	names := func(images []topImage) []string {
+		var out []string
+		for _, img := range images {
+			name := untaggedName
+			if len(img.Names) > 0 {
+				name = img.Names[0]
+			}
+			out = append(out, name)
+		}
+		return out
+	}
+
+	testCases := []struct {
+		sort     string
+		reverse  bool
+		expected []string
+	}{
+		{expected: []string{"a:latest", "b:latest", untaggedName}},
+		{sort: "name", expected: []string{"a:latest", "b:latest", untaggedName}},
+		{sort: "name", reverse: true, expected: []string{untaggedName, "b:latest", "a:latest"}},
+		// images sorted by size or created are already sorted by the list options.
+		{sort: "created", expected: []string{"b:latest", untaggedName, "a:latest"}},
+		{sort: "size", reverse: true, expected: []string{"b:latest", untaggedName, "a:latest"}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s-reverse=%t", tc.sort, tc.reverse), func(t *testing.T) {
+			images := newImages()
+			sortTopImages(images, tc.sort, tc.reverse)
+			assert.Check(t, is.DeepEqual(names(images), tc.expected))
+		})
+	}
+}
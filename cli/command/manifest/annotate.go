@@ -4,8 +4,9 @@
 package manifest
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"slices"
 
@@ -14,27 +15,26 @@
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/manifest/store"
-	"github.com/docker/cli/internal/registryclient"
-	"github.com/moby/moby/api/types/registry"
+	cliopts "github.com/docker/cli/opts"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 )
 
 type annotateOptions struct {
-	target     string // the target manifest list name (also transaction ID)
-	image      string // the manifest to annotate within the list
-	variant    string // an architecture variant
-	os         string
-	arch       string
-	osFeatures []string
-	osVersion  string
+	target      string // the target manifest list name (also transaction ID)
+	image       string // the manifest to annotate within the list
+	variant     string // an architecture variant
+	os          string
+	arch        string
+	osFeatures  []string
+	osVersion   string
+	annotations *cliopts.MapOpts
 }
 
 // manifestStoreProvider is used in tests to provide a dummy store.
 type manifestStoreProvider interface {
 	// ManifestStore returns a store for local manifests
 	ManifestStore() store.Store
-	RegistryClient(bool) registryclient.RegistryClient
 }
 
 // newManifestStore returns a store for local manifests
@@ -48,63 +48,21 @@ func newManifestStore(dockerCLI command.Cli) store.Store {
 	return store.NewStore(filepath.Join(config.Dir(), "manifests"))
 }
 
-// authConfigKey is the key used to store credentials for Docker Hub. It is
-// a copy of [registry.IndexServer].
-//
-// [registry.IndexServer]: https://pkg.go.dev/github.com/docker/docker@v28.3.3+incompatible/registry#IndexServer
-const authConfigKey = "https://index.docker.io/v1/"
-
-// getAuthConfigKey special-cases using the full index address of the official
-// index as the AuthConfig key, and uses the (host)name[:port] for private indexes.
-//
-// It is similar to [registry.GetAuthConfigKey], but does not require on
-// [registrytypes.IndexInfo] as intermediate.
-//
-// [registry.GetAuthConfigKey]: https://pkg.go.dev/github.com/docker/docker@v28.3.3+incompatible/registry#GetAuthConfigKey
-// [registrytypes.IndexInfo]: https://pkg.go.dev/github.com/docker/docker@v28.3.3+incompatible/api/types/registry#IndexInfo
-func getAuthConfigKey(domainName string) string {
-	if domainName == "docker.io" || domainName == "index.docker.io" {
-		return authConfigKey
-	}
-	return domainName
-}
-
-// newRegistryClient returns a client for communicating with a Docker distribution
-// registry
-func newRegistryClient(dockerCLI command.Cli, allowInsecure bool) registryclient.RegistryClient {
-	if msp, ok := dockerCLI.(manifestStoreProvider); ok {
-		// manifestStoreProvider is used in tests to provide a dummy store.
-		return msp.RegistryClient(allowInsecure)
-	}
-	cfg := dockerCLI.ConfigFile()
-	resolver := func(ctx context.Context, domainName string) registry.AuthConfig {
-		configKey := getAuthConfigKey(domainName)
-		a, _ := cfg.GetAuthConfig(configKey)
-		return registry.AuthConfig{
-			Username:      a.Username,
-			Password:      a.Password,
-			ServerAddress: a.ServerAddress,
-
-			// TODO(thaJeztah): Are these expected to be included?
-			Auth:          a.Auth,
-			IdentityToken: a.IdentityToken,
-			RegistryToken: a.RegistryToken,
-		}
-	}
-	// FIXME(thaJeztah): this should use the userAgent as configured on the dockerCLI.
-	return registryclient.NewRegistryClient(resolver, command.UserAgent(), allowInsecure)
-}
-
 // NewAnnotateCommand creates a new `docker manifest annotate` command
 func newAnnotateCommand(dockerCLI command.Cli) *cobra.Command {
-	var opts annotateOptions
+	opts := annotateOptions{
+		annotations: cliopts.NewMapOpts(nil, nil),
+	}
 
 	cmd := &cobra.Command{
-		Use:   "annotate [OPTIONS] MANIFEST_LIST MANIFEST",
+		Use:   "annotate [OPTIONS] MANIFEST_LIST [MANIFEST]",
 		Short: "Add additional information to a local image manifest",
-		Args:  cli.ExactArgs(2),
+		Args:  cli.RequiresRangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.target = args[0]
+			if len(args) == 1 {
+				return runIndexAnnotate(dockerCLI, opts)
+			}
 			opts.image = args[1]
 			return runManifestAnnotate(dockerCLI, opts)
 		},
@@ -118,6 +76,7 @@ func newAnnotateCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.StringVar(&opts.osVersion, "os-version", "", "Set operating system version")
 	flags.StringSliceVar(&opts.osFeatures, "os-features", []string{}, "Set operating system feature")
 	flags.StringVar(&opts.variant, "variant", "", "Set architecture variant")
+	flags.Var(opts.annotations, "annotation", "Add an annotation to the manifest, or to the image index if no manifest is given")
 
 	return cmd
 }
@@ -141,6 +100,20 @@ func runManifestAnnotate(dockerCLI command.Cli, opts annotateOptions) error {
 		return err
 	}
 
+	if annotations := opts.annotations.GetAll(); len(annotations) > 0 {
+		index, err := manifestStore.GetIndex(targetRef)
+		if err != nil {
+			return err
+		}
+		if !index.IsOCI() {
+			return errors.New("annotations are only supported by an OCI image index; use \"docker manifest create --amend --oci\" to convert the manifest list")
+		}
+		if imageManifest.Descriptor.Annotations == nil {
+			imageManifest.Descriptor.Annotations = make(map[string]string, len(annotations))
+		}
+		maps.Copy(imageManifest.Descriptor.Annotations, annotations)
+	}
+
 	// Update the mf
 	if imageManifest.Descriptor.Platform == nil {
 		imageManifest.Descriptor.Platform = new(ocispec.Platform)
@@ -161,12 +134,46 @@ func runManifestAnnotate(dockerCLI command.Cli, opts annotateOptions) error {
 		imageManifest.Descriptor.Platform.OSVersion = opts.osVersion
 	}
 
-	if !isValidOSArch(imageManifest.Descriptor.Platform.OS, imageManifest.Descriptor.Platform.Architecture) {
+	// Attestation manifests use "unknown/unknown" as platform.
+	if !imageManifest.IsAttestation() && !isValidOSArch(imageManifest.Descriptor.Platform.OS, imageManifest.Descriptor.Platform.Architecture) {
 		return fmt.Errorf("manifest entry for image has unsupported os/arch combination: %s/%s", opts.os, opts.arch)
 	}
 	return manifestStore.Save(targetRef, imgRef, imageManifest)
 }
 
+// runIndexAnnotate adds annotations to the image index of a local manifest
+// list.
+func runIndexAnnotate(dockerCLI command.Cli, opts annotateOptions) error {
+	targetRef, err := normalizeReference(opts.target)
+	if err != nil {
+		return fmt.Errorf("annotate: error parsing name for manifest list %s: %w", opts.target, err)
+	}
+	if opts.os != "" || opts.arch != "" || opts.osVersion != "" || len(opts.osFeatures) > 0 || opts.variant != "" {
+		return errors.New("platform options can only be used when annotating a manifest")
+	}
+	annotations := opts.annotations.GetAll()
+	if len(annotations) == 0 {
+		return errors.New("no annotations specified for image index")
+	}
+
+	manifestStore := newManifestStore(dockerCLI)
+	if _, err := manifestStore.GetList(targetRef); err != nil {
+		return err
+	}
+	index, err := manifestStore.GetIndex(targetRef)
+	if err != nil {
+		return err
+	}
+	if !index.IsOCI() {
+		return errors.New("annotations are only supported by an OCI image index; use \"docker manifest create --amend --oci\" to convert the manifest list")
+	}
+	if index.Annotations == nil {
+		index.Annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(index.Annotations, annotations)
+	return manifestStore.SaveIndex(targetRef, index)
+}
+
 func appendIfUnique(list []string, str string) []string {
 	if slices.Contains(list, str) {
 		return list
@@ -5,7 +5,9 @@
 	"testing"
 
 	"github.com/docker/cli/cli/manifest/store"
+	"github.com/docker/cli/cli/manifest/types"
 	"github.com/docker/cli/internal/test"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/golden"
@@ -17,8 +19,20 @@ func TestManifestAnnotateError(t *testing.T) {
 		expectedError string
 	}{
 		{
-			args:          []string{"too-few-arguments"},
-			expectedError: "requires 2 arguments",
+			args:          []string{},
+			expectedError: "requires at least 1 and at most 2 arguments",
+		},
+		{
+			args:          []string{"example.com/list:v1", "example.com/alpine:3.0", "too-many-arguments"},
+			expectedError: "requires at least 1 and at most 2 arguments",
+		},
+		{
+			args:          []string{"example.com/list:v1", "--os", "linux"},
+			expectedError: "platform options can only be used when annotating a manifest",
+		},
+		{
+			args:          []string{"example.com/list:v1"},
+			expectedError: "no annotations specified for image index",
 		},
 		{
 			args:          []string{"th!si'sa/fa!ke/li$t/name", "example.com/alpine:3.0"},
@@ -79,3 +93,35 @@ func TestManifestAnnotate(t *testing.T) {
 	expected := golden.Get(t, "inspect-annotate.golden")
 	assert.Check(t, is.Equal(string(expected), actual.String()))
 }
+
+func TestManifestAnnotateAnnotations(t *testing.T) {
+	manifestStore := store.NewStore(t.TempDir())
+
+	cli := test.NewFakeCli(nil)
+	cli.SetManifestStore(manifestStore)
+	namedRef := ref(t, "alpine:3.0")
+	listRef := ref(t, "list:v1")
+	assert.NilError(t, manifestStore.Save(listRef, namedRef, fullImageManifest(t, namedRef)))
+
+	cmd := newAnnotateCommand(cli)
+	cmd.SetArgs([]string{"--annotation", "org.opencontainers.image.title=alpine", "example.com/list:v1", "example.com/alpine:3.0"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "annotations are only supported by an OCI image index")
+
+	assert.NilError(t, manifestStore.SaveIndex(listRef, types.ImageIndex{MediaType: ocispec.MediaTypeImageIndex}))
+	assert.NilError(t, cmd.Execute())
+
+	cmd = newAnnotateCommand(cli)
+	cmd.SetArgs([]string{"--annotation", "org.opencontainers.image.version=1.0", "example.com/list:v1"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	imageManifest, err := manifestStore.Get(listRef, namedRef)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(imageManifest.Descriptor.Annotations, map[string]string{"org.opencontainers.image.title": "alpine"}))
+	index, err := manifestStore.GetIndex(listRef)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(index.Annotations, map[string]string{"org.opencontainers.image.version": "1.0"}))
+}
@@ -2,12 +2,15 @@
 
 import (
 	"context"
+	"io"
+	"strings"
 
 	"github.com/distribution/reference"
 	manifesttypes "github.com/docker/cli/cli/manifest/types"
 	"github.com/docker/cli/internal/registryclient"
 	"github.com/docker/distribution"
 	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type fakeRegistryClient struct {
@@ -15,6 +18,16 @@ type fakeRegistryClient struct {
 	getManifestListFunc func(ctx context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error)
 	mountBlobFunc       func(ctx context.Context, source reference.Canonical, target reference.Named) error
 	putManifestFunc     func(ctx context.Context, source reference.Named, mf distribution.Manifest) (digest.Digest, error)
+	getRawManifestFunc  func(ctx context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error)
+	getBlobFunc         func(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]byte, error)
+	getTagsFunc         func(ctx context.Context, ref reference.Named) ([]string, error)
+	getCatalogFunc      func(ctx context.Context, hostname string) ([]string, error)
+	blobExistsFunc      func(ctx context.Context, ref reference.Named, dgst digest.Digest) (bool, error)
+	openBlobFunc        func(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error)
+	putBlobFunc         func(ctx context.Context, ref reference.Named, desc ocispec.Descriptor, content io.Reader) error
+	putRawManifestFunc  func(ctx context.Context, ref reference.Named, mediaType string, raw []byte) (digest.Digest, error)
+	getReferrersFunc    func(ctx context.Context, ref reference.Canonical, artifactType string) ([]ocispec.Descriptor, error)
+	deleteManifestFunc  func(ctx context.Context, ref reference.Canonical) error
 }
 
 func (c *fakeRegistryClient) GetManifest(ctx context.Context, ref reference.Named) (manifesttypes.ImageManifest, error) {
@@ -45,4 +58,78 @@ func (c *fakeRegistryClient) PutManifest(ctx context.Context, ref reference.Name
 	return digest.Digest(""), nil
 }
 
+func (c *fakeRegistryClient) GetRawManifest(ctx context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+	if c.getRawManifestFunc != nil {
+		return c.getRawManifestFunc(ctx, ref)
+	}
+	return ocispec.Descriptor{}, nil, nil
+}
+
+func (c *fakeRegistryClient) GetUpstreamManifest(ctx context.Context, ref reference.Named, _ ...string) (ocispec.Descriptor, []byte, error) {
+	return c.GetRawManifest(ctx, ref)
+}
+
+func (c *fakeRegistryClient) GetBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]byte, error) {
+	if c.getBlobFunc != nil {
+		return c.getBlobFunc(ctx, ref, dgst)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) GetTags(ctx context.Context, ref reference.Named) ([]string, error) {
+	if c.getTagsFunc != nil {
+		return c.getTagsFunc(ctx, ref)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) GetCatalog(ctx context.Context, hostname string) ([]string, error) {
+	if c.getCatalogFunc != nil {
+		return c.getCatalogFunc(ctx, hostname)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) BlobExists(ctx context.Context, ref reference.Named, dgst digest.Digest) (bool, error) {
+	if c.blobExistsFunc != nil {
+		return c.blobExistsFunc(ctx, ref, dgst)
+	}
+	return false, nil
+}
+
+func (c *fakeRegistryClient) OpenBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error) {
+	if c.openBlobFunc != nil {
+		return c.openBlobFunc(ctx, ref, dgst)
+	}
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (c *fakeRegistryClient) PutBlob(ctx context.Context, ref reference.Named, desc ocispec.Descriptor, content io.Reader) error {
+	if c.putBlobFunc != nil {
+		return c.putBlobFunc(ctx, ref, desc, content)
+	}
+	return nil
+}
+
+func (c *fakeRegistryClient) PutRawManifest(ctx context.Context, ref reference.Named, mediaType string, raw []byte) (digest.Digest, error) {
+	if c.putRawManifestFunc != nil {
+		return c.putRawManifestFunc(ctx, ref, mediaType, raw)
+	}
+	return digest.FromBytes(raw), nil
+}
+
+func (c *fakeRegistryClient) GetReferrers(ctx context.Context, ref reference.Canonical, artifactType string) ([]ocispec.Descriptor, error) {
+	if c.getReferrersFunc != nil {
+		return c.getReferrersFunc(ctx, ref, artifactType)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) DeleteManifest(ctx context.Context, ref reference.Canonical) error {
+	if c.deleteManifestFunc != nil {
+		return c.deleteManifestFunc(ctx, ref)
+	}
+	return nil
+}
+
 var _ registryclient.RegistryClient = &fakeRegistryClient{}
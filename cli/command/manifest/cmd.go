@@ -34,6 +34,7 @@ func newManifestCommand(dockerCLI command.Cli) *cobra.Command {
 		newAnnotateCommand(dockerCLI),
 		newPushListCommand(dockerCLI),
 		newRmManifestListCommand(dockerCLI),
+		newReferrersCommand(dockerCLI),
 	)
 	return cmd
 }
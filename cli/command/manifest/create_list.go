@@ -4,26 +4,37 @@
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 
 	"github.com/containerd/errdefs"
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	cliopts "github.com/docker/cli/opts"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 )
 
 type createOpts struct {
-	amend    bool
-	insecure bool
+	amend        bool
+	insecure     bool
+	oci          bool
+	ociChanged   bool
+	annotations  *cliopts.MapOpts
+	artifactType string
+	subject      string
 }
 
 func newCreateListCommand(dockerCLI command.Cli) *cobra.Command {
-	opts := createOpts{}
+	opts := createOpts{
+		annotations: cliopts.NewMapOpts(nil, nil),
+	}
 
 	cmd := &cobra.Command{
 		Use:   "create MANIFEST_LIST MANIFEST [MANIFEST...]",
 		Short: "Create a local manifest list for annotating and pushing to a registry",
 		Args:  cli.RequiresMinArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ociChanged = cmd.Flags().Changed("oci")
 			return createManifestList(cmd.Context(), dockerCLI, args, opts)
 		},
 		DisableFlagsInUseLine: true,
@@ -32,6 +43,10 @@ func newCreateListCommand(dockerCLI command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry")
 	flags.BoolVarP(&opts.amend, "amend", "a", false, "Amend an existing manifest list")
+	flags.BoolVar(&opts.oci, "oci", false, "Create an OCI image index instead of a Docker manifest list")
+	flags.Var(opts.annotations, "annotation", "Add an annotation to the image index")
+	flags.StringVar(&opts.artifactType, "artifact-type", "", "Set the artifact type of the image index")
+	flags.StringVar(&opts.subject, "subject", "", "Set the subject of the image index to the given manifest")
 	return cmd
 }
 
@@ -53,6 +68,35 @@ func createManifestList(ctx context.Context, dockerCLI command.Cli, args []strin
 		return errors.New("refusing to amend an existing manifest list with no --amend flag")
 	}
 
+	index, err := manifestStore.GetIndex(targetRef)
+	if err != nil {
+		return err
+	}
+	if opts.ociChanged {
+		index.MediaType = ""
+		if opts.oci {
+			index.MediaType = ocispec.MediaTypeImageIndex
+		}
+	}
+	if annotations := opts.annotations.GetAll(); len(annotations) > 0 {
+		if index.Annotations == nil {
+			index.Annotations = make(map[string]string, len(annotations))
+		}
+		maps.Copy(index.Annotations, annotations)
+	}
+	if opts.artifactType != "" {
+		index.ArtifactType = opts.artifactType
+	}
+	if opts.subject != "" {
+		index.Subject, err = getSubject(ctx, dockerCLI, opts.subject, opts.insecure)
+		if err != nil {
+			return err
+		}
+	}
+	if !index.IsOCI() && (index.ArtifactType != "" || index.Subject != nil || len(index.Annotations) > 0) {
+		return errors.New("artifact type, subject, and annotations are only supported by an OCI image index (--oci)")
+	}
+
 	// Now create the local manifest list transaction by looking up the manifest schemas
 	// for the constituent images:
 	manifests := args[1:]
@@ -63,14 +107,48 @@ func createManifestList(ctx context.Context, dockerCLI command.Cli, args []strin
 			return err
 		}
 
-		manifest, err := getManifest(ctx, dockerCLI, targetRef, namedRef, opts.insecure)
+		imageManifests, isList, err := getManifests(ctx, dockerCLI, targetRef, namedRef, opts.insecure)
 		if err != nil {
 			return err
 		}
-		if err := manifestStore.Save(targetRef, namedRef, manifest); err != nil {
-			return err
+		for _, manifest := range imageManifests {
+			if manifest.IsAttestation() && !index.IsOCI() {
+				// Attestations are identified by their annotations, which a
+				// Docker manifest list does not support.
+				_, _ = fmt.Fprintf(dockerCLI.Err(), "Skipping attestation manifest %s: attestations are only supported by an OCI image index (--oci)\n", manifest.Descriptor.Digest)
+				continue
+			}
+			ref := namedRef
+			if isList {
+				ref = manifest.Ref.Named
+			}
+			if err := manifestStore.Save(targetRef, ref, manifest); err != nil {
+				return err
+			}
 		}
 	}
+	if err := manifestStore.SaveIndex(targetRef, index); err != nil {
+		return err
+	}
 	_, _ = fmt.Fprintln(dockerCLI.Out(), "Created manifest list", targetRef.String())
 	return nil
 }
+
+// getSubject resolves the descriptor of the manifest that is set as the
+// subject of an image index.
+func getSubject(ctx context.Context, dockerCLI command.Cli, subject string, insecure bool) (*ocispec.Descriptor, error) {
+	namedRef, err := normalizeReference(subject)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing name for subject %s: %w", subject, err)
+	}
+	desc, _, err := command.NewRegistryClient(dockerCLI, insecure).GetRawManifest(ctx, namedRef)
+	if err != nil {
+		return nil, err
+	}
+	return &ocispec.Descriptor{
+		MediaType:    desc.MediaType,
+		Digest:       desc.Digest,
+		Size:         desc.Size,
+		ArtifactType: desc.ArtifactType,
+	}, nil
+}
@@ -10,6 +10,8 @@
 	"github.com/docker/cli/cli/manifest/store"
 	manifesttypes "github.com/docker/cli/cli/manifest/types"
 	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/golden"
@@ -117,3 +119,97 @@ func TestManifestCreateNoManifest(t *testing.T) {
 	err := cmd.Execute()
 	assert.Error(t, err, "No such image: example.com/alpine:3.0")
 }
+
+// create an OCI image index from a multi-platform image, including its
+// attestation manifests
+func TestManifestCreateOCIIndex(t *testing.T) {
+	newRegistry := func(t *testing.T) *fakeRegistryClient {
+		t.Helper()
+		return &fakeRegistryClient{
+			getManifestFunc: func(_ context.Context, ref reference.Named) (manifesttypes.ImageManifest, error) {
+				return manifesttypes.ImageManifest{}, errors.New(ref.String() + " is a manifest list")
+			},
+			getManifestListFunc: func(_ context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error) {
+				img := fullImageManifest(t, ref)
+				imgRef, err := reference.WithDigest(ref, img.Descriptor.Digest)
+				assert.NilError(t, err)
+				img.Ref.Named = imgRef
+
+				attestation := fullImageManifest(t, ref)
+				attestation.Descriptor.Digest = digest.FromString("attestation")
+				attestationRef, err := reference.WithDigest(ref, attestation.Descriptor.Digest)
+				assert.NilError(t, err)
+				attestation.Ref.Named = attestationRef
+				attestation.Descriptor.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+				attestation.Descriptor.Annotations = map[string]string{
+					manifesttypes.AnnotationReferenceType:   manifesttypes.AttestationManifestType,
+					manifesttypes.AnnotationReferenceDigest: img.Descriptor.Digest.String(),
+				}
+				return []manifesttypes.ImageManifest{img, attestation}, nil
+			},
+			getRawManifestFunc: func(_ context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+				assert.Check(t, is.Equal(ref.String(), "example.com/subject:latest"))
+				return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("subject"), Size: 42}, nil, nil
+			},
+		}
+	}
+
+	t.Run("oci", func(t *testing.T) {
+		manifestStore := store.NewStore(t.TempDir())
+		cli := test.NewFakeCli(nil)
+		cli.SetManifestStore(manifestStore)
+		cli.SetRegistryClient(newRegistry(t))
+
+		cmd := newCreateListCommand(cli)
+		cmd.SetArgs([]string{
+			"--oci",
+			"--annotation", "org.opencontainers.image.version=1.0",
+			"--artifact-type", "application/vnd.example.bundle",
+			"--subject", "example.com/subject",
+			"example.com/list:v1", "example.com/alpine:3.0",
+		})
+		cmd.SetOut(io.Discard)
+		assert.NilError(t, cmd.Execute())
+
+		list, err := manifestStore.GetList(ref(t, "list:v1"))
+		assert.NilError(t, err)
+		assert.Check(t, is.Len(list, 2))
+		index, err := manifestStore.GetIndex(ref(t, "list:v1"))
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(index, manifesttypes.ImageIndex{
+			MediaType:    ocispec.MediaTypeImageIndex,
+			ArtifactType: "application/vnd.example.bundle",
+			Subject:      &ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("subject"), Size: 42},
+			Annotations:  map[string]string{"org.opencontainers.image.version": "1.0"},
+		}))
+	})
+
+	t.Run("docker", func(t *testing.T) {
+		manifestStore := store.NewStore(t.TempDir())
+		cli := test.NewFakeCli(nil)
+		cli.SetManifestStore(manifestStore)
+		cli.SetRegistryClient(newRegistry(t))
+
+		cmd := newCreateListCommand(cli)
+		cmd.SetArgs([]string{"example.com/list:v1", "example.com/alpine:3.0"})
+		cmd.SetOut(io.Discard)
+		assert.NilError(t, cmd.Execute())
+		assert.Check(t, is.Contains(cli.ErrBuffer().String(), "Skipping attestation manifest "+digest.FromString("attestation").String()))
+
+		list, err := manifestStore.GetList(ref(t, "list:v1"))
+		assert.NilError(t, err)
+		assert.Check(t, is.Len(list, 1))
+	})
+
+	t.Run("annotations without oci", func(t *testing.T) {
+		cli := test.NewFakeCli(nil)
+		cli.SetManifestStore(store.NewStore(t.TempDir()))
+		cli.SetRegistryClient(newRegistry(t))
+
+		cmd := newCreateListCommand(cli)
+		cmd.SetArgs([]string{"--annotation", "foo=bar", "example.com/list:v1", "example.com/alpine:3.0"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		assert.ErrorContains(t, cmd.Execute(), "only supported by an OCI image index (--oci)")
+	})
+}
@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/go-units"
+)
+
+const (
+	defaultReferrersTableFormat = "table {{.Digest}}\t{{.ArtifactType}}\t{{.Size}}\t{{.Annotations}}"
+
+	digestHeader       = "DIGEST"
+	artifactTypeHeader = "ARTIFACT TYPE"
+	mediaTypeHeader    = "MEDIA TYPE"
+	annotationsHeader  = "ANNOTATIONS"
+)
+
+// newReferrersFormat returns a Format for rendering using a referrerContext.
+func newReferrersFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultReferrersTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// referrersFormatWrite writes the referrers of a manifest using the context.
+func referrersFormatWrite(fmtCtx formatter.Context, referrers []referrer) error {
+	referrerCtx := &referrerContext{
+		HeaderContext: formatter.HeaderContext{
+			Header: formatter.SubHeaderContext{
+				"Digest":       digestHeader,
+				"MediaType":    mediaTypeHeader,
+				"ArtifactType": artifactTypeHeader,
+				"Size":         formatter.SizeHeader,
+				"Annotations":  annotationsHeader,
+			},
+		},
+	}
+	return fmtCtx.Write(referrerCtx, func(format func(subContext formatter.SubContext) error) error {
+		for _, r := range referrers {
+			if err := format(&referrerContext{r: r}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type referrerContext struct {
+	formatter.HeaderContext
+	r referrer
+}
+
+func (c *referrerContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *referrerContext) Digest() string {
+	return c.r.Digest.String()
+}
+
+func (c *referrerContext) MediaType() string {
+	return c.r.MediaType
+}
+
+func (c *referrerContext) ArtifactType() string {
+	return c.r.ArtifactType
+}
+
+// Size returns the total size of the referrer, including its config and
+// layers.
+func (c *referrerContext) Size() string {
+	return units.HumanSizeWithPrecision(float64(c.r.size()), 3)
+}
+
+// Annotations returns a comma-separated string of the annotations of the
+// referrer, as set in the descriptor.
+func (c *referrerContext) Annotations() string {
+	annotations := make([]string, 0, len(c.r.Annotations))
+	for k, v := range c.r.Annotations {
+		annotations = append(annotations, k+"="+v)
+	}
+	sort.Strings(annotations)
+	return strings.Join(annotations, ",")
+}
+
+// Annotation returns the value of the annotation with the given name or an
+// empty string if the given annotation does not exist.
+func (c *referrerContext) Annotation(name string) string {
+	return c.r.Annotations[name]
+}
@@ -10,7 +10,8 @@
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/manifest/types"
-	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/cli/internal/registryclient"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -71,11 +72,15 @@ func runInspect(ctx context.Context, dockerCli command.Cli, opts inspectOptions)
 	// Try a local manifest list first
 	localManifestList, err := newManifestStore(dockerCli).GetList(namedRef)
 	if err == nil {
-		return printManifestList(dockerCli, namedRef, localManifestList, opts)
+		index, err := newManifestStore(dockerCli).GetIndex(namedRef)
+		if err != nil {
+			return err
+		}
+		return printManifestList(dockerCli, namedRef, localManifestList, index, opts)
 	}
 
 	// Next try a remote manifest
-	registryClient := newRegistryClient(dockerCli, opts.insecure)
+	registryClient := command.NewRegistryClient(dockerCli, opts.insecure)
 	imageManifest, err := registryClient.GetManifest(ctx, namedRef)
 	if err == nil {
 		return printManifest(dockerCli, imageManifest, opts)
@@ -86,7 +91,34 @@ func runInspect(ctx context.Context, dockerCli command.Cli, opts inspectOptions)
 	if err != nil {
 		return err
 	}
-	return printManifestList(dockerCli, namedRef, manifestList, opts)
+	index, err := getRemoteIndex(ctx, registryClient, namedRef)
+	if err != nil {
+		return err
+	}
+	return printManifestList(dockerCli, namedRef, manifestList, index, opts)
+}
+
+// getRemoteIndex returns the properties of a remote manifest list that are
+// not part of the manifests it references, such as the annotations of an OCI
+// image index.
+func getRemoteIndex(ctx context.Context, registryClient registryclient.RegistryClient, namedRef reference.Named) (types.ImageIndex, error) {
+	desc, raw, err := registryClient.GetRawManifest(ctx, namedRef)
+	if err != nil {
+		return types.ImageIndex{}, err
+	}
+	if desc.MediaType != ocispec.MediaTypeImageIndex {
+		return types.ImageIndex{}, nil
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return types.ImageIndex{}, fmt.Errorf("failed to decode image index: %w", err)
+	}
+	return types.ImageIndex{
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: index.ArtifactType,
+		Subject:      index.Subject,
+		Annotations:  index.Annotations,
+	}, nil
 }
 
 func printManifest(dockerCli command.Cli, manifest types.ImageManifest, opts inspectOptions) error {
@@ -110,28 +142,14 @@ func printManifest(dockerCli command.Cli, manifest types.ImageManifest, opts ins
 	return nil
 }
 
-func printManifestList(dockerCli command.Cli, namedRef reference.Named, list []types.ImageManifest, opts inspectOptions) error {
+func printManifestList(dockerCli command.Cli, namedRef reference.Named, list []types.ImageManifest, index types.ImageIndex, opts inspectOptions) error {
 	if !opts.verbose {
-		targetRepo := reference.TrimNamed(namedRef)
-
-		manifests := make([]manifestlist.ManifestDescriptor, 0, len(list))
 		// More than one response. This is a manifest list.
-		for _, img := range list {
-			mfd, err := buildManifestDescriptor(targetRepo, img)
-			if err != nil {
-				return fmt.Errorf("failed to assemble ManifestDescriptor: %w", err)
-			}
-			manifests = append(manifests, mfd)
-		}
-		deserializedML, err := manifestlist.FromDescriptors(manifests)
+		_, raw, err := buildIndex(list, index, namedRef)
 		if err != nil {
-			return err
-		}
-		jsonBytes, err := deserializedML.MarshalJSON()
-		if err != nil {
-			return err
+			return fmt.Errorf("failed to assemble manifest list: %w", err)
 		}
-		_, _ = fmt.Fprintln(dockerCli.Out(), string(jsonBytes))
+		_, _ = fmt.Fprintln(dockerCli.Out(), string(raw))
 		return nil
 	}
 	jsonBytes, err := json.MarshalIndent(list, "", "\t")
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"testing"
@@ -135,3 +136,46 @@ func TestInspectcommandRemoteManifest(t *testing.T) {
 	expected := golden.Get(t, "inspect-manifest.golden")
 	assert.Check(t, is.Equal(string(expected), actual.String()))
 }
+
+func TestInspectCommandRemoteIndex(t *testing.T) {
+	refStore := store.NewStore(t.TempDir())
+	subject := &ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    "sha256:7328f6f8b41890597575cbaadc884e7386ae0acc53b747401ebce5cf0d624560",
+		Size:      1520,
+	}
+
+	cli := test.NewFakeCli(nil)
+	cli.SetManifestStore(refStore)
+	cli.SetRegistryClient(&fakeRegistryClient{
+		getManifestFunc: func(_ context.Context, _ reference.Named) (types.ImageManifest, error) {
+			return types.ImageManifest{}, errors.New("not a manifest")
+		},
+		getManifestListFunc: func(_ context.Context, ref reference.Named) ([]types.ImageManifest, error) {
+			return []types.ImageManifest{fullImageManifest(t, ref)}, nil
+		},
+		getRawManifestFunc: func(_ context.Context, _ reference.Named) (ocispec.Descriptor, []byte, error) {
+			raw, err := json.Marshal(ocispec.Index{
+				MediaType:    ocispec.MediaTypeImageIndex,
+				ArtifactType: "application/vnd.example+type",
+				Subject:      subject,
+				Annotations:  map[string]string{"org.opencontainers.image.title": "example"},
+			})
+			assert.NilError(t, err)
+			return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex}, raw, nil
+		},
+	})
+
+	cmd := newInspectCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"example.com/list:v1"})
+	assert.NilError(t, cmd.Execute())
+
+	var index ocispec.Index
+	assert.NilError(t, json.Unmarshal(cli.OutBuffer().Bytes(), &index))
+	assert.Check(t, is.Equal(index.MediaType, ocispec.MediaTypeImageIndex))
+	assert.Check(t, is.Equal(index.ArtifactType, "application/vnd.example+type"))
+	assert.Check(t, is.DeepEqual(index.Subject, subject))
+	assert.Check(t, is.DeepEqual(index.Annotations, map[string]string{"org.opencontainers.image.title": "example"}))
+	assert.Check(t, is.Len(index.Manifests, 1))
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -15,6 +16,8 @@
 	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/manifest/ocischema"
 	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -36,7 +39,8 @@ type manifestBlob struct {
 
 type pushRequest struct {
 	targetRef     reference.Named
-	list          *manifestlist.DeserializedManifestList
+	mediaType     string
+	index         []byte
 	mountRequests []mountRequest
 	manifestBlobs []manifestBlob
 	insecure      bool
@@ -76,7 +80,12 @@ func runPush(ctx context.Context, dockerCli command.Cli, opts pushOpts) error {
 		return fmt.Errorf("%s not found", targetRef)
 	}
 
-	req, err := buildPushRequest(manifests, targetRef, opts.insecure)
+	index, err := newManifestStore(dockerCli).GetIndex(targetRef)
+	if err != nil {
+		return err
+	}
+
+	req, err := buildPushRequest(manifests, index, targetRef, opts.insecure)
 	if err != nil {
 		return err
 	}
@@ -90,11 +99,14 @@ func runPush(ctx context.Context, dockerCli command.Cli, opts pushOpts) error {
 	return nil
 }
 
-func buildPushRequest(manifests []types.ImageManifest, targetRef reference.Named, insecure bool) (pushRequest, error) {
+func buildPushRequest(manifests []types.ImageManifest, index types.ImageIndex, targetRef reference.Named, insecure bool) (pushRequest, error) {
 	req := pushRequest{targetRef: targetRef, insecure: insecure}
 
+	if err := validateIndex(manifests, index); err != nil {
+		return req, err
+	}
 	var err error
-	req.list, err = buildManifestList(manifests, targetRef)
+	req.mediaType, req.index, err = buildIndex(manifests, index, targetRef)
 	if err != nil {
 		return req, err
 	}
@@ -121,23 +133,70 @@ func buildPushRequest(manifests []types.ImageManifest, targetRef reference.Named
 	return req, nil
 }
 
-func buildManifestList(manifests []types.ImageManifest, targetRef reference.Named) (*manifestlist.DeserializedManifestList, error) {
-	targetRepo := reference.TrimNamed(targetRef)
-	descriptors := make([]manifestlist.ManifestDescriptor, 0, len(manifests))
+// validateIndex verifies that the manifests in a local manifest list can be
+// pushed, and that a Docker manifest list only uses properties that are
+// supported by it.
+func validateIndex(manifests []types.ImageManifest, index types.ImageIndex) error {
+	if index.IsOCI() {
+		return nil
+	}
+	if index.ArtifactType != "" || index.Subject != nil || len(index.Annotations) > 0 {
+		return errors.New("artifact type, subject, and annotations are only supported by an OCI image index (--oci)")
+	}
 	for _, imageManifest := range manifests {
 		if imageManifest.Descriptor.Platform == nil ||
 			imageManifest.Descriptor.Platform.Architecture == "" ||
 			imageManifest.Descriptor.Platform.OS == "" {
-			return nil, fmt.Errorf("manifest %s must have an OS and Architecture to be pushed to a registry", imageManifest.Ref)
+			return fmt.Errorf("manifest %s must have an OS and Architecture to be pushed to a registry", imageManifest.Ref)
+		}
+		if len(imageManifest.Descriptor.Annotations) > 0 {
+			return fmt.Errorf("manifest %s has annotations, which are only supported by an OCI image index (--oci)", imageManifest.Ref)
 		}
-		descriptor, err := buildManifestDescriptor(targetRepo, imageManifest)
+	}
+	return nil
+}
+
+// buildIndex builds the Docker manifest list or OCI image index for the
+// manifests in a local manifest list, and returns its media type and
+// canonical content.
+func buildIndex(manifests []types.ImageManifest, index types.ImageIndex, targetRef reference.Named) (string, []byte, error) {
+	targetRepo := reference.TrimNamed(targetRef)
+	if !index.IsOCI() {
+		descriptors := make([]manifestlist.ManifestDescriptor, 0, len(manifests))
+		for _, imageManifest := range manifests {
+			descriptor, err := buildManifestDescriptor(targetRepo, imageManifest)
+			if err != nil {
+				return "", nil, err
+			}
+			descriptors = append(descriptors, descriptor)
+		}
+		list, err := manifestlist.FromDescriptors(descriptors)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
-		descriptors = append(descriptors, descriptor)
+		return list.Payload()
 	}
 
-	return manifestlist.FromDescriptors(descriptors)
+	descriptors := make([]ocispec.Descriptor, 0, len(manifests))
+	for _, imageManifest := range manifests {
+		descriptor, err := buildIndexDescriptor(targetRepo, imageManifest)
+		if err != nil {
+			return "", nil, err
+		}
+		descriptors = append(descriptors, descriptor)
+	}
+	raw, err := json.MarshalIndent(ocispec.Index{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: index.ArtifactType,
+		Manifests:    descriptors,
+		Subject:      index.Subject,
+		Annotations:  index.Annotations,
+	}, "", "   ")
+	if err != nil {
+		return "", nil, err
+	}
+	return ocispec.MediaTypeImageIndex, raw, nil
 }
 
 func buildManifestDescriptor(targetRepo reference.Named, imageManifest types.ImageManifest) (manifestlist.ManifestDescriptor, error) {
@@ -167,6 +226,36 @@ func buildManifestDescriptor(targetRepo reference.Named, imageManifest types.Ima
 	return manifest, nil
 }
 
+// buildIndexDescriptor builds the descriptor of a manifest in an OCI image
+// index. Unlike a Docker manifest list, the platform is optional, which allows
+// for artifacts that are not an image.
+func buildIndexDescriptor(targetRepo reference.Named, imageManifest types.ImageManifest) (ocispec.Descriptor, error) {
+	manifestRepoHostname := reference.Domain(reference.TrimNamed(imageManifest.Ref))
+	targetRepoHostname := reference.Domain(reference.TrimNamed(targetRepo))
+	if manifestRepoHostname != targetRepoHostname {
+		return ocispec.Descriptor{}, fmt.Errorf("cannot use source images from a different registry than the target image: %s != %s", manifestRepoHostname, targetRepoHostname)
+	}
+
+	descriptor := ocispec.Descriptor{
+		MediaType:    imageManifest.Descriptor.MediaType,
+		Digest:       imageManifest.Descriptor.Digest,
+		Size:         imageManifest.Descriptor.Size,
+		ArtifactType: imageManifest.Descriptor.ArtifactType,
+		Annotations:  imageManifest.Descriptor.Annotations,
+	}
+	if p := imageManifest.Descriptor.Platform; p != nil && (p.OS != "" || p.Architecture != "") {
+		if p.OS == "" || p.Architecture == "" {
+			return ocispec.Descriptor{}, fmt.Errorf("manifest %s must have both an OS and Architecture, or neither", imageManifest.Ref)
+		}
+		descriptor.Platform = p
+	}
+
+	if err := descriptor.Digest.Validate(); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("digest parse of image %q failed: %w", imageManifest.Ref, err)
+	}
+	return descriptor, nil
+}
+
 func buildBlobRequestList(imageManifest types.ImageManifest, repoName reference.Named) ([]manifestBlob, error) {
 	blobs := imageManifest.Blobs()
 	blobReqs := make([]manifestBlob, 0, len(blobs))
@@ -246,7 +335,7 @@ func buildPutManifestRequest(imageManifest types.ImageManifest, targetRef refere
 }
 
 func pushList(ctx context.Context, dockerCLI command.Cli, req pushRequest) error {
-	registryClient := newRegistryClient(dockerCLI, req.insecure)
+	registryClient := command.NewRegistryClient(dockerCLI, req.insecure)
 
 	if err := mountBlobs(ctx, registryClient, req.targetRef, req.manifestBlobs); err != nil {
 		return err
@@ -254,7 +343,7 @@ func pushList(ctx context.Context, dockerCLI command.Cli, req pushRequest) error
 	if err := pushReferences(ctx, dockerCLI.Out(), registryClient, req.mountRequests); err != nil {
 		return err
 	}
-	dgst, err := registryClient.PutManifest(ctx, req.targetRef, req.list)
+	dgst, err := registryClient.PutRawManifest(ctx, req.targetRef, req.mediaType, req.index)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"testing"
@@ -10,7 +11,10 @@
 	"github.com/docker/cli/cli/manifest/store"
 	manifesttypes "github.com/docker/cli/cli/manifest/types"
 	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 )
 
 func newFakeRegistryClient() *fakeRegistryClient {
@@ -68,3 +72,84 @@ func TestManifestPush(t *testing.T) {
 	err = cmd.Execute()
 	assert.NilError(t, err)
 }
+
+func TestManifestPushOCIIndex(t *testing.T) {
+	manifestStore := store.NewStore(t.TempDir())
+
+	var pushed []byte
+	registry := newFakeRegistryClient()
+	registry.putRawManifestFunc = func(_ context.Context, ref reference.Named, mediaType string, raw []byte) (digest.Digest, error) {
+		assert.Check(t, is.Equal(ref.String(), "example.com/list:v1"))
+		assert.Check(t, is.Equal(mediaType, ocispec.MediaTypeImageIndex))
+		pushed = raw
+		return digest.FromBytes(raw), nil
+	}
+
+	cli := test.NewFakeCli(nil)
+	cli.SetManifestStore(manifestStore)
+	cli.SetRegistryClient(registry)
+
+	listRef := ref(t, "list:v1")
+	imageManifest := fullImageManifest(t, ref(t, "list@"+digest.FromString("image").String()))
+	assert.NilError(t, manifestStore.Save(listRef, imageManifest.Ref, imageManifest))
+
+	attestation := fullImageManifest(t, ref(t, "list@"+digest.FromString("attestation").String()))
+	attestation.Descriptor.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+	attestation.Descriptor.Annotations = map[string]string{
+		manifesttypes.AnnotationReferenceType:   manifesttypes.AttestationManifestType,
+		manifesttypes.AnnotationReferenceDigest: imageManifest.Descriptor.Digest.String(),
+	}
+	assert.NilError(t, manifestStore.Save(listRef, attestation.Ref, attestation))
+
+	subject := &ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("subject"),
+		Size:      1234,
+	}
+	assert.NilError(t, manifestStore.SaveIndex(listRef, manifesttypes.ImageIndex{
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: "application/vnd.example.bundle",
+		Subject:      subject,
+		Annotations:  map[string]string{"org.opencontainers.image.version": "1.0"},
+	}))
+
+	cmd := newPushListCommand(cli)
+	cmd.SetArgs([]string{"example.com/list:v1"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), digest.FromBytes(pushed).String()+"\n"))
+
+	var index ocispec.Index
+	assert.NilError(t, json.Unmarshal(pushed, &index))
+	assert.Check(t, is.Equal(index.MediaType, ocispec.MediaTypeImageIndex))
+	assert.Check(t, is.Equal(index.ArtifactType, "application/vnd.example.bundle"))
+	assert.Check(t, is.DeepEqual(index.Subject, subject))
+	assert.Check(t, is.DeepEqual(index.Annotations, map[string]string{"org.opencontainers.image.version": "1.0"}))
+	assert.Assert(t, is.Len(index.Manifests, 2))
+	for _, desc := range index.Manifests {
+		if desc.Annotations == nil {
+			assert.Check(t, is.DeepEqual(desc.Platform, imageManifest.Descriptor.Platform))
+		} else {
+			assert.Check(t, is.DeepEqual(desc.Annotations, attestation.Descriptor.Annotations))
+			assert.Check(t, is.DeepEqual(desc.Platform, attestation.Descriptor.Platform))
+		}
+	}
+}
+
+func TestManifestPushDockerListWithAnnotations(t *testing.T) {
+	manifestStore := store.NewStore(t.TempDir())
+
+	cli := test.NewFakeCli(nil)
+	cli.SetManifestStore(manifestStore)
+	cli.SetRegistryClient(newFakeRegistryClient())
+
+	namedRef := ref(t, "alpine:3.0")
+	imageManifest := fullImageManifest(t, namedRef)
+	imageManifest.Descriptor.Annotations = map[string]string{"org.opencontainers.image.title": "alpine"}
+	assert.NilError(t, manifestStore.Save(ref(t, "list:v1"), namedRef, imageManifest))
+
+	cmd := newPushListCommand(cli)
+	cmd.SetArgs([]string{"example.com/list:v1"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "has annotations, which are only supported by an OCI image index")
+}
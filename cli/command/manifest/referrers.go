@@ -0,0 +1,211 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/docker/cli/internal/registryclient"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+)
+
+type referrersOptions struct {
+	ref          string
+	artifactType string
+	format       string
+	output       string
+	insecure     bool
+}
+
+// referrer is a manifest that has another manifest as its subject.
+type referrer struct {
+	ocispec.Descriptor
+	raw      []byte
+	manifest *ocispec.Manifest // nil if the referrer is not an image manifest
+}
+
+// newReferrersCommand creates a new `docker manifest referrers` command
+func newReferrersCommand(dockerCLI command.Cli) *cobra.Command {
+	var opts referrersOptions
+
+	cmd := &cobra.Command{
+		Use:   "referrers [OPTIONS] MANIFEST",
+		Short: "List the artifacts that refer to a manifest, such as signatures and SBOMs",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ref = args[0]
+			return runReferrers(cmd.Context(), dockerCLI, opts)
+		},
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.artifactType, "artifact-type", "", "Only show referrers with the given artifact type")
+	flags.StringVar(&opts.format, "format", "", flagsHelper.FormatHelp)
+	flags.StringVarP(&opts.output, "output", "o", "", "Download the manifests and layers of the referrers to a directory")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry")
+	return cmd
+}
+
+func runReferrers(ctx context.Context, dockerCLI command.Cli, opts referrersOptions) error {
+	namedRef, err := normalizeReference(opts.ref)
+	if err != nil {
+		return err
+	}
+	registryClient := command.NewRegistryClient(dockerCLI, opts.insecure)
+
+	// The referrers API needs the digest of the subject.
+	subject, ok := namedRef.(reference.Canonical)
+	if !ok {
+		desc, _, err := registryClient.GetRawManifest(ctx, namedRef)
+		if err != nil {
+			return err
+		}
+		subject, err = reference.WithDigest(reference.TrimNamed(namedRef), desc.Digest)
+		if err != nil {
+			return err
+		}
+	}
+
+	descriptors, err := registryClient.GetReferrers(ctx, subject, opts.artifactType)
+	if err != nil {
+		return err
+	}
+	referrers := make([]referrer, 0, len(descriptors))
+	for _, desc := range descriptors {
+		r, err := fetchReferrer(ctx, registryClient, subject, desc)
+		if err != nil {
+			return err
+		}
+		if opts.output != "" {
+			if err := downloadReferrer(ctx, registryClient, subject, r, opts.output); err != nil {
+				return err
+			}
+		}
+		referrers = append(referrers, r)
+	}
+
+	referrersCtx := formatter.Context{
+		Output: dockerCLI.Out(),
+		Format: newReferrersFormat(opts.format),
+	}
+	return referrersFormatWrite(referrersCtx, referrers)
+}
+
+// fetchReferrer fetches the manifest of a referrer, to determine its size and
+// artifact type, which defaults to the media type of the config if it is not
+// set.
+func fetchReferrer(ctx context.Context, registryClient registryclient.RegistryClient, repo reference.Named, desc ocispec.Descriptor) (referrer, error) {
+	ref, err := reference.WithDigest(reference.TrimNamed(repo), desc.Digest)
+	if err != nil {
+		return referrer{}, err
+	}
+	_, raw, err := registryClient.GetRawManifest(ctx, ref)
+	if err != nil {
+		return referrer{}, err
+	}
+	r := referrer{Descriptor: desc, raw: raw}
+	if desc.MediaType != ocispec.MediaTypeImageManifest && desc.MediaType != schema2.MediaTypeManifest {
+		return r, nil
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return referrer{}, fmt.Errorf("invalid manifest %s: %w", desc.Digest, err)
+	}
+	r.manifest = &manifest
+	if r.ArtifactType == "" {
+		r.ArtifactType = manifest.ArtifactType
+	}
+	if r.ArtifactType == "" {
+		r.ArtifactType = manifest.Config.MediaType
+	}
+	if len(r.Annotations) == 0 {
+		r.Annotations = manifest.Annotations
+	}
+	return r, nil
+}
+
+// size returns the total size of the referrer, including its config and
+// layers.
+func (r referrer) size() int64 {
+	size := r.Size
+	if r.manifest != nil {
+		size += r.manifest.Config.Size
+		for _, layer := range r.manifest.Layers {
+			size += layer.Size
+		}
+	}
+	return size
+}
+
+// downloadReferrer writes the manifest and layers of a referrer to a
+// subdirectory of dir that is named after its digest. Layers are named after
+// their "org.opencontainers.image.title" annotation, if set, or their digest.
+func downloadReferrer(ctx context.Context, registryClient registryclient.RegistryClient, repo reference.Named, r referrer, dir string) error {
+	dir = filepath.Join(dir, r.Digest.Algorithm().String()+"-"+r.Digest.Encoded())
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), r.raw, 0o644); err != nil {
+		return err
+	}
+	if r.manifest == nil {
+		return nil
+	}
+	for _, layer := range r.manifest.Layers {
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if name == "" || name != filepath.Base(name) || name == "." || name == ".." || name == "manifest.json" {
+			name = layer.Digest.Encoded()
+		}
+		if err := downloadBlob(ctx, registryClient, repo, layer.Digest, filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to download layer %s of %s: %w", layer.Digest, r.Digest, err)
+		}
+	}
+	return nil
+}
+
+func downloadBlob(ctx context.Context, registryClient registryclient.RegistryClient, repo reference.Named, dgst digest.Digest, filename string) (retErr error) {
+	if err := dgst.Validate(); err != nil {
+		return err
+	}
+	rc, err := registryClient.OpenBlob(ctx, reference.TrimNamed(repo), dgst)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+		if retErr != nil {
+			_ = os.Remove(filename)
+		}
+	}()
+
+	verifier := dgst.Verifier()
+	if _, err := io.Copy(io.MultiWriter(f, verifier), rc); err != nil {
+		return err
+	}
+	if !verifier.Verified() {
+		return errors.New("content does not match digest")
+	}
+	return nil
+}
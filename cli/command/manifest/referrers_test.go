@@ -0,0 +1,128 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestManifestReferrers(t *testing.T) {
+	content := map[digest.Digest][]byte{}
+	addContent := func(b []byte) digest.Digest {
+		dgst := digest.FromBytes(b)
+		content[dgst] = b
+		return dgst
+	}
+	subject := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    addContent([]byte(`{"schemaVersion":2}`)),
+		Size:      19,
+	}
+	sbom := []byte(`{"spdxVersion":"SPDX-2.3"}`)
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/spdx+json",
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers: []ocispec.Descriptor{{
+			MediaType:   "application/spdx+json",
+			Digest:      addContent(sbom),
+			Size:        int64(len(sbom)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: "sbom.spdx.json"},
+		}},
+		Subject:     &subject,
+		Annotations: map[string]string{"org.opencontainers.image.created": "2024-01-01T00:00:00Z"},
+	})
+	assert.NilError(t, err)
+	referrer := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    addContent(manifest),
+		Size:      int64(len(manifest)),
+	}
+
+	newCli := func(t *testing.T) *test.FakeCli {
+		t.Helper()
+		cli := test.NewFakeCli(nil)
+		cli.SetRegistryClient(&fakeRegistryClient{
+			getRawManifestFunc: func(_ context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+				switch r := ref.(type) {
+				case reference.Canonical:
+					return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: r.Digest()}, content[r.Digest()], nil
+				case reference.NamedTagged:
+					assert.Check(t, is.Equal(r.String(), "example.com/app:latest"))
+					return subject, content[subject.Digest], nil
+				}
+				return ocispec.Descriptor{}, nil, errors.New("unexpected reference")
+			},
+			getReferrersFunc: func(_ context.Context, ref reference.Canonical, artifactType string) ([]ocispec.Descriptor, error) {
+				assert.Check(t, is.Equal(ref.String(), "example.com/app@"+subject.Digest.String()))
+				if artifactType != "" && artifactType != "application/spdx+json" {
+					return []ocispec.Descriptor{}, nil
+				}
+				return []ocispec.Descriptor{referrer}, nil
+			},
+			openBlobFunc: func(_ context.Context, _ reference.Named, dgst digest.Digest) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(string(content[dgst]))), nil
+			},
+		})
+		return cli
+	}
+
+	t.Run("table", func(t *testing.T) {
+		cli := newCli(t)
+		cmd := newReferrersCommand(cli)
+		cmd.SetArgs([]string{"example.com/app"})
+		assert.NilError(t, cmd.Execute())
+		expected := "DIGEST                                                                    ARTIFACT TYPE           SIZE      ANNOTATIONS\n" +
+			referrer.Digest.String() + "   application/spdx+json   752B      org.opencontainers.image.created=2024-01-01T00:00:00Z\n"
+		assert.Check(t, is.Equal(cli.OutBuffer().String(), expected))
+	})
+
+	t.Run("artifact type", func(t *testing.T) {
+		cli := newCli(t)
+		cmd := newReferrersCommand(cli)
+		cmd.SetArgs([]string{"--artifact-type", "application/vnd.dev.sigstore.bundle.v0.3+json", "--format", "{{.Digest}}", "example.com/app"})
+		assert.NilError(t, cmd.Execute())
+		assert.Check(t, is.Equal(cli.OutBuffer().String(), ""))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		cli := newCli(t)
+		cmd := newReferrersCommand(cli)
+		cmd.SetArgs([]string{"--format", "json", "example.com/app@" + subject.Digest.String()})
+		assert.NilError(t, cmd.Execute())
+		var actual map[string]string
+		assert.NilError(t, json.Unmarshal(cli.OutBuffer().Bytes(), &actual))
+		assert.Check(t, is.Equal(actual["Digest"], referrer.Digest.String()))
+		assert.Check(t, is.Equal(actual["ArtifactType"], "application/spdx+json"))
+	})
+
+	t.Run("output", func(t *testing.T) {
+		dir := t.TempDir()
+		cli := newCli(t)
+		cmd := newReferrersCommand(cli)
+		cmd.SetArgs([]string{"--output", dir, "--format", "{{.Digest}}", "example.com/app"})
+		assert.NilError(t, cmd.Execute())
+
+		referrerDir := filepath.Join(dir, "sha256-"+referrer.Digest.Encoded())
+		actual, err := os.ReadFile(filepath.Join(referrerDir, "manifest.json"))
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(actual, manifest))
+		actual, err = os.ReadFile(filepath.Join(referrerDir, "sbom.spdx.json"))
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(actual, sbom))
+	})
+}
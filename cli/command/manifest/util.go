@@ -73,12 +73,27 @@ func getManifest(ctx context.Context, dockerCLI command.Cli, listRef, namedRef r
 	data, err := newManifestStore(dockerCLI).Get(listRef, namedRef)
 	switch {
 	case errdefs.IsNotFound(err):
-		return newRegistryClient(dockerCLI, insecure).GetManifest(ctx, namedRef)
+		return command.NewRegistryClient(dockerCLI, insecure).GetManifest(ctx, namedRef)
 	case err != nil:
 		return types.ImageManifest{}, err
 	case len(data.Raw) == 0:
-		return newRegistryClient(dockerCLI, insecure).GetManifest(ctx, namedRef)
+		return command.NewRegistryClient(dockerCLI, insecure).GetManifest(ctx, namedRef)
 	default:
 		return data, nil
 	}
 }
+
+// getManifests returns the manifest for an image, or all the manifests in a
+// multi-platform image if namedRef refers to a manifest list or image index
+// in the remote registry.
+func getManifests(ctx context.Context, dockerCLI command.Cli, listRef, namedRef reference.Named, insecure bool) (_ []types.ImageManifest, isList bool, _ error) {
+	manifest, err := getManifest(ctx, dockerCLI, listRef, namedRef, insecure)
+	if err == nil {
+		return []types.ImageManifest{manifest}, false, nil
+	}
+	manifests, listErr := command.NewRegistryClient(dockerCLI, insecure).GetManifestList(ctx, namedRef)
+	if listErr != nil {
+		return nil, false, err
+	}
+	return manifests, true, nil
+}
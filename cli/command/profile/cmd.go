@@ -0,0 +1,50 @@
+package profile
+
+import (
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/internal/commands"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	commands.Register(newProfileCommand)
+}
+
+// newProfileCommand returns a cobra command for `profile` subcommands
+func newProfileCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage configuration profiles",
+		Args:  cli.NoArgs,
+		RunE:  command.ShowHelp(dockerCLI.Err()),
+
+		DisableFlagsInUseLine: true,
+	}
+	cmd.AddCommand(
+		newListCommand(dockerCLI),
+		newShowCommand(dockerCLI),
+		newUseCommand(dockerCLI),
+	)
+	return cmd
+}
+
+// completeProfileNames offers completion for profile names, including the
+// "default" profile if includeDefault is set.
+func completeProfileNames(dockerCLI command.Cli, includeDefault bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		profiles, _ := config.Profiles(dockerCLI.ConfigFile())
+		var names []string
+		if includeDefault {
+			names = append(names, config.DefaultProfileName)
+		}
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,74 @@
+package profile
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/spf13/cobra"
+)
+
+type listOptions struct {
+	quiet bool
+}
+
+// newListCommand creates a new cobra.Command for `docker profile ls`
+func newListCommand(dockerCLI command.Cli) *cobra.Command {
+	var opts listOptions
+
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS]",
+		Aliases: []string{"list"},
+		Short:   "List profiles",
+		Args:    cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(dockerCLI, opts)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Only show profile names")
+	return cmd
+}
+
+func runList(dockerCLI command.Cli, opts listOptions) error {
+	cfg := dockerCLI.ConfigFile()
+	profiles, err := config.Profiles(cfg)
+	if err != nil {
+		return err
+	}
+	profiles = append([]config.Profile{{Name: config.DefaultProfileName}}, profiles...)
+
+	if opts.quiet {
+		for _, p := range profiles {
+			_, _ = fmt.Fprintln(dockerCLI.Out(), p.Name)
+		}
+		return nil
+	}
+
+	// contexts maps the names of profiles to the contexts that use them.
+	contexts := make(map[string][]string)
+	for _, ctx := range slices.Sorted(maps.Keys(cfg.ContextProfiles)) {
+		name := cfg.ContextProfiles[ctx]
+		contexts[name] = append(contexts[name], ctx)
+	}
+
+	active := config.ActiveProfile(cfg)
+	w := tabwriter.NewWriter(dockerCLI.Out(), 10, 1, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tCONTEXTS\tSOURCE")
+	for _, p := range profiles {
+		name := p.Name
+		if name == active {
+			name += " *"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", name, strings.Join(contexts[p.Name], ", "), p.Filename)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,28 @@
+package profile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestList(t *testing.T) {
+	fakeCLI := newTestCli(t)
+	cfg := fakeCLI.ConfigFile()
+	cfg.ContextProfiles = map[string]string{"staging": "ci", "test": "ci"}
+	assert.NilError(t, config.LoadProfile(cfg, "dev"))
+
+	assert.NilError(t, runCommand(fakeCLI, "ls"))
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), `NAME      CONTEXTS        SOURCE
+default                   
+ci        staging, test   `+filepath.Join(filepath.Dir(cfg.Filename), "config.ci.json")+`
+dev *                     `+cfg.Filename+`
+`))
+
+	fakeCLI.OutBuffer().Reset()
+	assert.NilError(t, runCommand(fakeCLI, "ls", "--quiet"))
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), "default\nci\ndev\n"))
+}
@@ -0,0 +1,52 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/spf13/cobra"
+)
+
+// newShowCommand creates a new cobra.Command for `docker profile show`
+func newShowCommand(dockerCLI command.Cli) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [PROFILE]",
+		Short: "Print the settings of a profile",
+		Args:  cli.RequiresMaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return runShow(dockerCLI, name)
+		},
+		ValidArgsFunction:     completeProfileNames(dockerCLI, false),
+		DisableFlagsInUseLine: true,
+	}
+}
+
+func runShow(dockerCLI command.Cli, name string) error {
+	cfg := dockerCLI.ConfigFile()
+	if name == "" {
+		name = config.ActiveProfile(cfg)
+		if name == config.DefaultProfileName {
+			return errors.New("no profile is used")
+		}
+	}
+	profile, err := config.GetProfile(cfg, name)
+	if err != nil {
+		return err
+	}
+	if len(profile.Ignored) > 0 {
+		_, _ = fmt.Fprintf(dockerCLI.Err(), "WARNING: Ignoring %s in profile %q\n", strings.Join(profile.Ignored, ", "), name)
+	}
+	enc := json.NewEncoder(dockerCLI.Out())
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	return enc.Encode(profile.Content())
+}
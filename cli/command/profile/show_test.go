@@ -0,0 +1,23 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestShow(t *testing.T) {
+	fakeCLI := newTestCli(t)
+	err := runCommand(fakeCLI, "show")
+	assert.Check(t, is.Error(err, "no profile is used"))
+
+	assert.NilError(t, config.LoadProfile(fakeCLI.ConfigFile(), "dev"))
+	assert.NilError(t, runCommand(fakeCLI, "show"))
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), "{\n\t\"psFormat\": \"dev\"\n}\n"))
+
+	fakeCLI.OutBuffer().Reset()
+	assert.NilError(t, runCommand(fakeCLI, "show", "ci"))
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), "{\n\t\"psFormat\": \"ci\"\n}\n"))
+}
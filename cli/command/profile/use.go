@@ -0,0 +1,92 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/spf13/cobra"
+)
+
+type useOptions struct {
+	context string
+}
+
+// newUseCommand creates a new cobra.Command for `docker profile use`
+func newUseCommand(dockerCLI command.Cli) *cobra.Command {
+	var opts useOptions
+
+	cmd := &cobra.Command{
+		Use:   "use [OPTIONS] PROFILE",
+		Short: "Set the default profile, or the profile to use with a context",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUse(dockerCLI, opts, args[0])
+		},
+		ValidArgsFunction:     completeProfileNames(dockerCLI, true),
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.context, "context", "", "Use the profile with this context")
+	_ = cmd.RegisterFlagCompletionFunc("context", completeContextNames(dockerCLI))
+	return cmd
+}
+
+func runUse(dockerCLI command.Cli, opts useOptions, name string) error {
+	// configValue uses an empty string for "default"
+	var configValue string
+	if name != config.DefaultProfileName {
+		if _, err := config.GetProfile(dockerCLI.ConfigFile(), name); err != nil {
+			return err
+		}
+		configValue = name
+	}
+
+	cfg := dockerCLI.ConfigFile()
+	if opts.context == "" {
+		if cfg.CurrentProfile != configValue {
+			cfg.CurrentProfile = configValue
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+		}
+		_, _ = fmt.Fprintln(dockerCLI.Out(), name)
+		_, _ = fmt.Fprintf(dockerCLI.Err(), "Current profile is now %q\n", name)
+		if p := cfg.ContextProfiles[dockerCLI.CurrentContext()]; p != "" {
+			_, _ = fmt.Fprintf(dockerCLI.Err(), "Warning: the current context %q uses profile %q.\n", dockerCLI.CurrentContext(), p)
+		}
+	} else {
+		if cfg.ContextProfiles[opts.context] != configValue {
+			if configValue == "" {
+				delete(cfg.ContextProfiles, opts.context)
+			} else {
+				if cfg.ContextProfiles == nil {
+					cfg.ContextProfiles = make(map[string]string)
+				}
+				cfg.ContextProfiles[opts.context] = configValue
+			}
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+		}
+		_, _ = fmt.Fprintln(dockerCLI.Out(), name)
+		_, _ = fmt.Fprintf(dockerCLI.Err(), "Profile for context %q is now %q\n", opts.context, name)
+	}
+	if os.Getenv(command.EnvOverrideProfile) != "" {
+		_, _ = fmt.Fprintf(dockerCLI.Err(), "Warning: %[1]s environment variable overrides the active profile. "+
+			"To use %[2]q, either set the global --profile flag, or unset %[1]s environment variable.\n", command.EnvOverrideProfile, name)
+	}
+	return nil
+}
+
+// completeContextNames offers completion for context names.
+func completeContextNames(dockerCLI command.Cli) cobra.CompletionFunc {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		names, _ := store.Names(dockerCLI.ContextStore())
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func newTestCli(t *testing.T) *test.FakeCli {
+	t.Helper()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, config.ConfigFileName)
+	content := `{
+	"psFormat": "user",
+	"profiles": {"dev": {"psFormat": "dev"}}
+}`
+	assert.NilError(t, os.WriteFile(filename, []byte(content), 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.ci.json"), []byte(`{"psFormat": "ci"}`), 0o600))
+	cfg := configfile.New(filename)
+	assert.NilError(t, cfg.LoadFromReader(strings.NewReader(content)))
+	fakeCLI := test.NewFakeCli(nil)
+	fakeCLI.SetConfigFile(cfg)
+	return fakeCLI
+}
+
+func runCommand(fakeCLI *test.FakeCli, args ...string) error {
+	cmd := newProfileCommand(fakeCLI)
+	cmd.SetArgs(args)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return cmd.Execute()
+}
+
+func loadConfigFile(t *testing.T, fakeCLI *test.FakeCli) *configfile.ConfigFile {
+	t.Helper()
+	f, err := os.Open(fakeCLI.ConfigFile().Filename)
+	assert.NilError(t, err)
+	defer f.Close()
+	cfg := configfile.New(fakeCLI.ConfigFile().Filename)
+	assert.NilError(t, cfg.LoadFromReader(f))
+	return cfg
+}
+
+func TestUse(t *testing.T) {
+	fakeCLI := newTestCli(t)
+
+	assert.NilError(t, runCommand(fakeCLI, "use", "ci"))
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), "ci\n"))
+	assert.Check(t, is.Equal(fakeCLI.ErrBuffer().String(), "Current profile is now \"ci\"\n"))
+	assert.Check(t, is.Equal(loadConfigFile(t, fakeCLI).CurrentProfile, "ci"))
+
+	assert.NilError(t, runCommand(fakeCLI, "use", "--context", "prod", "dev"))
+	assert.Check(t, is.DeepEqual(loadConfigFile(t, fakeCLI).ContextProfiles, map[string]string{"prod": "dev"}))
+
+	assert.NilError(t, runCommand(fakeCLI, "use", "--context", "prod", "default"))
+	assert.NilError(t, runCommand(fakeCLI, "use", "default"))
+	cfg := loadConfigFile(t, fakeCLI)
+	assert.Check(t, is.Equal(cfg.CurrentProfile, ""))
+	assert.Check(t, is.Len(cfg.ContextProfiles, 0))
+
+	err := runCommand(fakeCLI, "use", "prod")
+	assert.Check(t, is.Error(err, `profile "prod" not found`))
+}
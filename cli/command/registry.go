@@ -15,6 +15,7 @@
 	"github.com/docker/cli/cli/hints"
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/cli/internal/prompt"
+	"github.com/docker/cli/internal/registryclient"
 	"github.com/docker/cli/internal/tui"
 	"github.com/moby/moby/api/pkg/authconfig"
 	registrytypes "github.com/moby/moby/api/types/registry"
@@ -35,6 +36,22 @@
 // [registry.IndexServer]: https://pkg.go.dev/github.com/docker/docker@v28.3.3+incompatible/registry#IndexServer
 const authConfigKey = "https://index.docker.io/v1/"
 
+// registryClientProvider is used in tests to provide a fake registry client.
+type registryClientProvider interface {
+	RegistryClient(allowInsecure bool) registryclient.RegistryClient
+}
+
+// NewRegistryClient returns a client for communicating with a Docker
+// distribution registry. It uses the credentials and the registry options
+// (such as mirrors) of the CLI's config file.
+func NewRegistryClient(dockerCLI Cli, allowInsecure bool) registryclient.RegistryClient {
+	if rcp, ok := dockerCLI.(registryClientProvider); ok {
+		return rcp.RegistryClient(allowInsecure)
+	}
+	cfg := dockerCLI.ConfigFile()
+	return registryclient.NewRegistryClient(registryclient.ConfigFileResolver(cfg), UserAgent(), allowInsecure, registryclient.WithConfigFile(cfg))
+}
+
 // ResolveAuthConfig returns auth-config for the given registry from the
 // credential-store. It returns an empty AuthConfig if no credentials were
 // found.
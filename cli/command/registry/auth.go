@@ -0,0 +1,263 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/docker/cli/internal/oauth"
+	"github.com/docker/cli/internal/oauth/manager"
+	"github.com/docker/cli/internal/registry"
+	"github.com/docker/cli/internal/registryclient"
+	registrytypes "github.com/moby/moby/api/types/registry"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// Status of stored credentials, after validating them with the registry.
+const (
+	authStatusValid   = "valid"
+	authStatusInvalid = "invalid" // the registry rejected the credentials
+	authStatusError   = "error"   // the credentials could not be validated
+)
+
+// fileStoreName is the name of the credential store that keeps credentials in
+// the config file.
+const fileStoreName = "file"
+
+// maxConcurrentAuthChecks is the maximum number of registries to validate
+// credentials with at a time.
+const maxConcurrentAuthChecks = 4
+
+// oauthAccessTokenKey is the key under which the OAuth access token for
+// Docker Hub is stored, in addition to the credentials for Docker Hub itself.
+const oauthAccessTokenKey = registry.IndexServer + "access-token"
+
+// newAuthCommand returns a cobra command for `registry auth` subcommands
+func newAuthCommand(dockerCLI command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth COMMAND",
+		Short: "Manage stored registry credentials",
+		Args:  cli.NoArgs,
+		RunE:  command.ShowHelp(dockerCLI.Err()),
+
+		DisableFlagsInUseLine: true,
+	}
+	cmd.AddCommand(
+		newAuthListCommand(dockerCLI),
+		newAuthStatusCommand(dockerCLI),
+		newAuthMigrateCommand(dockerCLI),
+	)
+	return cmd
+}
+
+type authListOptions struct {
+	registries []string
+	check      bool
+	format     string
+	exitStatus bool // exit with status 1 if any of the credentials are not valid
+}
+
+// newAuthListCommand creates a new `docker registry auth ls` command
+func newAuthListCommand(dockerCLI command.Cli) *cobra.Command {
+	var options authListOptions
+
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS]",
+		Aliases: []string{"list"},
+		Short:   "List the registries that credentials are stored for",
+		Args:    cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuthList(cmd.Context(), dockerCLI, options)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&options.check, "check", false, "Validate the credentials with each registry")
+	flags.StringVar(&options.format, "format", "", flagsHelper.FormatHelp)
+	return cmd
+}
+
+// newAuthStatusCommand creates a new `docker registry auth status` command
+func newAuthStatusCommand(dockerCLI command.Cli) *cobra.Command {
+	options := authListOptions{check: true, exitStatus: true}
+
+	cmd := &cobra.Command{
+		Use:   "status [OPTIONS] [REGISTRY...]",
+		Short: "Validate the stored credentials with their registries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.registries = args
+			return runAuthList(cmd.Context(), dockerCLI, options)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.format, "format", "", flagsHelper.FormatHelp)
+	return cmd
+}
+
+// storedAuth is a stored credential, and the result of validating it.
+type storedAuth struct {
+	key     string // key in the credential store, such as "https://index.docker.io/v1/"
+	config  types.AuthConfig
+	store   string // credential helper or store, or "file"
+	expires time.Time
+	checked bool
+	err     error
+}
+
+func (a storedAuth) status() string {
+	switch {
+	case !a.checked:
+		return ""
+	case a.err == nil:
+		return authStatusValid
+	case errdefs.IsUnauthorized(a.err):
+		return authStatusInvalid
+	default:
+		return authStatusError
+	}
+}
+
+func runAuthList(ctx context.Context, dockerCLI command.Cli, options authListOptions) error {
+	var hosts []string
+	for _, r := range options.registries {
+		host, err := normalizeRegistryHost(r)
+		if err != nil {
+			return err
+		}
+		hosts = append(hosts, host)
+	}
+
+	cfg := dockerCLI.ConfigFile()
+	all, err := cfg.GetAllCredentials()
+	if err != nil {
+		return err
+	}
+	var accessToken string
+	if t, ok := all[oauthAccessTokenKey]; ok {
+		accessToken = t.Password
+	}
+
+	auths := make([]storedAuth, 0, len(all))
+	for key, ac := range all {
+		if manager.IsTokenKey(key) {
+			continue
+		}
+		if len(hosts) > 0 && !slices.Contains(hosts, registryHost(key)) {
+			continue
+		}
+		a := storedAuth{key: key, config: ac, store: cfg.CredentialsStoreName(key)}
+		if a.store == "" {
+			a.store = fileStoreName
+		}
+		tokens := []string{ac.IdentityToken, ac.RegistryToken, ac.Password}
+		if key == registry.IndexServer {
+			tokens = append([]string{accessToken}, tokens...)
+		}
+		a.expires = tokenExpiry(tokens...)
+		auths = append(auths, a)
+	}
+	for _, host := range hosts {
+		if !slices.ContainsFunc(auths, func(a storedAuth) bool { return registryHost(a.key) == host }) {
+			return errdefs.ErrNotFound.WithMessage("no credentials stored for " + host)
+		}
+	}
+	slices.SortFunc(auths, func(a, b storedAuth) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	if options.check {
+		svc, err := registry.NewService(registryclient.ServiceOptionsFromConfigFile(cfg))
+		if err != nil {
+			return err
+		}
+		var eg errgroup.Group
+		eg.SetLimit(maxConcurrentAuthChecks)
+		for i := range auths {
+			eg.Go(func() error {
+				a := &auths[i]
+				a.checked = true
+				a.err = checkAuth(ctx, svc, a.key, a.config)
+				return nil
+			})
+		}
+		_ = eg.Wait()
+	}
+
+	authCtx := formatter.Context{
+		Output: dockerCLI.Out(),
+		Format: newAuthFormat(options.format, options.check),
+	}
+	if err := authFormatWrite(authCtx, auths, time.Now()); err != nil {
+		return err
+	}
+	for _, a := range auths {
+		if a.err != nil {
+			_, _ = fmt.Fprintf(dockerCLI.Err(), "%s: %v\n", a.key, a.err)
+		}
+	}
+	if options.exitStatus && slices.ContainsFunc(auths, func(a storedAuth) bool { return a.err != nil }) {
+		return cli.StatusError{StatusCode: 1}
+	}
+	return nil
+}
+
+// checkAuth validates the credentials with the registry, by logging in to it.
+func checkAuth(ctx context.Context, svc *registry.Service, key string, ac types.AuthConfig) error {
+	serverAddress := ac.ServerAddress
+	if serverAddress == "" {
+		serverAddress = key
+	}
+	if ac.Username == "" && ac.Password == "" && ac.IdentityToken == "" {
+		return errors.New("no username, password, or identity token to validate")
+	}
+	_, err := svc.Auth(ctx, &registrytypes.AuthConfig{
+		Username:      ac.Username,
+		Password:      ac.Password,
+		ServerAddress: serverAddress,
+		IdentityToken: ac.IdentityToken,
+	}, command.UserAgent())
+	return err
+}
+
+// registryHost returns the normalized hostname of the registry that
+// credentials are stored for under the given key.
+func registryHost(key string) string {
+	host := credentials.ConvertToHostname(key)
+	if host == registry.IndexHostname {
+		return registry.IndexName
+	}
+	return host
+}
+
+// tokenExpiry returns the expiry of the first token that is a JWT with an
+// expiry, such as an access token that was issued by an OAuth login, or a
+// zero time if none of the tokens expire.
+func tokenExpiry(tokens ...string) time.Time {
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		claims, err := oauth.GetClaims(token)
+		if err != nil || claims.Expiry == nil {
+			continue
+		}
+		return claims.Expiry.Time()
+	}
+	return time.Time{}
+}
@@ -0,0 +1,335 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/spf13/cobra"
+)
+
+type authMigrateOptions struct {
+	to         string
+	registries []string
+	dryRun     bool
+}
+
+// newAuthMigrateCommand creates a new `docker registry auth migrate` command
+func newAuthMigrateCommand(dockerCLI command.Cli) *cobra.Command {
+	var options authMigrateOptions
+
+	cmd := &cobra.Command{
+		Use:   "migrate [OPTIONS] --to STORE [REGISTRY...]",
+		Short: "Move stored credentials between the config file and a credential helper",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.registries = args
+			return runAuthMigrate(dockerCLI, options)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.to, "to", "", `Credential helper to move the credentials to, such as "pass", or "file" to move them to the config file`)
+	_ = cmd.MarkFlagRequired("to")
+	flags.BoolVar(&options.dryRun, "dry-run", false, "Show the credentials that would be migrated, without migrating them")
+	return cmd
+}
+
+// migratedAuth is a credential that is moved from one store to another.
+type migratedAuth struct {
+	key    string // key in the credential store, such as "https://index.docker.io/v1/"
+	config types.AuthConfig
+	from   string // credential helper or store, or "file"
+
+	// unused is set for credentials that are left behind in the config file
+	// for a registry that already has credentials in the credential helper
+	// it is migrated to. They are not used, and are removed without copying
+	// them to the helper.
+	unused bool
+}
+
+// memoryFile is an in-memory replacement for the config file of a native
+// store, which keeps the native store from writing to the config file before
+// all credentials are migrated.
+type memoryFile struct {
+	authConfigs map[string]types.AuthConfig
+}
+
+func (*memoryFile) Save() error { return nil }
+
+func (f *memoryFile) GetAuthConfigs() map[string]types.AuthConfig { return f.authConfigs }
+
+func (*memoryFile) GetFilename() string { return "" }
+
+// newNativeStore returns the store for the given credential helper, or the
+// encrypted file store. It is a variable so that it can be replaced in tests.
+var newNativeStore = func(cfg *configfile.ConfigFile, helper string) credentials.Store {
+	file := &memoryFile{authConfigs: make(map[string]types.AuthConfig)}
+	if helper == credentials.EncryptedFileStore {
+		var dir string
+		if cfg.Filename != "" {
+			dir = filepath.Dir(cfg.Filename)
+		}
+		return credentials.NewEncryptedStore(file, dir)
+	}
+	return credentials.NewNativeStore(file, helper)
+}
+
+func runAuthMigrate(dockerCLI command.Cli, options authMigrateOptions) error {
+	if options.to == "" {
+		return errors.New("no credential store specified")
+	}
+	var hosts []string
+	for _, r := range options.registries {
+		host, err := normalizeRegistryHost(r)
+		if err != nil {
+			return err
+		}
+		hosts = append(hosts, host)
+	}
+
+	var (
+		auths []migratedAuth
+		err   error
+	)
+	if options.to == fileStoreName {
+		auths, err = helperCredentials(dockerCLI.ConfigFile(), hosts)
+	} else {
+		auths, err = fileCredentials(dockerCLI.ConfigFile(), options.to, hosts)
+	}
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if !slices.ContainsFunc(auths, func(a migratedAuth) bool { return registryHost(a.key) == host }) {
+			return errdefs.ErrNotFound.WithMessage("no credentials to migrate for " + host)
+		}
+	}
+	slices.SortFunc(auths, func(a, b migratedAuth) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	if options.dryRun {
+		for _, a := range auths {
+			if a.unused {
+				_, _ = fmt.Fprintf(dockerCLI.Out(), "Would remove unused credentials for %s from %s, as %s has credentials for it\n", a.key, a.from, options.to)
+				continue
+			}
+			_, _ = fmt.Fprintf(dockerCLI.Out(), "Would migrate credentials for %s from %s to %s\n", a.key, a.from, options.to)
+		}
+		return nil
+	}
+
+	if options.to == fileStoreName {
+		err = migrateToFile(dockerCLI, auths, len(hosts) == 0)
+	} else {
+		err = migrateToHelper(dockerCLI.ConfigFile(), options.to, auths, len(hosts) == 0)
+	}
+	if err != nil {
+		return err
+	}
+	for _, a := range auths {
+		if a.unused {
+			_, _ = fmt.Fprintf(dockerCLI.Out(), "Removed unused credentials for %s from %s, as %s has credentials for it\n", a.key, a.from, options.to)
+			continue
+		}
+		_, _ = fmt.Fprintf(dockerCLI.Out(), "Migrated credentials for %s from %s to %s\n", a.key, a.from, options.to)
+	}
+	return nil
+}
+
+// fileCredentials returns the credentials in the config file that are to be
+// migrated to the given credential helper. This includes credentials that are
+// left behind in the config file for registries that already use the helper,
+// such as after configuring it as credsStore by hand.
+func fileCredentials(cfg *configfile.ConfigFile, helper string, hosts []string) ([]migratedAuth, error) {
+	if len(hosts) == 0 && cfg.CredentialsStore != "" && cfg.CredentialsStore != helper {
+		return nil, fmt.Errorf("credentials are stored in the %q credential store: migrate them to the config file with --to=file first", cfg.CredentialsStore)
+	}
+	var (
+		auths []migratedAuth
+		store credentials.Store
+	)
+	for key, ac := range cfg.GetAuthConfigs() {
+		if !hasCredentials(ac) {
+			// Entries without credentials only keep the email of credentials
+			// that are stored in a credential helper.
+			continue
+		}
+		owner := cfg.CredentialsStoreName(key)
+		if owner != "" && owner != helper {
+			// not used; credentials for this registry are stored in another
+			// credential helper.
+			continue
+		}
+		if len(hosts) > 0 && !slices.Contains(hosts, registryHost(key)) {
+			continue
+		}
+		ac.ServerAddress = key
+		a := migratedAuth{key: key, config: ac, from: fileStoreName}
+		if owner == helper {
+			if store == nil {
+				store = newNativeStore(cfg, helper)
+			}
+			existing, err := store.Get(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get credentials for %s from %s: %w", key, helper, err)
+			}
+			a.unused = hasCredentials(existing)
+		}
+		auths = append(auths, a)
+	}
+	return auths, nil
+}
+
+// helperCredentials returns the credentials in the configured credential
+// helpers that are to be migrated to the config file.
+func helperCredentials(cfg *configfile.ConfigFile, hosts []string) ([]migratedAuth, error) {
+	var helpers []string
+	if cfg.CredentialsStore != "" {
+		helpers = append(helpers, cfg.CredentialsStore)
+	}
+	for _, helper := range cfg.CredentialHelpers {
+		if !slices.Contains(helpers, helper) {
+			helpers = append(helpers, helper)
+		}
+	}
+
+	var auths []migratedAuth
+	for _, helper := range helpers {
+		all, err := newNativeStore(cfg, helper).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list credentials in %s: %w", helper, err)
+		}
+		for key, ac := range all {
+			if cfg.CredentialsStoreName(key) != helper {
+				// not used; credentials for this registry are stored elsewhere.
+				continue
+			}
+			if len(hosts) > 0 {
+				if !slices.Contains(hosts, registryHost(key)) {
+					continue
+				}
+				if _, ok := cfg.CredentialHelpers[key]; !ok {
+					return nil, fmt.Errorf("credentials for %s are stored in the %q credential store, which is used for all registries: migrate all credentials by omitting the registry", key, helper)
+				}
+			}
+			ac.ServerAddress = key
+			auths = append(auths, migratedAuth{key: key, config: ac, from: helper})
+		}
+	}
+	return auths, nil
+}
+
+// migrateToHelper copies the credentials from the config file to the given
+// credential helper, and verifies them by reading them back from the helper.
+// The credentials are only removed from the config file, and the helper
+// configured, once all of them are copied.
+func migrateToHelper(cfg *configfile.ConfigFile, helper string, auths []migratedAuth, allRegistries bool) error {
+	store := newNativeStore(cfg, helper)
+	for _, a := range auths {
+		if a.unused {
+			continue
+		}
+		if err := store.Store(a.config); err != nil {
+			return fmt.Errorf("failed to store credentials for %s in %s: %w", a.key, helper, err)
+		}
+		if err := verifyCredentials(store, a.key, a.config); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range auths {
+		delete(cfg.GetAuthConfigs(), a.key)
+		if !allRegistries && cfg.CredentialsStoreName(a.key) != helper {
+			if cfg.CredentialHelpers == nil {
+				cfg.CredentialHelpers = make(map[string]string)
+			}
+			cfg.CredentialHelpers[a.key] = helper
+		}
+	}
+	if allRegistries {
+		cfg.CredentialsStore = helper
+	}
+	return cfg.Save()
+}
+
+// migrateToFile copies the credentials from their credential helpers to the
+// config file, and removes them from the helpers once the config file is
+// written. The credentials are verified by reading them back from the config
+// file before it is written.
+func migrateToFile(dockerCLI command.Cli, auths []migratedAuth, allRegistries bool) error {
+	cfg := dockerCLI.ConfigFile()
+	for _, a := range auths {
+		cfg.GetAuthConfigs()[a.key] = a.config
+		if cfg.CredentialHelpers[a.key] == a.from {
+			delete(cfg.CredentialHelpers, a.key)
+		}
+	}
+	if allRegistries {
+		cfg.CredentialsStore = ""
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.SaveToWriter(&buf); err != nil {
+		return err
+	}
+	written := configfile.New(cfg.Filename)
+	if err := written.LoadFromReader(&buf); err != nil {
+		return err
+	}
+	fileStore := credentials.NewFileStore(written)
+	for _, a := range auths {
+		if err := verifyCredentials(fileStore, a.key, a.config); err != nil {
+			return err
+		}
+	}
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	// The credentials are migrated at this point; leaving a copy behind in
+	// the helper is not fatal.
+	for _, a := range auths {
+		if err := newNativeStore(cfg, a.from).Erase(a.key); err != nil {
+			_, _ = fmt.Fprintf(dockerCLI.Err(), "WARNING: failed to remove credentials for %s from %s: %v\n", a.key, a.from, err)
+		}
+	}
+	return nil
+}
+
+// verifyCredentials reads back the credentials for the given key from the
+// store, and checks that they match the credentials that were copied to it.
+func verifyCredentials(store credentials.Store, key string, want types.AuthConfig) error {
+	got, err := store.Get(key)
+	if err != nil {
+		return fmt.Errorf("failed to verify credentials for %s: %w", key, err)
+	}
+	// Credential helpers store either an identity token, or a username and
+	// password.
+	if want.IdentityToken != "" {
+		if got.IdentityToken != want.IdentityToken {
+			return fmt.Errorf("failed to verify credentials for %s: identity token does not match", key)
+		}
+		return nil
+	}
+	if got.Username != want.Username || got.Password != want.Password {
+		return fmt.Errorf("failed to verify credentials for %s: username or password does not match", key)
+	}
+	return nil
+}
+
+// hasCredentials returns whether the auth config has a username, password,
+// or identity token.
+func hasCredentials(ac types.AuthConfig) bool {
+	return ac.Username != "" || ac.Password != "" || ac.IdentityToken != ""
+}
@@ -0,0 +1,260 @@
+package registry
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// fakeCredentialHelper is an in-memory credential helper.
+type fakeCredentialHelper struct {
+	auths    map[string]types.AuthConfig
+	storeErr error
+	mangle   bool // store a different password than the one given
+}
+
+func (h *fakeCredentialHelper) Erase(serverAddress string) error {
+	delete(h.auths, serverAddress)
+	return nil
+}
+
+func (h *fakeCredentialHelper) Get(serverAddress string) (types.AuthConfig, error) {
+	return h.auths[serverAddress], nil
+}
+
+func (h *fakeCredentialHelper) GetAll() (map[string]types.AuthConfig, error) {
+	return maps.Clone(h.auths), nil
+}
+
+func (h *fakeCredentialHelper) Store(authConfig types.AuthConfig) error {
+	if h.storeErr != nil {
+		return h.storeErr
+	}
+	if h.mangle {
+		authConfig.Password += "-mangled"
+	}
+	h.auths[authConfig.ServerAddress] = authConfig
+	return nil
+}
+
+// withFakeCredentialHelpers replaces the native stores with the given fake
+// credential helpers for the duration of the test.
+func withFakeCredentialHelpers(t *testing.T, helpers map[string]*fakeCredentialHelper) {
+	t.Helper()
+	orig := newNativeStore
+	t.Cleanup(func() { newNativeStore = orig })
+	newNativeStore = func(_ *configfile.ConfigFile, helper string) credentials.Store {
+		h, ok := helpers[helper]
+		if !ok {
+			t.Fatalf("unexpected credential helper: %s", helper)
+		}
+		return h
+	}
+}
+
+// loadConfigFile loads the config file as it was written to disk.
+func loadConfigFile(t *testing.T, filename string) *configfile.ConfigFile {
+	t.Helper()
+	f, err := os.Open(filename)
+	assert.NilError(t, err)
+	defer f.Close()
+	cfg := configfile.New(filename)
+	assert.NilError(t, cfg.LoadFromReader(f))
+	return cfg
+}
+
+func TestAuthMigrateToHelper(t *testing.T) {
+	helper := &fakeCredentialHelper{auths: map[string]types.AuthConfig{}}
+	withFakeCredentialHelpers(t, map[string]*fakeCredentialHelper{"pass": helper})
+	fakeCLI := newAuthTestCli(t, map[string]types.AuthConfig{
+		"https://index.docker.io/v1/": {Username: "hubuser", Password: "pat"},
+		"registry.example.com":        {Username: "user", Password: "secret"},
+	})
+
+	cmd := newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "pass"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), ""+
+		"Migrated credentials for https://index.docker.io/v1/ from file to pass\n"+
+		"Migrated credentials for registry.example.com from file to pass\n"))
+	assert.Check(t, is.DeepEqual(helper.auths, map[string]types.AuthConfig{
+		"https://index.docker.io/v1/": {Username: "hubuser", Password: "pat", ServerAddress: "https://index.docker.io/v1/"},
+		"registry.example.com":        {Username: "user", Password: "secret", ServerAddress: "registry.example.com"},
+	}))
+
+	cfg := loadConfigFile(t, fakeCLI.ConfigFile().Filename)
+	assert.Check(t, is.Equal(cfg.CredentialsStore, "pass"))
+	assert.Check(t, is.Len(cfg.AuthConfigs, 0))
+}
+
+func TestAuthMigrateToHelperRegistry(t *testing.T) {
+	helper := &fakeCredentialHelper{auths: map[string]types.AuthConfig{}}
+	withFakeCredentialHelpers(t, map[string]*fakeCredentialHelper{"pass": helper})
+	fakeCLI := newAuthTestCli(t, map[string]types.AuthConfig{
+		"https://index.docker.io/v1/": {Username: "hubuser", Password: "pat"},
+		"registry.example.com":        {Username: "user", Password: "secret"},
+	})
+
+	cmd := newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "pass", "--dry-run", "registry.example.com"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), "Would migrate credentials for registry.example.com from file to pass\n"))
+	assert.Check(t, is.Len(helper.auths, 0))
+
+	cmd = newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "pass", "registry.example.com"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Len(helper.auths, 1))
+
+	cfg := loadConfigFile(t, fakeCLI.ConfigFile().Filename)
+	assert.Check(t, is.Equal(cfg.CredentialsStore, ""))
+	assert.Check(t, is.DeepEqual(cfg.CredentialHelpers, map[string]string{"registry.example.com": "pass"}))
+	assert.Check(t, is.Len(cfg.AuthConfigs, 1))
+	assert.Check(t, is.Equal(cfg.AuthConfigs["https://index.docker.io/v1/"].Username, "hubuser"))
+}
+
+func TestAuthMigrateToHelperLeftover(t *testing.T) {
+	helper := &fakeCredentialHelper{auths: map[string]types.AuthConfig{
+		"registry.example.com": {Username: "user", Password: "current", ServerAddress: "registry.example.com"},
+	}}
+	withFakeCredentialHelpers(t, map[string]*fakeCredentialHelper{"pass": helper})
+	fakeCLI := newAuthTestCli(t, map[string]types.AuthConfig{
+		"https://index.docker.io/v1/": {Username: "hubuser", Password: "pat"},
+		"registry.example.com":        {Username: "user", Password: "old"},
+	})
+	fakeCLI.ConfigFile().CredentialsStore = "pass"
+
+	cmd := newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "pass", "--dry-run"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), ""+
+		"Would migrate credentials for https://index.docker.io/v1/ from file to pass\n"+
+		"Would remove unused credentials for registry.example.com from file, as pass has credentials for it\n"))
+
+	fakeCLI.OutBuffer().Reset()
+	cmd = newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "pass"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), ""+
+		"Migrated credentials for https://index.docker.io/v1/ from file to pass\n"+
+		"Removed unused credentials for registry.example.com from file, as pass has credentials for it\n"))
+
+	// Credentials in the helper are not replaced by the ones that were left
+	// behind in the config file.
+	assert.Check(t, is.DeepEqual(helper.auths, map[string]types.AuthConfig{
+		"https://index.docker.io/v1/": {Username: "hubuser", Password: "pat", ServerAddress: "https://index.docker.io/v1/"},
+		"registry.example.com":        {Username: "user", Password: "current", ServerAddress: "registry.example.com"},
+	}))
+
+	cfg := loadConfigFile(t, fakeCLI.ConfigFile().Filename)
+	assert.Check(t, is.Equal(cfg.CredentialsStore, "pass"))
+	assert.Check(t, is.Len(cfg.CredentialHelpers, 0))
+	assert.Check(t, is.Len(cfg.AuthConfigs, 0))
+}
+
+func TestAuthMigrateToHelperFailure(t *testing.T) {
+	tests := []struct {
+		doc         string
+		helper      *fakeCredentialHelper
+		expectedErr string
+	}{
+		{
+			doc:         "store error",
+			helper:      &fakeCredentialHelper{auths: map[string]types.AuthConfig{}, storeErr: errors.New("helper not found")},
+			expectedErr: "failed to store credentials for registry.example.com in pass: helper not found",
+		},
+		{
+			doc:         "verify error",
+			helper:      &fakeCredentialHelper{auths: map[string]types.AuthConfig{}, mangle: true},
+			expectedErr: "failed to verify credentials for registry.example.com: username or password does not match",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.doc, func(t *testing.T) {
+			withFakeCredentialHelpers(t, map[string]*fakeCredentialHelper{"pass": tc.helper})
+			fakeCLI := newAuthTestCli(t, map[string]types.AuthConfig{
+				"registry.example.com": {Username: "user", Password: "secret"},
+			})
+
+			cmd := newAuthMigrateCommand(fakeCLI)
+			cmd.SetArgs([]string{"--to", "pass"})
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			assert.Check(t, is.Error(cmd.Execute(), tc.expectedErr))
+
+			cfg := fakeCLI.ConfigFile()
+			assert.Check(t, is.Equal(cfg.CredentialsStore, ""))
+			assert.Check(t, is.Equal(cfg.AuthConfigs["registry.example.com"].Password, "secret"))
+			_, err := os.Stat(cfg.Filename)
+			assert.Check(t, os.IsNotExist(err), "config file should not be written")
+		})
+	}
+}
+
+func TestAuthMigrateToFile(t *testing.T) {
+	store := &fakeCredentialHelper{auths: map[string]types.AuthConfig{
+		"https://index.docker.io/v1/": {Username: "hubuser", Password: "pat", ServerAddress: "https://index.docker.io/v1/"},
+		"registry.example.com":        {Username: "user", Password: "old", ServerAddress: "registry.example.com"},
+	}}
+	helper := &fakeCredentialHelper{auths: map[string]types.AuthConfig{
+		"registry.example.com": {Username: "user", Password: "secret", ServerAddress: "registry.example.com"},
+	}}
+	withFakeCredentialHelpers(t, map[string]*fakeCredentialHelper{"desktop": store, "pass": helper})
+	fakeCLI := newAuthTestCli(t, map[string]types.AuthConfig{})
+	fakeCLI.ConfigFile().CredentialsStore = "desktop"
+	fakeCLI.ConfigFile().CredentialHelpers = map[string]string{"registry.example.com": "pass"}
+
+	cmd := newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "file", "docker.io"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.ErrorContains(cmd.Execute(), `credentials for https://index.docker.io/v1/ are stored in the "desktop" credential store, which is used for all registries`))
+
+	cmd = newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "file"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(fakeCLI.OutBuffer().String(), ""+
+		"Migrated credentials for https://index.docker.io/v1/ from desktop to file\n"+
+		"Migrated credentials for registry.example.com from pass to file\n"))
+
+	// Credentials in the credential store that are shadowed by a credential
+	// helper are not migrated.
+	assert.Check(t, is.DeepEqual(store.auths, map[string]types.AuthConfig{
+		"registry.example.com": {Username: "user", Password: "old", ServerAddress: "registry.example.com"},
+	}))
+	assert.Check(t, is.Len(helper.auths, 0))
+
+	cfg := loadConfigFile(t, fakeCLI.ConfigFile().Filename)
+	assert.Check(t, is.Equal(cfg.CredentialsStore, ""))
+	assert.Check(t, is.Len(cfg.CredentialHelpers, 0))
+	assert.Check(t, is.Equal(cfg.AuthConfigs["https://index.docker.io/v1/"].Password, "pat"))
+	assert.Check(t, is.Equal(cfg.AuthConfigs["registry.example.com"].Password, "secret"))
+}
+
+func TestAuthMigrateNotFound(t *testing.T) {
+	withFakeCredentialHelpers(t, map[string]*fakeCredentialHelper{})
+	fakeCLI := newAuthTestCli(t, map[string]types.AuthConfig{
+		"registry.example.com": {Username: "user", Password: "secret"},
+	})
+	fakeCLI.ConfigFile().CredentialsStore = "desktop"
+
+	cmd := newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "pass"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.ErrorContains(cmd.Execute(), `credentials are stored in the "desktop" credential store`))
+
+	cmd = newAuthMigrateCommand(fakeCLI)
+	cmd.SetArgs([]string{"--to", "pass", "registry.example.com"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	assert.Check(t, is.Error(cmd.Execute(), "no credentials to migrate for registry.example.com"))
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/cli/config/credentials"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/spf13/cobra"
+)
+
+type catalogOptions struct {
+	host     string
+	limit    int
+	format   string
+	insecure bool
+}
+
+// newCatalogCommand creates a new `docker registry catalog` command
+func newCatalogCommand(dockerCLI command.Cli) *cobra.Command {
+	var options catalogOptions
+
+	cmd := &cobra.Command{
+		Use:   "catalog [OPTIONS] REGISTRY",
+		Short: "List the repositories in a registry",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.host = args[0]
+			return runCatalog(cmd.Context(), dockerCLI, options)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.IntVar(&options.limit, "limit", 0, "Maximum number of repositories to show")
+	flags.StringVar(&options.format, "format", "", flagsHelper.FormatHelp)
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+	return cmd
+}
+
+func runCatalog(ctx context.Context, dockerCLI command.Cli, options catalogOptions) error {
+	if options.limit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+	host, err := normalizeRegistryHost(options.host)
+	if err != nil {
+		return err
+	}
+
+	names, err := command.NewRegistryClient(dockerCLI, options.insecure).GetCatalog(ctx, host)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(names, compareNatural)
+	if options.limit > 0 && len(names) > options.limit {
+		names = names[:options.limit]
+	}
+
+	catalogCtx := formatter.Context{
+		Output: dockerCLI.Out(),
+		Format: newCatalogFormat(options.format),
+	}
+	return catalogFormatWrite(catalogCtx, names)
+}
+
+// normalizeRegistryHost returns the hostname (and port) of a registry, which
+// can be given as a hostname, or as a URL.
+func normalizeRegistryHost(registry string) (string, error) {
+	host := credentials.ConvertToHostname(registry)
+	if host == "index.docker.io" {
+		host = "docker.io"
+	}
+	named, err := reference.ParseNormalizedNamed(host + "/repository")
+	if err != nil || reference.Domain(named) != host {
+		return "", fmt.Errorf("invalid registry hostname: %s", registry)
+	}
+	return host, nil
+}
@@ -0,0 +1,67 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestRegistryCatalog(t *testing.T) {
+	testCases := []struct {
+		name         string
+		args         []string
+		expectedHost string
+		expected     string
+		expectedErr  string
+	}{
+		{
+			name:         "hostname",
+			args:         []string{"example.com:5000"},
+			expectedHost: "example.com:5000",
+			expected:     "NAME\napp\napp2\napp10\ntools/lint\n",
+		},
+		{
+			name:         "url",
+			args:         []string{"--limit", "1", "--format", "{{.Name}}", "https://example.com:5000/v2/"},
+			expectedHost: "example.com:5000",
+			expected:     "app\n",
+		},
+		{
+			name:         "docker hub",
+			args:         []string{"--format", "json", "--limit", "1", "index.docker.io"},
+			expectedHost: "docker.io",
+			expected:     `{"Name":"app"}` + "\n",
+		},
+		{
+			name:        "invalid hostname",
+			args:        []string{"not a host"},
+			expectedErr: "invalid registry hostname: not a host",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetRegistryClient(&fakeRegistryClient{
+				getCatalogFunc: func(_ context.Context, hostname string) ([]string, error) {
+					assert.Check(t, is.Equal(hostname, tc.expectedHost))
+					return []string{"tools/lint", "app10", "app", "app2"}, nil
+				},
+			})
+			cmd := newCatalogCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			err := cmd.Execute()
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+		})
+	}
+}
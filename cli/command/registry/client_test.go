@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/internal/registryclient"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type fakeRegistryClient struct {
+	registryclient.RegistryClient
+	getTagsFunc        func(ctx context.Context, ref reference.Named) ([]string, error)
+	getCatalogFunc     func(ctx context.Context, hostname string) ([]string, error)
+	getRawManifestFunc func(ctx context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error)
+	getBlobFunc        func(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]byte, error)
+	deleteManifestFunc func(ctx context.Context, ref reference.Canonical) error
+}
+
+func (c *fakeRegistryClient) GetTags(ctx context.Context, ref reference.Named) ([]string, error) {
+	if c.getTagsFunc != nil {
+		return c.getTagsFunc(ctx, ref)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) GetCatalog(ctx context.Context, hostname string) ([]string, error) {
+	if c.getCatalogFunc != nil {
+		return c.getCatalogFunc(ctx, hostname)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) GetRawManifest(ctx context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+	if c.getRawManifestFunc != nil {
+		return c.getRawManifestFunc(ctx, ref)
+	}
+	return ocispec.Descriptor{}, nil, nil
+}
+
+func (c *fakeRegistryClient) GetUpstreamManifest(ctx context.Context, ref reference.Named, _ ...string) (ocispec.Descriptor, []byte, error) {
+	return c.GetRawManifest(ctx, ref)
+}
+
+func (c *fakeRegistryClient) GetBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]byte, error) {
+	if c.getBlobFunc != nil {
+		return c.getBlobFunc(ctx, ref, dgst)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) DeleteManifest(ctx context.Context, ref reference.Canonical) error {
+	if c.deleteManifestFunc != nil {
+		return c.deleteManifestFunc(ctx, ref)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/go-units"
+)
+
+const (
+	defaultAuthTableFormat      = "table {{.Registry}}\t{{.Username}}\t{{.Store}}\t{{.Expires}}"
+	defaultAuthCheckTableFormat = "table {{.Registry}}\t{{.Username}}\t{{.Store}}\t{{.Expires}}\t{{.Status}}"
+
+	registryHeader = "REGISTRY"
+	usernameHeader = "USERNAME"
+	storeHeader    = "STORE"
+	expiresHeader  = "EXPIRES"
+	errorHeader    = "ERROR"
+)
+
+// newAuthFormat returns a Format for rendering using an authContext. The
+// default table format includes the status of the credentials if they are
+// validated.
+func newAuthFormat(source string, check bool) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		if check {
+			return defaultAuthCheckTableFormat
+		}
+		return defaultAuthTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// authFormatWrite writes the stored credentials using the context. The
+// expiry of tokens is shown relative to now.
+func authFormatWrite(fmtCtx formatter.Context, auths []storedAuth, now time.Time) error {
+	authCtx := &authContext{
+		HeaderContext: formatter.HeaderContext{
+			Header: formatter.SubHeaderContext{
+				"Registry":  registryHeader,
+				"Username":  usernameHeader,
+				"Store":     storeHeader,
+				"Expires":   expiresHeader,
+				"ExpiresAt": expiresHeader,
+				"Status":    formatter.StatusHeader,
+				"Error":     errorHeader,
+			},
+		},
+	}
+	return fmtCtx.Write(authCtx, func(format func(subContext formatter.SubContext) error) error {
+		for _, a := range auths {
+			if err := format(&authContext{a: a, now: now}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type authContext struct {
+	formatter.HeaderContext
+	a   storedAuth
+	now time.Time
+}
+
+func (c *authContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+// Registry returns the key that the credentials are stored under.
+func (c *authContext) Registry() string {
+	return c.a.key
+}
+
+func (c *authContext) Username() string {
+	return c.a.config.Username
+}
+
+// Store returns the credential helper or store that holds the credentials,
+// or "file" if they are stored in the config file.
+func (c *authContext) Store() string {
+	return c.a.store
+}
+
+// Expires returns when the token of the credentials expires, relative to
+// now, or an empty string if the credentials do not expire.
+func (c *authContext) Expires() string {
+	switch {
+	case c.a.expires.IsZero():
+		return ""
+	case c.a.expires.After(c.now):
+		return "in " + units.HumanDuration(c.a.expires.Sub(c.now))
+	default:
+		return "expired " + units.HumanDuration(c.now.Sub(c.a.expires)) + " ago"
+	}
+}
+
+// ExpiresAt returns the time when the token of the credentials expires, or
+// an empty string if the credentials do not expire.
+func (c *authContext) ExpiresAt() string {
+	if c.a.expires.IsZero() {
+		return ""
+	}
+	return c.a.expires.Format(time.RFC3339)
+}
+
+// Status returns the status of the credentials ("valid", "invalid", or
+// "error"), or an empty string if they were not validated.
+func (c *authContext) Status() string {
+	return c.a.status()
+}
+
+// Error returns the error that validating the credentials produced.
+func (c *authContext) Error() string {
+	if c.a.err == nil {
+		return ""
+	}
+	return c.a.err.Error()
+}
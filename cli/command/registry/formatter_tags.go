@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"github.com/docker/cli/cli/command/formatter"
+)
+
+const (
+	defaultTagsTableFormat    = "table {{.Tag}}"
+	defaultCatalogTableFormat = "table {{.Name}}"
+
+	repositoryHeader = "REPOSITORY"
+	tagHeader        = "TAG"
+)
+
+// newTagsFormat returns a Format for rendering using a tagContext.
+func newTagsFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultTagsTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// tagsFormatWrite writes the tags of a repository using the context.
+func tagsFormatWrite(fmtCtx formatter.Context, repository string, tags []string) error {
+	tagCtx := &tagContext{
+		HeaderContext: formatter.HeaderContext{
+			Header: formatter.SubHeaderContext{
+				"Repository": repositoryHeader,
+				"Tag":        tagHeader,
+			},
+		},
+	}
+	return fmtCtx.Write(tagCtx, func(format func(subContext formatter.SubContext) error) error {
+		for _, tag := range tags {
+			if err := format(&tagContext{repository: repository, tag: tag}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type tagContext struct {
+	formatter.HeaderContext
+	repository string
+	tag        string
+}
+
+func (c *tagContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *tagContext) Repository() string {
+	return c.repository
+}
+
+func (c *tagContext) Tag() string {
+	return c.tag
+}
+
+// newCatalogFormat returns a Format for rendering using a catalogContext.
+func newCatalogFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultCatalogTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// catalogFormatWrite writes the repositories of a registry using the context.
+func catalogFormatWrite(fmtCtx formatter.Context, names []string) error {
+	catalogCtx := &catalogContext{
+		HeaderContext: formatter.HeaderContext{
+			Header: formatter.SubHeaderContext{
+				"Name": formatter.NameHeader,
+			},
+		},
+	}
+	return fmtCtx.Write(catalogCtx, func(format func(subContext formatter.SubContext) error) error {
+		for _, name := range names {
+			if err := format(&catalogContext{name: name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type catalogContext struct {
+	formatter.HeaderContext
+	name string
+}
+
+func (c *catalogContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *catalogContext) Name() string {
+	return c.name
+}
@@ -12,10 +12,12 @@
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
 	configtypes "github.com/docker/cli/cli/config/types"
 	"github.com/docker/cli/internal/commands"
 	"github.com/docker/cli/internal/oauth/manager"
 	"github.com/docker/cli/internal/registry"
+	"github.com/docker/cli/internal/registryclient"
 	"github.com/docker/cli/internal/tui"
 	registrytypes "github.com/moby/moby/api/types/registry"
 	"github.com/moby/moby/client"
@@ -32,6 +34,7 @@ type loginOptions struct {
 	user          string
 	password      string
 	passwordStdin bool
+	oidc          bool
 }
 
 // newLoginCommand creates a new `docker login` command
@@ -64,6 +67,7 @@ func newLoginCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.StringVarP(&opts.user, "username", "u", "", "Username")
 	flags.StringVarP(&opts.password, "password", "p", "", `Password or Personal Access Token (PAT), or "-" to read from stdin`)
 	flags.BoolVar(&opts.passwordStdin, "password-stdin", false, "Take the Password or Personal Access Token (PAT) from stdin")
+	flags.BoolVar(&opts.oidc, "oidc", false, "Log in with the OpenID Connect provider that is configured for the registry")
 
 	return cmd
 }
@@ -72,6 +76,9 @@ func newLoginCommand(dockerCLI command.Cli) *cobra.Command {
 //
 // TODO(thaJeztah); combine with verifyLoginOptions, but this requires rewrites of many tests.
 func verifyLoginFlags(flags *pflag.FlagSet, opts loginOptions) error {
+	if opts.oidc && (flags.Changed("username") || flags.Changed("password") || flags.Changed("password-stdin")) {
+		return errors.New("conflicting options: cannot specify --oidc with --username, --password or --password-stdin")
+	}
 	if flags.Changed("password-stdin") || opts.password == "-" {
 		if flags.Changed("password") && opts.password != "-" {
 			return errors.New("conflicting options: cannot specify both --password and --password-stdin")
@@ -165,6 +172,20 @@ func runLogin(ctx context.Context, dockerCLI command.Cli, opts loginOptions) err
 	}
 	isDefaultRegistry := serverAddress == registry.IndexServer
 
+	if opts.oidc {
+		if isDefaultRegistry {
+			return errors.New("the --oidc option requires a registry to be specified")
+		}
+		msg, err := loginWithOIDC(ctx, dockerCLI, credentials.ConvertToHostname(serverAddress))
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			_, _ = fmt.Fprintln(dockerCLI.Out(), msg)
+		}
+		return nil
+	}
+
 	// attempt login with current (stored) credentials
 	authConfig, err := command.GetDefaultAuthConfig(dockerCLI.ConfigFile(), opts.user == "" && opts.password == "", serverAddress, isDefaultRegistry)
 	if err == nil && authConfig.Username != "" && authConfig.Password != "" {
@@ -260,7 +281,7 @@ func loginWithUsernameAndPassword(ctx context.Context, dockerCLI command.Cli, op
 		return "", err
 	}
 
-	res, err := loginWithRegistry(ctx, dockerCLI.Client(), client.RegistryLoginOptions{
+	res, err := loginWithRegistry(ctx, dockerCLI, client.RegistryLoginOptions{
 		Username:      authConfig.Username,
 		Password:      authConfig.Password,
 		ServerAddress: authConfig.ServerAddress,
@@ -289,7 +310,7 @@ func loginWithDeviceCodeFlow(ctx context.Context, dockerCLI command.Cli) (msg st
 		return "", err
 	}
 
-	response, err := loginWithRegistry(ctx, dockerCLI.Client(), client.RegistryLoginOptions{
+	response, err := loginWithRegistry(ctx, dockerCLI, client.RegistryLoginOptions{
 		Username:      authConfig.Username,
 		Password:      authConfig.Password,
 		ServerAddress: authConfig.ServerAddress,
@@ -319,6 +340,40 @@ func loginWithDeviceCodeFlow(ctx context.Context, dockerCLI command.Cli) (msg st
 	return response.Auth.Status, nil
 }
 
+// loginWithOIDC logs in to the registry with the device authorization flow of
+// the OpenID Connect provider in the "registryOIDC" property of the config
+// file. The access token is used as the password for the registry, and is
+// refreshed with the refresh token before it expires.
+func loginWithOIDC(ctx context.Context, dockerCLI command.Cli, serverAddress string) (msg string, _ error) {
+	oidcConfig, ok := dockerCLI.ConfigFile().RegistryOIDC[serverAddress]
+	if !ok {
+		return "", fmt.Errorf("no OIDC provider configured for %s: add it to the \"registryOIDC\" property in the config file", serverAddress)
+	}
+	store := dockerCLI.ConfigFile().GetCredentialsStore(serverAddress)
+	m, err := manager.NewOIDCManager(ctx, store, serverAddress, oidcConfig)
+	if err != nil {
+		return "", err
+	}
+	authConfig, err := m.LoginDevice(ctx, dockerCLI.Err())
+	if err != nil {
+		return "", err
+	}
+
+	response, err := loginWithRegistry(ctx, dockerCLI, client.RegistryLoginOptions{
+		Username:      authConfig.Username,
+		Password:      authConfig.Password,
+		ServerAddress: authConfig.ServerAddress,
+	})
+	if err != nil {
+		// the tokens were stored by the manager; don't keep credentials
+		// that the registry does not accept.
+		_ = m.Logout(ctx)
+		_ = store.Erase(serverAddress)
+		return "", err
+	}
+	return response.Auth.Status, nil
+}
+
 func storeCredentials(cfg *configfile.ConfigFile, authConfig registrytypes.AuthConfig) error {
 	creds := cfg.GetCredentialsStore(authConfig.ServerAddress)
 	if err := creds.Store(configtypes.AuthConfig{
@@ -337,12 +392,13 @@ func storeCredentials(cfg *configfile.ConfigFile, authConfig registrytypes.AuthC
 	return nil
 }
 
-func loginWithRegistry(ctx context.Context, apiClient client.SystemAPIClient, options client.RegistryLoginOptions) (client.RegistryLoginResult, error) {
-	res, err := apiClient.RegistryLogin(ctx, options)
+func loginWithRegistry(ctx context.Context, dockerCLI command.Cli, options client.RegistryLoginOptions) (client.RegistryLoginResult, error) {
+	res, err := dockerCLI.Client().RegistryLogin(ctx, options)
 	if err != nil {
 		if client.IsErrConnectionFailed(err) {
-			// daemon isn't responding; attempt to login client side.
-			return loginClientSide(ctx, options)
+			// daemon isn't responding; attempt to login client side, using
+			// the registry TLS settings of the CLI's config file.
+			return loginClientSide(ctx, registryclient.ServiceOptionsFromConfigFile(dockerCLI.ConfigFile()), options)
 		}
 		return client.RegistryLoginResult{}, err
 	}
@@ -350,8 +406,8 @@ func loginWithRegistry(ctx context.Context, apiClient client.SystemAPIClient, op
 	return res, nil
 }
 
-func loginClientSide(ctx context.Context, options client.RegistryLoginOptions) (client.RegistryLoginResult, error) {
-	svc, err := registry.NewService(registry.ServiceOptions{})
+func loginClientSide(ctx context.Context, serviceOpts registry.ServiceOptions, options client.RegistryLoginOptions) (client.RegistryLoginResult, error) {
+	svc, err := registry.NewService(serviceOpts)
 	if err != nil {
 		return client.RegistryLoginResult{}, err
 	}
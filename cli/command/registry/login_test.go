@@ -708,6 +708,21 @@ func TestLoginValidateFlags(t *testing.T) {
 			args:        []string{"--password"},
 			expectedErr: `flag needs an argument: --password`,
 		},
+		{
+			name:        "conflicting options --oidc and --username",
+			args:        []string{"--oidc", "--username", "my-username", "registry.example.com"},
+			expectedErr: `conflicting options: cannot specify --oidc with --username, --password or --password-stdin`,
+		},
+		{
+			name:        "--oidc without registry",
+			args:        []string{"--oidc"},
+			expectedErr: `the --oidc option requires a registry to be specified`,
+		},
+		{
+			name:        "--oidc without provider",
+			args:        []string{"--oidc", "https://registry.example.com/"},
+			expectedErr: `no OIDC provider configured for registry.example.com: add it to the "registryOIDC" property in the config file`,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			cmd := newLoginCommand(test.NewFakeCli(&fakeClient{}))
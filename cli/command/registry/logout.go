@@ -6,6 +6,7 @@
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/config/credentials"
 	"github.com/docker/cli/internal/commands"
 	"github.com/docker/cli/internal/oauth/manager"
@@ -67,6 +68,10 @@ func runLogout(ctx context.Context, dockerCLI command.Cli, serverAddress string)
 		if err := manager.NewManager(store).Logout(ctx); err != nil {
 			_, _ = fmt.Fprintln(dockerCLI.Err(), "WARNING:", err)
 		}
+	} else if oidcConfig, ok := dockerCLI.ConfigFile().RegistryOIDC[hostnameAddress]; ok {
+		if err := logoutOIDC(ctx, dockerCLI, hostnameAddress, oidcConfig); err != nil {
+			_, _ = fmt.Fprintln(dockerCLI.Err(), "WARNING:", err)
+		}
 	}
 
 	_, _ = fmt.Fprintln(dockerCLI.Out(), "Removing login credentials for", hostnameAddress)
@@ -87,3 +92,18 @@ func runLogout(ctx context.Context, dockerCLI command.Cli, serverAddress string)
 
 	return nil
 }
+
+// logoutOIDC revokes the refresh token that was stored by "docker login --oidc"
+// with the OpenID Connect provider of the registry, and erases it from the
+// store. The token is erased if the provider can't be reached as well.
+func logoutOIDC(ctx context.Context, dockerCLI command.Cli, serverAddress string, oidcConfig configfile.RegistryOIDCConfig) error {
+	store := dockerCLI.ConfigFile().GetCredentialsStore(serverAddress)
+	m, err := manager.NewOIDCManager(ctx, store, serverAddress, oidcConfig)
+	if err != nil {
+		if eraseErr := store.Erase(manager.RefreshTokenKey(serverAddress)); eraseErr != nil {
+			return fmt.Errorf("failed to erase tokens: %w", eraseErr)
+		}
+		return fmt.Errorf("credentials erased successfully, but the OAuth refresh token could not be revoked: %w", err)
+	}
+	return m.Logout(ctx)
+}
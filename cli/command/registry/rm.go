@@ -0,0 +1,400 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/prompt"
+	"github.com/docker/cli/internal/registryclient"
+	"github.com/docker/cli/opts"
+	"github.com/moby/moby/client"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+)
+
+type rmOptions struct {
+	refs     []string
+	filter   opts.FilterOpt
+	dryRun   bool
+	force    bool
+	insecure bool
+}
+
+// newRmCommand creates a new `docker registry rm` command
+func newRmCommand(dockerCLI command.Cli) *cobra.Command {
+	options := rmOptions{filter: opts.NewFilterOpt()}
+
+	cmd := &cobra.Command{
+		Use:     "rm [OPTIONS] REFERENCE [REFERENCE...]",
+		Aliases: []string{"remove"},
+		Short:   "Delete manifests and tags from a registry",
+		Args:    cli.RequiresMinArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.refs = args
+			return runRm(cmd.Context(), dockerCLI, options)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.VarP(&options.filter, "filter", "f", `Delete the tags of a repository that match the conditions provided (e.g. "tag=1.*", "semver=<2", or "until=720h")`)
+	flags.BoolVar(&options.dryRun, "dry-run", false, "Show what would be deleted, without deleting anything")
+	flags.BoolVar(&options.force, "force", false, "Do not prompt for confirmation, and delete manifests that have other tags")
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+	return cmd
+}
+
+// rmTarget is a manifest to delete, and the tags that refer to it.
+type rmTarget struct {
+	ref  reference.Canonical
+	tags []reference.NamedTagged
+
+	// otherTags are the tags that refer to the manifest, but that were not
+	// selected. They are removed as well when the manifest is deleted.
+	otherTags []reference.NamedTagged
+}
+
+const rmWarning = `WARNING! This will delete the manifests listed above, and all tags that refer to them.
+Are you sure you want to continue?`
+
+func runRm(ctx context.Context, dockerCLI command.Cli, options rmOptions) error {
+	filters := options.filter.Value()
+	until, err := untilFilter(filters, time.Now())
+	if err != nil {
+		return err
+	}
+	filters = filters.Clone()
+	delete(filters, "until")
+	match, err := tagsMatcher(filters)
+	if err != nil {
+		return err
+	}
+	hasFilter := len(options.filter.Value()) > 0
+
+	registryClient := command.NewRegistryClient(dockerCLI, options.insecure)
+	resolver := &tagResolver{registryClient: registryClient, digests: make(map[string]digest.Digest)}
+
+	var (
+		targets []*rmTarget
+		errs    []error
+	)
+	addTarget := func(ref reference.Canonical, tag reference.NamedTagged) {
+		i := slices.IndexFunc(targets, func(t *rmTarget) bool { return t.ref.String() == ref.String() })
+		if i < 0 {
+			targets = append(targets, &rmTarget{ref: ref})
+			i = len(targets) - 1
+		}
+		if tag != nil {
+			targets[i].tags = append(targets[i].tags, tag)
+		}
+	}
+	for _, arg := range options.refs {
+		namedRef, err := reference.ParseNormalizedNamed(arg)
+		if err != nil {
+			return err
+		}
+		switch {
+		case reference.IsNameOnly(namedRef):
+			if !hasFilter {
+				return fmt.Errorf("invalid reference (%s): must contain a tag or digest, or --filter must be set to select the tags to delete", arg)
+			}
+			tags, err := registryClient.GetTags(ctx, namedRef)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, tag := range tags {
+				if !match(tag) {
+					continue
+				}
+				tagged, err := reference.WithTag(namedRef, tag)
+				if err != nil {
+					return err
+				}
+				ref, created, err := resolver.resolve(ctx, tagged, !until.IsZero())
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if !until.IsZero() && (created.IsZero() || !created.Before(until)) {
+					continue
+				}
+				addTarget(ref, tagged)
+			}
+		case hasFilter:
+			return fmt.Errorf("invalid reference (%s): --filter can only be used with a repository name", arg)
+		default:
+			if canonical, ok := namedRef.(reference.Canonical); ok {
+				ref, err := reference.WithDigest(reference.TrimNamed(namedRef), canonical.Digest())
+				if err != nil {
+					return err
+				}
+				addTarget(ref, nil)
+				continue
+			}
+			tagged := namedRef.(reference.NamedTagged)
+			ref, _, err := resolver.resolve(ctx, tagged, false)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			addTarget(ref, tagged)
+		}
+	}
+
+	// Deleting a manifest removes all the tags that refer to it, so look up
+	// the tags that were not selected, and refuse to delete their manifest
+	// unless forced.
+	targets, err = resolver.findOtherTags(ctx, targets)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if !options.force {
+		targets = slices.DeleteFunc(targets, func(t *rmTarget) bool {
+			if len(t.otherTags) == 0 {
+				return false
+			}
+			errs = append(errs, fmt.Errorf("refusing to delete %s: it is also tagged as %s; use --force to delete it and all its tags",
+				reference.FamiliarString(t.ref), strings.Join(familiarTags(t.otherTags), ", ")))
+			return true
+		})
+	}
+
+	if options.dryRun || (!options.force && len(targets) > 0) {
+		for _, target := range targets {
+			printRmTarget(dockerCLI.Out(), target, true)
+		}
+		if options.dryRun {
+			return errors.Join(errs...)
+		}
+		r, err := prompt.Confirm(ctx, dockerCLI.In(), dockerCLI.Out(), rmWarning)
+		if err != nil {
+			return err
+		}
+		if !r {
+			return cancelledErr{errors.New("registry rm has been cancelled")}
+		}
+	}
+
+	for _, target := range targets {
+		if err := registryClient.DeleteManifest(ctx, target.ref); err != nil {
+			if errdefs.IsNotImplemented(err) {
+				// The registry won't delete any of the other manifests either.
+				return fmt.Errorf("%w: deleting must be enabled in the registry's configuration, or may not be supported by the registry at all", err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		printRmTarget(dockerCLI.Out(), target, false)
+	}
+	return errors.Join(errs...)
+}
+
+// printRmTarget prints the tags and the manifest that are deleted, or that
+// would be deleted if dryRun is set.
+func printRmTarget(out io.Writer, target *rmTarget, dryRun bool) {
+	untagged, deleted := "Untagged", "Deleted"
+	if dryRun {
+		untagged, deleted = "Would untag", "Would delete"
+	}
+	for _, tag := range slices.Concat(target.tags, target.otherTags) {
+		_, _ = fmt.Fprintf(out, "%s: %s\n", untagged, reference.FamiliarString(tag))
+	}
+	_, _ = fmt.Fprintf(out, "%s: %s\n", deleted, reference.FamiliarString(target.ref))
+}
+
+func familiarTags(tags []reference.NamedTagged) []string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		out = append(out, reference.FamiliarString(tag))
+	}
+	return out
+}
+
+type cancelledErr struct{ error }
+
+func (cancelledErr) Cancelled() {}
+
+// tagResolver resolves tags to the manifest they refer to. Tags are resolved
+// on the registry itself, and not on its mirrors, as the manifests are
+// deleted from the registry itself. The digests of the tags are remembered,
+// so that each tag is only resolved once.
+type tagResolver struct {
+	registryClient registryclient.RegistryClient
+	digests        map[string]digest.Digest
+}
+
+// resolve returns the reference by digest of the manifest that the tag
+// refers to, and, if withCreated is set, the creation time of the image.
+func (r *tagResolver) resolve(ctx context.Context, tagged reference.NamedTagged, withCreated bool) (reference.Canonical, time.Time, error) {
+	desc, raw, err := r.registryClient.GetUpstreamManifest(ctx, tagged, "pull", "delete")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	r.digests[tagged.String()] = desc.Digest
+	ref, err := reference.WithDigest(reference.TrimNamed(tagged), desc.Digest)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if !withCreated {
+		return ref, time.Time{}, nil
+	}
+	created, err := imageCreated(ctx, r.registryClient, ref, raw)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return ref, created, nil
+}
+
+// findOtherTags sets the tags of the targets' manifests that were not
+// selected, by resolving all tags of their repositories. Targets in a
+// repository whose tags can't be listed are removed, as deleting them could
+// remove tags that are unknown.
+func (r *tagResolver) findOtherTags(ctx context.Context, targets []*rmTarget) ([]*rmTarget, error) {
+	selected := make(map[string]bool)
+	var repos []reference.Named
+	for _, t := range targets {
+		for _, tag := range t.tags {
+			selected[tag.String()] = true
+		}
+		repo := reference.TrimNamed(t.ref)
+		if !slices.ContainsFunc(repos, func(r reference.Named) bool { return r.String() == repo.String() }) {
+			repos = append(repos, repo)
+		}
+	}
+
+	var errs []error
+	for _, repo := range repos {
+		inRepo := func(t *rmTarget) bool { return reference.TrimNamed(t.ref).String() == repo.String() }
+		tags, err := r.registryClient.GetTags(ctx, repo)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list the tags of %s: %w", reference.FamiliarName(repo), err))
+			targets = slices.DeleteFunc(targets, inRepo)
+			continue
+		}
+		for _, tag := range tags {
+			tagged, err := reference.WithTag(repo, tag)
+			if err != nil {
+				return nil, err
+			}
+			if selected[tagged.String()] {
+				continue
+			}
+			dgst, ok := r.digests[tagged.String()]
+			if !ok {
+				ref, _, err := r.resolve(ctx, tagged, false)
+				if errdefs.IsNotFound(err) {
+					// The tag was deleted in the meantime.
+					continue
+				}
+				if err != nil {
+					errs = append(errs, err)
+					targets = slices.DeleteFunc(targets, inRepo)
+					break
+				}
+				dgst = ref.Digest()
+			}
+			for _, t := range targets {
+				if inRepo(t) && t.ref.Digest() == dgst {
+					t.otherTags = append(t.otherTags, tagged)
+				}
+			}
+		}
+	}
+	return targets, errors.Join(errs...)
+}
+
+// untilFilter returns the time of the "until" filter, which is a timestamp,
+// or a duration relative to now. A zero time is returned if the filter is not
+// set.
+func untilFilter(filters client.Filters, now time.Time) (time.Time, error) {
+	values := slices.Collect(maps.Keys(filters["until"]))
+	switch len(values) {
+	case 0:
+		return time.Time{}, nil
+	case 1:
+	default:
+		return time.Time{}, errors.New("the until filter can only be set once")
+	}
+	if d, err := time.ParseDuration(values[0]); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, values[0], time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until filter %q: value must be a duration (e.g. 720h) or a timestamp (e.g. 2006-01-02)", values[0])
+}
+
+// imageCreated returns the creation time of the image with the given
+// manifest, from its "org.opencontainers.image.created" annotation or the
+// "created" field of its image config. The most recent creation time of the
+// images in an image index is returned, ignoring attestations. A zero time
+// is returned if the creation time is unknown.
+func imageCreated(ctx context.Context, registryClient registryclient.RegistryClient, ref reference.Canonical, raw []byte) (time.Time, error) {
+	var mfst struct {
+		Annotations map[string]string    `json:"annotations,omitempty"`
+		Config      *ocispec.Descriptor  `json:"config,omitempty"`
+		Manifests   []ocispec.Descriptor `json:"manifests,omitempty"`
+	}
+	if err := json.Unmarshal(raw, &mfst); err != nil {
+		return time.Time{}, fmt.Errorf("invalid manifest %s: %w", ref, err)
+	}
+	if v, ok := mfst.Annotations[ocispec.AnnotationCreated]; ok {
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
+		}
+	}
+	if mfst.Config != nil {
+		b, err := registryClient.GetBlob(ctx, ref, mfst.Config.Digest)
+		if err != nil {
+			return time.Time{}, err
+		}
+		var config ocispec.Image
+		if err := json.Unmarshal(b, &config); err != nil || config.Created == nil {
+			return time.Time{}, nil
+		}
+		return *config.Created, nil
+	}
+	var created time.Time
+	for _, m := range mfst.Manifests {
+		if _, ok := m.Annotations[types.AnnotationReferenceType]; ok {
+			continue
+		}
+		t, err := manifestCreated(ctx, registryClient, ref, m.Digest)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if t.After(created) {
+			created = t
+		}
+	}
+	return created, nil
+}
+
+func manifestCreated(ctx context.Context, registryClient registryclient.RegistryClient, namedRef reference.Named, dgst digest.Digest) (time.Time, error) {
+	ref, err := reference.WithDigest(reference.TrimNamed(namedRef), dgst)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, raw, err := registryClient.GetRawManifest(ctx, ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return imageCreated(ctx, registryClient, ref, raw)
+}
@@ -0,0 +1,278 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// rmTestImage returns the raw manifest and config of an image created at
+// the given time.
+func rmTestImage(t *testing.T, created time.Time) (manifest []byte, config []byte) {
+	t.Helper()
+	config, err := json.Marshal(ocispec.Image{Created: &created})
+	assert.NilError(t, err)
+	manifest, err = json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageConfig,
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		},
+	})
+	assert.NilError(t, err)
+	return manifest, config
+}
+
+func TestRegistryRm(t *testing.T) {
+	now := time.Now()
+	oldManifest, oldConfig := rmTestImage(t, now.Add(-60*24*time.Hour))
+	newManifest, newConfig := rmTestImage(t, now.Add(-time.Hour))
+	oldDigest, newDigest := digest.FromBytes(oldManifest), digest.FromBytes(newManifest)
+
+	// "1.0" and "1.1" refer to the same, old image.
+	manifests := map[string][]byte{
+		"1.0":    oldManifest,
+		"1.1":    oldManifest,
+		"2.0":    newManifest,
+		"latest": newManifest,
+	}
+	blobs := map[digest.Digest][]byte{
+		digest.FromBytes(oldConfig): oldConfig,
+		digest.FromBytes(newConfig): newConfig,
+	}
+
+	testCases := []struct {
+		name            string
+		args            []string
+		expected        string
+		expectedDeleted []digest.Digest
+		expectedErr     string
+	}{
+		{
+			name:            "tag",
+			args:            []string{"--force", "example.com/app:2.0"},
+			expected:        "Untagged: example.com/app:2.0\nUntagged: example.com/app:latest\nDeleted: example.com/app@" + newDigest.String() + "\n",
+			expectedDeleted: []digest.Digest{newDigest},
+		},
+		{
+			name:        "tag with other tags without force",
+			args:        []string{"example.com/app:2.0"},
+			expectedErr: "refusing to delete example.com/app@" + newDigest.String() + ": it is also tagged as example.com/app:latest; use --force",
+		},
+		{
+			name:            "digest",
+			args:            []string{"--force", "example.com/app@" + oldDigest.String()},
+			expected:        "Untagged: example.com/app:1.0\nUntagged: example.com/app:1.1\nDeleted: example.com/app@" + oldDigest.String() + "\n",
+			expectedDeleted: []digest.Digest{oldDigest},
+		},
+		{
+			name:        "digest without force",
+			args:        []string{"example.com/app@" + oldDigest.String()},
+			expectedErr: "it is also tagged as example.com/app:1.0, example.com/app:1.1",
+		},
+		{
+			name:            "tags with the same digest",
+			args:            []string{"--force", "example.com/app:1.0", "example.com/app:1.1"},
+			expected:        "Untagged: example.com/app:1.0\nUntagged: example.com/app:1.1\nDeleted: example.com/app@" + oldDigest.String() + "\n",
+			expectedDeleted: []digest.Digest{oldDigest},
+		},
+		{
+			name:            "glob filter",
+			args:            []string{"--force", "--filter", "tag=1.*", "example.com/app"},
+			expected:        "Untagged: example.com/app:1.0\nUntagged: example.com/app:1.1\nDeleted: example.com/app@" + oldDigest.String() + "\n",
+			expectedDeleted: []digest.Digest{oldDigest},
+		},
+		{
+			name:            "until filter",
+			args:            []string{"--force", "--filter", "until=720h", "example.com/app"},
+			expected:        "Untagged: example.com/app:1.0\nUntagged: example.com/app:1.1\nDeleted: example.com/app@" + oldDigest.String() + "\n",
+			expectedDeleted: []digest.Digest{oldDigest},
+		},
+		{
+			name:     "until and semver filters",
+			args:     []string{"--filter", "until=720h", "--filter", "semver=>=2", "example.com/app"},
+			expected: "",
+		},
+		{
+			name:     "dry run",
+			args:     []string{"--dry-run", "--force", "--filter", "semver=2.x", "example.com/app"},
+			expected: "Would untag: example.com/app:2.0\nWould untag: example.com/app:latest\nWould delete: example.com/app@" + newDigest.String() + "\n",
+		},
+		{
+			name:        "dry run without force",
+			args:        []string{"--dry-run", "--filter", "semver=2.x", "example.com/app"},
+			expectedErr: "it is also tagged as example.com/app:latest",
+		},
+		{
+			name:        "repository without filter",
+			args:        []string{"example.com/app"},
+			expectedErr: "must contain a tag or digest, or --filter must be set",
+		},
+		{
+			name:        "filter with tag",
+			args:        []string{"--filter", "tag=1.*", "example.com/app:1.0"},
+			expectedErr: "--filter can only be used with a repository name",
+		},
+		{
+			name:        "invalid until filter",
+			args:        []string{"--filter", "until=last week", "example.com/app"},
+			expectedErr: `invalid until filter "last week"`,
+		},
+		{
+			name:        "invalid filter",
+			args:        []string{"--filter", "name=app", "example.com/app"},
+			expectedErr: "invalid filter 'name'",
+		},
+		{
+			name:            "unknown tag",
+			args:            []string{"--force", "example.com/app:missing", "example.com/app:2.0"},
+			expected:        "Untagged: example.com/app:2.0\nUntagged: example.com/app:latest\nDeleted: example.com/app@" + newDigest.String() + "\n",
+			expectedDeleted: []digest.Digest{newDigest},
+			expectedErr:     "no such manifest: example.com/app:missing",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var deleted []digest.Digest
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetRegistryClient(&fakeRegistryClient{
+				getTagsFunc: func(context.Context, reference.Named) ([]string, error) {
+					return []string{"1.0", "1.1", "2.0", "latest"}, nil
+				},
+				getRawManifestFunc: func(_ context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+					raw, ok := manifests[ref.(reference.Tagged).Tag()]
+					if !ok {
+						return ocispec.Descriptor{}, nil, errdefs.ErrNotFound.WithMessage("no such manifest: " + ref.String())
+					}
+					return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromBytes(raw)}, raw, nil
+				},
+				getBlobFunc: func(_ context.Context, _ reference.Named, dgst digest.Digest) ([]byte, error) {
+					return blobs[dgst], nil
+				},
+				deleteManifestFunc: func(_ context.Context, ref reference.Canonical) error {
+					deleted = append(deleted, ref.Digest())
+					return nil
+				},
+			})
+			cmd := newRmCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			err := cmd.Execute()
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+			} else {
+				assert.Check(t, err)
+			}
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+			assert.Check(t, is.DeepEqual(deleted, tc.expectedDeleted))
+		})
+	}
+}
+
+func TestRegistryRmPrompt(t *testing.T) {
+	manifest, _ := rmTestImage(t, time.Now())
+	dgst := digest.FromBytes(manifest)
+
+	for _, confirm := range []bool{true, false} {
+		t.Run(fmt.Sprintf("confirm=%t", confirm), func(t *testing.T) {
+			var deleted []digest.Digest
+			cli := test.NewFakeCli(&fakeClient{})
+			input := "n\n"
+			if confirm {
+				input = "y\n"
+			}
+			cli.SetIn(streams.NewIn(io.NopCloser(strings.NewReader(input))))
+			cli.SetRegistryClient(&fakeRegistryClient{
+				getTagsFunc: func(context.Context, reference.Named) ([]string, error) {
+					return []string{"1.0"}, nil
+				},
+				getRawManifestFunc: func(context.Context, reference.Named) (ocispec.Descriptor, []byte, error) {
+					return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: dgst}, manifest, nil
+				},
+				deleteManifestFunc: func(_ context.Context, ref reference.Canonical) error {
+					deleted = append(deleted, ref.Digest())
+					return nil
+				},
+			})
+			cmd := newRmCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs([]string{"example.com/app:1.0"})
+			err := cmd.Execute()
+
+			out := cli.OutBuffer().String()
+			assert.Check(t, is.Contains(out, "Would untag: example.com/app:1.0\nWould delete: example.com/app@"+dgst.String()+"\n"))
+			assert.Check(t, is.Contains(out, "Are you sure you want to continue?"))
+			if confirm {
+				assert.Check(t, err)
+				assert.Check(t, is.Contains(out, "Untagged: example.com/app:1.0\nDeleted: example.com/app@"+dgst.String()+"\n"))
+				assert.Check(t, is.DeepEqual(deleted, []digest.Digest{dgst}))
+			} else {
+				assert.Check(t, is.ErrorContains(err, "registry rm has been cancelled"))
+				assert.Check(t, is.Len(deleted, 0))
+			}
+		})
+	}
+}
+
+func TestRegistryRmUnsupported(t *testing.T) {
+	var calls int
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetRegistryClient(&fakeRegistryClient{
+		deleteManifestFunc: func(context.Context, reference.Canonical) error {
+			calls++
+			return errdefs.ErrNotImplemented.WithMessage("registry does not support deleting manifests")
+		},
+	})
+	cmd := newRmCommand(cli)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{
+		"--force",
+		"example.com/app@" + digest.FromString("one").String(),
+		"example.com/app@" + digest.FromString("two").String(),
+	})
+	err := cmd.Execute()
+	assert.Check(t, is.ErrorContains(err, "registry does not support deleting manifests: deleting must be enabled"))
+	assert.Check(t, errdefs.IsNotImplemented(err))
+	assert.Check(t, is.Equal(calls, 1))
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), ""))
+}
+
+func TestUntilFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	filters := make(map[string]map[string]bool)
+
+	until, err := untilFilter(filters, now)
+	assert.NilError(t, err)
+	assert.Check(t, until.IsZero())
+
+	filters["until"] = map[string]bool{"48h": true}
+	until, err = untilFilter(filters, now)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(until, now.Add(-48*time.Hour)))
+
+	filters["until"] = map[string]bool{"2024-01-02T03:04:05Z": true}
+	until, err = untilFilter(filters, now)
+	assert.NilError(t, err)
+	assert.Check(t, until.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	filters["until"] = map[string]bool{"48h": true, "2024-01-02": true}
+	_, err = untilFilter(filters, now)
+	assert.Check(t, is.Error(err, "the until filter can only be set once"))
+}
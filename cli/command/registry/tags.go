@@ -0,0 +1,294 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/completion"
+	"github.com/docker/cli/cli/command/formatter"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/docker/cli/opts"
+	"github.com/fvbommel/sortorder"
+	"github.com/moby/moby/client"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// Keys accepted by "docker registry tags --sort".
+const (
+	tagsSortName   = "name"
+	tagsSortSemver = "semver"
+)
+
+type tagsOptions struct {
+	repo     string
+	filter   opts.FilterOpt
+	sort     string
+	reverse  bool
+	limit    int
+	format   string
+	insecure bool
+}
+
+// newTagsCommand creates a new `docker registry tags` command
+func newTagsCommand(dockerCLI command.Cli) *cobra.Command {
+	options := tagsOptions{filter: opts.NewFilterOpt()}
+
+	cmd := &cobra.Command{
+		Use:   "tags [OPTIONS] REPOSITORY",
+		Short: "List the tags of a repository in a registry",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.repo = args[0]
+			return runTags(cmd.Context(), dockerCLI, options)
+		},
+		ValidArgsFunction:     cobra.NoFileCompletions,
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.VarP(&options.filter, "filter", "f", `Filter output based on conditions provided (e.g. "tag=1.*" or "semver=>=1.2 <2")`)
+	flags.StringVar(&options.sort, "sort", "", `Sort tags by "name" or "semver"`)
+	flags.BoolVar(&options.reverse, "reverse", false, "Reverse the sort order")
+	flags.IntVar(&options.limit, "limit", 0, "Maximum number of tags to show")
+	flags.StringVar(&options.format, "format", "", flagsHelper.FormatHelp)
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+
+	_ = cmd.RegisterFlagCompletionFunc("sort", completion.FromList(tagsSortName, tagsSortSemver))
+	return cmd
+}
+
+func runTags(ctx context.Context, dockerCLI command.Cli, options tagsOptions) error {
+	if options.limit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+	if options.sort != "" && options.sort != tagsSortName && options.sort != tagsSortSemver {
+		return fmt.Errorf("invalid sort key %q: must be %s or %s", options.sort, tagsSortName, tagsSortSemver)
+	}
+	if options.reverse && options.sort == "" {
+		return errors.New("--reverse requires --sort to be set")
+	}
+	match, err := tagsMatcher(options.filter.Value())
+	if err != nil {
+		return err
+	}
+
+	namedRef, err := reference.ParseNormalizedNamed(options.repo)
+	if err != nil {
+		return err
+	}
+	if !reference.IsNameOnly(namedRef) {
+		return fmt.Errorf("invalid repository name (%s): must not contain a tag or digest", options.repo)
+	}
+
+	tags, err := command.NewRegistryClient(dockerCLI, options.insecure).GetTags(ctx, namedRef)
+	if err != nil {
+		return err
+	}
+	tags = slices.DeleteFunc(tags, func(tag string) bool { return !match(tag) })
+	sortTags(tags, options.sort, options.reverse)
+	if options.limit > 0 && len(tags) > options.limit {
+		tags = tags[:options.limit]
+	}
+
+	tagsCtx := formatter.Context{
+		Output: dockerCLI.Out(),
+		Format: newTagsFormat(options.format),
+	}
+	return tagsFormatWrite(tagsCtx, reference.FamiliarName(namedRef), tags)
+}
+
+// tagsMatcher returns a function that returns whether a tag matches the
+// filters. Tags must match one of the "tag" filters, which are glob patterns,
+// and one of the "semver" filters, which are version ranges.
+func tagsMatcher(filters client.Filters) (func(string) bool, error) {
+	var (
+		patterns []string
+		ranges   []semverRange
+	)
+	for key, values := range filters {
+		switch key {
+		case "tag":
+			for p := range values {
+				if _, err := path.Match(p, ""); err != nil {
+					return nil, fmt.Errorf("invalid tag filter %q: %w", p, err)
+				}
+				patterns = append(patterns, p)
+			}
+		case "semver":
+			for v := range values {
+				r, err := parseSemverRange(v)
+				if err != nil {
+					return nil, err
+				}
+				ranges = append(ranges, r)
+			}
+		default:
+			return nil, fmt.Errorf("invalid filter '%s'", key)
+		}
+	}
+
+	return func(tag string) bool {
+		if len(patterns) > 0 && !slices.ContainsFunc(patterns, func(p string) bool {
+			ok, _ := path.Match(p, tag)
+			return ok
+		}) {
+			return false
+		}
+		if len(ranges) > 0 && !slices.ContainsFunc(ranges, func(r semverRange) bool {
+			return r.matches(tag)
+		}) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// sortTags sorts the tags by name, in natural order, or by semantic version,
+// highest version first. Tags that are not a semantic version are sorted
+// after those that are.
+func sortTags(tags []string, sortKey string, reverse bool) {
+	var cmpFunc func(a, b string) int
+	switch sortKey {
+	case tagsSortName:
+		cmpFunc = compareNatural
+	case tagsSortSemver:
+		cmpFunc = func(a, b string) int {
+			va, vb := semverOf(a), semverOf(b)
+			switch {
+			case va != "" && vb != "":
+				if n := semver.Compare(vb, va); n != 0 {
+					return n
+				}
+			case va != "":
+				return -1
+			case vb != "":
+				return 1
+			}
+			return compareNatural(a, b)
+		}
+	default:
+		return
+	}
+	slices.SortStableFunc(tags, func(a, b string) int {
+		if reverse {
+			return cmpFunc(b, a)
+		}
+		return cmpFunc(a, b)
+	})
+}
+
+func compareNatural(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case sortorder.NaturalLess(a, b):
+		return -1
+	default:
+		return 1
+	}
+}
+
+// semverOf returns the tag in the canonical form used by the semver package
+// (with a "v" prefix), or an empty string if the tag is not a semantic
+// version. Both "1.2.3" and "v1.2.3" are semantic versions.
+func semverOf(tag string) string {
+	v := tag
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
+
+// semverRange is a list of comparisons that a version must all satisfy.
+type semverRange []semverComparison
+
+type semverComparison struct {
+	op      string
+	version string
+}
+
+// parseSemverRange parses a version range, such as ">=1.2 <2", "1.4.x",
+// "~1.4.2", or "^1.4". Comparisons are separated by spaces or commas, and are
+// one of:
+//
+//   - "=", "<", "<=", ">", or ">=" followed by a version, which compare the
+//     version; a version without operator must be equal.
+//   - "~" followed by a version, which matches the same major and minor
+//     version, equal to or higher than the given version.
+//   - "^" followed by a version, which matches the same major version, equal
+//     to or higher than the given version.
+//   - a version ending with ".x" or ".*", which matches the same major (and
+//     minor) version.
+func parseSemverRange(value string) (semverRange, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid semver filter %q: no version range", value)
+	}
+	var r semverRange
+	for _, f := range fields {
+		op := strings.TrimRight(f[:min(len(f), 2)], "0123456789v.")
+		if !slices.Contains([]string{"", "=", "<", "<=", ">", ">=", "~", "^"}, op) {
+			return nil, fmt.Errorf("invalid semver filter %q: invalid operator %q", value, op)
+		}
+		v := f[len(op):]
+		if base, ok := strings.CutSuffix(v, ".x"); ok {
+			v, op = base, "x"
+		} else if base, ok := strings.CutSuffix(v, ".*"); ok {
+			v, op = base, "x"
+		}
+		version := semverOf(v)
+		if version == "" || (op == "x" && (len(f) != len(v)+2 || strings.Count(v, ".") > 1)) {
+			return nil, fmt.Errorf("invalid semver filter %q: invalid version %q", value, f)
+		}
+		r = append(r, semverComparison{op: op, version: version})
+	}
+	return r, nil
+}
+
+func (r semverRange) matches(tag string) bool {
+	v := semverOf(tag)
+	if v == "" {
+		return false
+	}
+	for _, c := range r {
+		n := semver.Compare(v, c.version)
+		var ok bool
+		switch c.op {
+		case "", "=":
+			ok = n == 0
+		case "<":
+			ok = n < 0
+		case "<=":
+			ok = n <= 0
+		case ">":
+			ok = n > 0
+		case ">=":
+			ok = n >= 0
+		case "~":
+			ok = n >= 0 && semver.MajorMinor(v) == semver.MajorMinor(c.version)
+		case "^":
+			ok = n >= 0 && semver.Major(v) == semver.Major(c.version)
+		case "x":
+			if strings.Count(c.version, ".") == 0 {
+				ok = semver.Major(v) == c.version
+			} else {
+				ok = semver.MajorMinor(v) == c.version
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestRegistryTags(t *testing.T) {
+	tags := []string{"latest", "1.9.0", "1.10.0", "1.10.1-rc1", "v2.0.0", "2.1", "edge", "1.2.3-alpine"}
+	testCases := []struct {
+		name        string
+		args        []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "default",
+			args:     []string{"example.com/app"},
+			expected: "TAG\nlatest\n1.9.0\n1.10.0\n1.10.1-rc1\nv2.0.0\n2.1\nedge\n1.2.3-alpine\n",
+		},
+		{
+			name:     "sort by name",
+			args:     []string{"--sort", "name", "example.com/app"},
+			expected: "TAG\n1.2.3-alpine\n1.9.0\n1.10.0\n1.10.1-rc1\n2.1\nedge\nlatest\nv2.0.0\n",
+		},
+		{
+			name:     "sort by semver",
+			args:     []string{"--sort", "semver", "example.com/app"},
+			expected: "TAG\n2.1\nv2.0.0\n1.10.1-rc1\n1.10.0\n1.9.0\n1.2.3-alpine\nedge\nlatest\n",
+		},
+		{
+			name:     "sort by semver reversed with limit",
+			args:     []string{"--sort", "semver", "--reverse", "--limit", "2", "example.com/app"},
+			expected: "TAG\nlatest\nedge\n",
+		},
+		{
+			name:     "glob filter",
+			args:     []string{"--filter", "tag=1.*", "--filter", "tag=*-alpine", "example.com/app"},
+			expected: "TAG\n1.9.0\n1.10.0\n1.10.1-rc1\n1.2.3-alpine\n",
+		},
+		{
+			name:     "semver range",
+			args:     []string{"--filter", "semver=>=1.10 <2", "--sort", "semver", "example.com/app"},
+			expected: "TAG\n1.10.1-rc1\n1.10.0\n",
+		},
+		{
+			name:     "semver wildcard and glob",
+			args:     []string{"--filter", "semver=2.x", "--filter", "tag=v*", "example.com/app"},
+			expected: "TAG\nv2.0.0\n",
+		},
+		{
+			name:     "json",
+			args:     []string{"--format", "json", "--filter", "tag=latest", "example.com/app"},
+			expected: `{"Repository":"example.com/app","Tag":"latest"}` + "\n",
+		},
+		{
+			name:        "invalid filter",
+			args:        []string{"--filter", "name=foo", "example.com/app"},
+			expectedErr: "invalid filter 'name'",
+		},
+		{
+			name:        "invalid semver range",
+			args:        []string{"--filter", "semver=>=latest", "example.com/app"},
+			expectedErr: `invalid semver filter ">=latest"`,
+		},
+		{
+			name:        "invalid sort",
+			args:        []string{"--sort", "size", "example.com/app"},
+			expectedErr: `invalid sort key "size"`,
+		},
+		{
+			name:        "reverse without sort",
+			args:        []string{"--reverse", "example.com/app"},
+			expectedErr: "--reverse requires --sort to be set",
+		},
+		{
+			name:        "tagged reference",
+			args:        []string{"example.com/app:latest"},
+			expectedErr: "must not contain a tag or digest",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetRegistryClient(&fakeRegistryClient{
+				getTagsFunc: func(_ context.Context, ref reference.Named) ([]string, error) {
+					assert.Check(t, is.Equal(ref.String(), "example.com/app"))
+					return append([]string(nil), tags...), nil
+				},
+			})
+			cmd := newTagsCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			err := cmd.Execute()
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+		})
+	}
+}
+
+func TestParseSemverRange(t *testing.T) {
+	testCases := []struct {
+		value   string
+		matches []string
+		misses  []string
+	}{
+		{value: "1.2.3", matches: []string{"1.2.3", "v1.2.3"}, misses: []string{"1.2.4", "latest"}},
+		{value: ">1.2, <=2", matches: []string{"1.2.1", "2.0.0"}, misses: []string{"1.2.0", "2.0.1"}},
+		{value: "~1.4.2", matches: []string{"1.4.2", "1.4.9"}, misses: []string{"1.4.1", "1.5.0"}},
+		{value: "^1.4", matches: []string{"1.4.0", "1.9.9"}, misses: []string{"1.3.9", "2.0.0"}},
+		{value: "1.4.*", matches: []string{"1.4.0", "1.4.10"}, misses: []string{"1.5.0", "2.4.0"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			r, err := parseSemverRange(tc.value)
+			assert.NilError(t, err)
+			for _, v := range tc.matches {
+				assert.Check(t, r.matches(v), "expected %s to match", v)
+			}
+			for _, v := range tc.misses {
+				assert.Check(t, !r.matches(v), "expected %s not to match", v)
+			}
+		})
+	}
+
+	for _, value := range []string{"", "latest", "!1.0", ">=1.x", "1.2.3.x"} {
+		_, err := parseSemverRange(value)
+		assert.Check(t, err != nil, "expected %q to be invalid", value)
+	}
+}
@@ -29,6 +29,7 @@ func newServiceCommand(dockerCLI command.Cli) *cobra.Command {
 		newInspectCommand(dockerCLI),
 		newPsCommand(dockerCLI),
 		newListCommand(dockerCLI),
+		newOutdatedCommand(dockerCLI),
 		newRemoveCommand(dockerCLI),
 		newScaleCommand(dockerCLI),
 		newUpdateCommand(dockerCLI),
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command/formatter"
+)
+
+const (
+	defaultOutdatedTableFormat = "table {{.ID}}\t{{.Name}}\t{{.Image}}\t{{.Status}}"
+
+	outdatedIDHeader           = "ID"
+	outdatedNameHeader         = "NAME"
+	outdatedImageHeader        = "IMAGE"
+	outdatedDigestHeader       = "DIGEST"
+	outdatedRemoteDigestHeader = "REMOTE DIGEST"
+	outdatedStatusHeader       = "STATUS"
+)
+
+// newOutdatedFormat returns a Format for rendering using an outdatedContext.
+func newOutdatedFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultOutdatedTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// outdatedFormatWrite writes the services, and how their image compares to
+// their registry, using the context.
+func outdatedFormatWrite(fmtCtx formatter.Context, services []outdatedService) error {
+	outdatedCtx := &outdatedContext{
+		HeaderContext: formatter.HeaderContext{
+			Header: formatter.SubHeaderContext{
+				"ID":           outdatedIDHeader,
+				"Name":         outdatedNameHeader,
+				"Image":        outdatedImageHeader,
+				"Digest":       outdatedDigestHeader,
+				"RemoteDigest": outdatedRemoteDigestHeader,
+				"Status":       outdatedStatusHeader,
+			},
+		},
+	}
+	return fmtCtx.Write(outdatedCtx, func(format func(subContext formatter.SubContext) error) error {
+		for _, s := range services {
+			if err := format(&outdatedContext{trunc: fmtCtx.Trunc, s: s}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type outdatedContext struct {
+	formatter.HeaderContext
+	trunc bool
+	s     outdatedService
+}
+
+func (c *outdatedContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *outdatedContext) ID() string {
+	if c.trunc {
+		return formatter.TruncateID(c.s.service.ID)
+	}
+	return c.s.service.ID
+}
+
+func (c *outdatedContext) Name() string {
+	return c.s.service.Spec.Name
+}
+
+// Image returns the tag of the image of the service, or the image as set in
+// its spec if it has no tag.
+func (c *outdatedContext) Image() string {
+	if c.s.tagged == nil {
+		return c.s.image()
+	}
+	return reference.FamiliarString(c.s.tagged)
+}
+
+// Digest returns the digest that the image of the service is pinned to.
+func (c *outdatedContext) Digest() string {
+	return c.s.pinned.String()
+}
+
+// RemoteDigest returns the digest that the tag resolves to in the registry.
+func (c *outdatedContext) RemoteDigest() string {
+	return c.s.remote.String()
+}
+
+func (c *outdatedContext) Status() string {
+	return c.s.status
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	flagsHelper "github.com/docker/cli/cli/flags"
+	"github.com/docker/cli/opts"
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/moby/moby/client"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// Status of the image of a service, compared to the image in its registry.
+const (
+	outdatedStatusOutdated = "outdated"
+	outdatedStatusCurrent  = "current"
+	outdatedStatusMissing  = "missing" // the tag does not exist in the registry
+	outdatedStatusUnknown  = "unknown" // the image is not pinned to a digest, or has no tag
+)
+
+type outdatedOptions struct {
+	services       []string
+	filter         opts.FilterOpt
+	all            bool
+	format         string
+	printCommands  bool
+	update         bool
+	registryAuth   bool
+	insecure       bool
+	maxConcurrency int
+}
+
+func newOutdatedCommand(dockerCLI command.Cli) *cobra.Command {
+	options := outdatedOptions{filter: opts.NewFilterOpt()}
+
+	cmd := &cobra.Command{
+		Use:   "outdated [OPTIONS] [SERVICE...]",
+		Short: "List services whose image is outdated compared to its registry",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.services = args
+			return runOutdated(cmd.Context(), dockerCLI, options)
+		},
+		ValidArgsFunction:     completeServiceNames(dockerCLI),
+		DisableFlagsInUseLine: true,
+	}
+
+	flags := cmd.Flags()
+	flags.VarP(&options.filter, "filter", "f", "Filter services based on conditions provided")
+	flags.BoolVarP(&options.all, "all", "a", false, "Show all services, including those that are up to date")
+	flags.StringVar(&options.format, "format", "", flagsHelper.FormatHelp)
+	flags.BoolVar(&options.printCommands, "print-commands", false, `Print the "docker service update" commands to update the outdated services`)
+	flags.BoolVar(&options.update, "update", false, "Update the outdated services to the image that their tag currently resolves to")
+	flags.BoolVar(&options.registryAuth, flagRegistryAuth, false, "Send registry authentication details to swarm agents (with --update)")
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+	flags.IntVar(&options.maxConcurrency, "max-concurrent-requests", 4, "Maximum number of concurrent requests to registries")
+
+	_ = cmd.RegisterFlagCompletionFunc("filter", completeServiceListFilters(dockerCLI))
+	return cmd
+}
+
+// outdatedService is a service, the tag and digest of its image, and the
+// digest that the tag resolves to in its registry.
+type outdatedService struct {
+	service swarm.Service
+	tagged  reference.NamedTagged // nil if the image has no tag
+	pinned  digest.Digest         // empty if the image is not pinned to a digest
+	remote  digest.Digest
+	status  string
+}
+
+// image returns the image of the service, as set in its spec.
+func (s outdatedService) image() string {
+	return s.service.Spec.TaskTemplate.ContainerSpec.Image
+}
+
+// remoteImage returns the tag of the image of the service, pinned to the
+// digest that it resolves to in the registry.
+func (s outdatedService) remoteImage() string {
+	if s.tagged == nil || s.remote == "" {
+		return ""
+	}
+	return reference.FamiliarString(s.tagged) + "@" + s.remote.String()
+}
+
+func runOutdated(ctx context.Context, dockerCLI command.Cli, options outdatedOptions) error {
+	switch {
+	case options.maxConcurrency < 1:
+		return errors.New("--max-concurrent-requests must be at least 1")
+	case options.printCommands && options.update:
+		return errors.New("--print-commands and --update cannot be combined")
+	case options.registryAuth && !options.update:
+		return errors.New("--" + flagRegistryAuth + " can only be used with --update")
+	}
+
+	filters := options.filter.Value().Clone()
+	if len(options.services) > 0 {
+		filters.Add("name", options.services...)
+	}
+	apiClient := dockerCLI.Client()
+	res, err := apiClient.ServiceList(ctx, client.ServiceListOptions{Filters: filters})
+	if err != nil {
+		return err
+	}
+	services := make([]outdatedService, 0, len(res.Items))
+	for _, service := range res.Items {
+		services = append(services, parseServiceImage(service))
+	}
+	slices.SortFunc(services, func(a, b outdatedService) int {
+		return strings.Compare(a.service.Spec.Name, b.service.Spec.Name)
+	})
+
+	registryClient := command.NewRegistryClient(dockerCLI, options.insecure)
+	var (
+		mu   sync.Mutex
+		errs []error
+		eg   errgroup.Group
+	)
+	eg.SetLimit(options.maxConcurrency)
+	for i := range services {
+		s := &services[i]
+		if s.tagged == nil || s.pinned == "" {
+			s.status = outdatedStatusUnknown
+			continue
+		}
+		eg.Go(func() error {
+			desc, _, err := registryClient.GetUpstreamManifest(ctx, s.tagged)
+			switch {
+			case errdefs.IsNotFound(err):
+				s.status = outdatedStatusMissing
+			case err != nil:
+				s.status = outdatedStatusUnknown
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("failed to check the image of service %s: %w", s.service.Spec.Name, err))
+				mu.Unlock()
+			case desc.Digest == s.pinned:
+				s.remote = desc.Digest
+				s.status = outdatedStatusCurrent
+			default:
+				s.remote = desc.Digest
+				s.status = outdatedStatusOutdated
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	var outdated []outdatedService
+	for _, s := range services {
+		if s.status == outdatedStatusOutdated {
+			outdated = append(outdated, s)
+		}
+	}
+
+	switch {
+	case options.printCommands:
+		for _, s := range outdated {
+			_, _ = fmt.Fprintf(dockerCLI.Out(), "docker service update --image %s %s\n", s.remoteImage(), s.service.Spec.Name)
+		}
+	case options.update:
+		for _, s := range outdated {
+			if err := updateServiceImage(ctx, dockerCLI, s.service.ID, s.remoteImage(), options.registryAuth); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update service %s: %w", s.service.Spec.Name, err))
+				continue
+			}
+			_, _ = fmt.Fprintln(dockerCLI.Out(), s.service.Spec.Name)
+		}
+	default:
+		if !options.all {
+			services = outdated
+		}
+		outdatedCtx := formatter.Context{
+			Output: dockerCLI.Out(),
+			Format: newOutdatedFormat(options.format),
+			Trunc:  true,
+		}
+		if err := outdatedFormatWrite(outdatedCtx, services); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseServiceImage returns the tag and digest of the image of the service.
+// Services that are deployed with the default "docker service create" and
+// "docker stack deploy" options have their image pinned to the digest that
+// the tag resolved to at deploy time, such as "nginx:stable@sha256:...".
+func parseServiceImage(service swarm.Service) outdatedService {
+	s := outdatedService{service: service}
+	namedRef, err := reference.ParseNormalizedNamed(s.image())
+	if err != nil {
+		return s
+	}
+	if tagged, ok := namedRef.(reference.Tagged); ok {
+		s.tagged, _ = reference.WithTag(reference.TrimNamed(namedRef), tagged.Tag())
+	}
+	if canonical, ok := namedRef.(reference.Canonical); ok {
+		s.pinned = canonical.Digest()
+	}
+	return s
+}
+
+// updateServiceImage updates the image of the service, without waiting for
+// the update to converge.
+func updateServiceImage(ctx context.Context, dockerCLI command.Cli, serviceID string, image string, registryAuth bool) error {
+	apiClient := dockerCLI.Client()
+	res, err := apiClient.ServiceInspect(ctx, serviceID, client.ServiceInspectOptions{})
+	if err != nil {
+		return err
+	}
+	spec := res.Service.Spec
+	spec.TaskTemplate.ContainerSpec.Image = image
+
+	updateOpts := client.ServiceUpdateOptions{
+		Version: res.Service.Version,
+		Spec:    spec,
+	}
+	if registryAuth {
+		updateOpts.EncodedRegistryAuth, err = command.RetrieveAuthTokenFromImage(dockerCLI.ConfigFile(), image)
+		if err != nil {
+			return err
+		}
+	}
+	response, err := apiClient.ServiceUpdate(ctx, res.Service.ID, updateOpts)
+	if err != nil {
+		return err
+	}
+	for _, warning := range response.Warnings {
+		_, _ = fmt.Fprintln(dockerCLI.Err(), warning)
+	}
+	return nil
+}
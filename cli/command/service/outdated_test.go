@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/internal/registryclient"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/cli/internal/test/builders"
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/moby/moby/client"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type fakeRegistryClient struct {
+	registryclient.RegistryClient
+	digests map[string]digest.Digest // by tag
+}
+
+func (c *fakeRegistryClient) GetUpstreamManifest(_ context.Context, ref reference.Named, _ ...string) (ocispec.Descriptor, []byte, error) {
+	if ref.String() == "example.com/broken:1" {
+		return ocispec.Descriptor{}, nil, errors.New("connection refused")
+	}
+	dgst, ok := c.digests[ref.String()]
+	if !ok {
+		return ocispec.Descriptor{}, nil, errdefs.ErrNotFound.WithMessage("no such manifest: " + ref.String())
+	}
+	return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, Digest: dgst}, nil, nil
+}
+
+func TestServiceOutdated(t *testing.T) {
+	oldDigest, newDigest := digest.FromString("old"), digest.FromString("new")
+	registry := &fakeRegistryClient{digests: map[string]digest.Digest{
+		"docker.io/library/nginx:stable": newDigest,
+		"docker.io/library/redis:7":      oldDigest,
+	}}
+	services := []swarm.Service{
+		*builders.Service(builders.ServiceID("aaaaaaaaaaaaaaaaaaaaaaaaa"), builders.ServiceName("web"), builders.ServiceImage("nginx:stable@"+oldDigest.String())),
+		*builders.Service(builders.ServiceID("bbbbbbbbbbbbbbbbbbbbbbbbb"), builders.ServiceName("cache"), builders.ServiceImage("redis:7@"+oldDigest.String())),
+		*builders.Service(builders.ServiceID("ccccccccccccccccccccccccc"), builders.ServiceName("app"), builders.ServiceImage("example.com/app:1")),
+		*builders.Service(builders.ServiceID("ddddddddddddddddddddddddd"), builders.ServiceName("gone"), builders.ServiceImage("example.com/gone:1@"+oldDigest.String())),
+	}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		expected string
+		updated  map[string]string
+	}{
+		{
+			name: "outdated",
+			expected: `ID             NAME      IMAGE          STATUS
+aaaaaaaaaaaa   web       nginx:stable   outdated
+`,
+		},
+		{
+			name: "all",
+			args: []string{"--all", "--format", "{{.Name}} {{.Status}}"},
+			expected: "app unknown\n" +
+				"cache current\n" +
+				"gone missing\n" +
+				"web outdated\n",
+		},
+		{
+			name:     "print commands",
+			args:     []string{"--print-commands"},
+			expected: "docker service update --image nginx:stable@" + newDigest.String() + " web\n",
+		},
+		{
+			name:     "update",
+			args:     []string{"--update"},
+			expected: "web\n",
+			updated:  map[string]string{"aaaaaaaaaaaaaaaaaaaaaaaaa": "nginx:stable@" + newDigest.String()},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			updated := make(map[string]string)
+			cli := test.NewFakeCli(&fakeClient{
+				serviceListFunc: func(context.Context, client.ServiceListOptions) (client.ServiceListResult, error) {
+					return client.ServiceListResult{Items: services}, nil
+				},
+				serviceInspectFunc: func(_ context.Context, serviceID string, _ client.ServiceInspectOptions) (client.ServiceInspectResult, error) {
+					for _, s := range services {
+						if s.ID == serviceID {
+							return client.ServiceInspectResult{Service: s}, nil
+						}
+					}
+					return client.ServiceInspectResult{}, errdefs.ErrNotFound
+				},
+				serviceUpdateFunc: func(_ context.Context, serviceID string, options client.ServiceUpdateOptions) (client.ServiceUpdateResult, error) {
+					updated[serviceID] = options.Spec.TaskTemplate.ContainerSpec.Image
+					return client.ServiceUpdateResult{}, nil
+				},
+			})
+			cli.SetRegistryClient(registry)
+			cmd := newOutdatedCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			assert.NilError(t, cmd.Execute())
+			assert.Check(t, is.Equal(cli.OutBuffer().String(), tc.expected))
+			if tc.updated == nil {
+				tc.updated = map[string]string{}
+			}
+			assert.Check(t, is.DeepEqual(updated, tc.updated))
+		})
+	}
+}
+
+func TestServiceOutdatedErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{
+			name:        "print commands and update",
+			args:        []string{"--print-commands", "--update"},
+			expectedErr: "--print-commands and --update cannot be combined",
+		},
+		{
+			name:        "registry auth without update",
+			args:        []string{"--with-registry-auth"},
+			expectedErr: "--with-registry-auth can only be used with --update",
+		},
+		{
+			name:        "registry error",
+			args:        []string{"broken"},
+			expectedErr: "failed to check the image of service broken: connection refused",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{
+				serviceListFunc: func(_ context.Context, options client.ServiceListOptions) (client.ServiceListResult, error) {
+					assert.Check(t, is.DeepEqual(options.Filters, client.Filters{"name": {"broken": true}}))
+					return client.ServiceListResult{Items: []swarm.Service{
+						*builders.Service(builders.ServiceName("broken"), builders.ServiceImage("example.com/broken:1@"+digest.FromString("x").String())),
+					}}, nil
+				},
+			})
+			cli.SetRegistryClient(&fakeRegistryClient{})
+			cmd := newOutdatedCommand(cli)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+			assert.Check(t, is.ErrorContains(cmd.Execute(), tc.expectedErr))
+		})
+	}
+}
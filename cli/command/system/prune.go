@@ -27,6 +27,7 @@ type pruneOptions struct {
 	all          bool
 	pruneVolumes bool
 	filter       opts.FilterOpt
+	imagePolicy  string
 }
 
 // newPruneCommand creates a new cobra.Command for `docker prune`
@@ -52,6 +53,7 @@ func newPruneCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.Var(&options.filter, "filter", `Provide filter values (e.g. "label=<key>=<value>")`)
 	// "filter" flag is available in 1.28 (docker 17.04) and up
 	flags.SetAnnotation("filter", "version", []string{"1.28"})
+	flags.StringVar(&options.imagePolicy, "image-policy", "", "Prune images according to the retention policy in the given JSON file")
 
 	return cmd
 }
@@ -102,9 +104,10 @@ func runPrune(ctx context.Context, dockerCli command.Cli, options pruneOptions)
 		}
 
 		spc, output, err := pruneFn(ctx, dockerCli, pruner.PruneOptions{
-			Confirmed: confirmed,
-			All:       options.all,
-			Filter:    options.filter,
+			Confirmed:   confirmed,
+			All:         options.all,
+			Filter:      options.filter,
+			ImagePolicy: options.imagePolicy,
 		})
 		if err != nil && !errdefs.IsNotImplemented(err) {
 			return err
@@ -146,8 +149,9 @@ func dryRun(ctx context.Context, dockerCli command.Cli, options pruneOptions) (s
 		// to perform validation of the given options and produce
 		// a confirmation message for the pruner.
 		_, confirmMsg, err := pruneFn(ctx, dockerCli, pruner.PruneOptions{
-			All:    options.all,
-			Filter: options.filter,
+			All:         options.all,
+			Filter:      options.filter,
+			ImagePolicy: options.imagePolicy,
 		})
 		// A "canceled" error is expected in dry-run mode; any other error
 		// must be returned as a "fatal" error.
@@ -77,6 +77,11 @@ type PruneOptions struct {
 	Confirmed bool
 	All       bool // Remove all unused content not just dangling (exact meaning differs per content-type).
 	Filter    opts.FilterOpt
+
+	// ImagePolicy is the path to a retention policy file for images. If
+	// set, images that are not retained by the policy are pruned instead
+	// of unused images.
+	ImagePolicy string
 }
 
 // registered holds a map of PruneFunc functions registered through [Register].
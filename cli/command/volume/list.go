@@ -6,6 +6,7 @@
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/completion"
 	"github.com/docker/cli/cli/command/formatter"
 	flagsHelper "github.com/docker/cli/cli/flags"
 	"github.com/docker/cli/opts"
@@ -23,10 +24,12 @@ type listOptions struct {
 	format  string
 	cluster bool
 	filter  opts.FilterOpt
+	sort    string
+	reverse bool
 }
 
 func newListCommand(dockerCLI command.Cli) *cobra.Command {
-	options := listOptions{filter: opts.NewFilterOpt()}
+	options := listOptions{filter: opts.NewFilterOptWithComparisons()}
 
 	cmd := &cobra.Command{
 		Use:     "ls [OPTIONS]",
@@ -47,11 +50,23 @@ func newListCommand(dockerCLI command.Cli) *cobra.Command {
 	flags.BoolVar(&options.cluster, "cluster", false, "Display only cluster volumes, and use cluster volume list formatting")
 	_ = flags.SetAnnotation("cluster", "version", []string{"1.42"})
 	_ = flags.SetAnnotation("cluster", "swarm", []string{"manager"})
+	flags.StringVar(&options.sort, "sort", "", `Sort output by "size", "created", or "name"`)
+	flags.BoolVar(&options.reverse, "reverse", false, "Reverse the sort order")
 
+	_ = cmd.RegisterFlagCompletionFunc("sort", completion.FromList(formatter.SortKeys...))
 	return cmd
 }
 
 func runList(ctx context.Context, dockerCLI command.Cli, options listOptions) error {
+	sortOptions := formatter.ListOptions{
+		Sort:        options.sort,
+		Reverse:     options.reverse,
+		Comparisons: options.filter.Comparisons(),
+	}
+	if err := sortOptions.Validate(); err != nil {
+		return err
+	}
+
 	apiClient := dockerCLI.Client()
 	res, err := apiClient.VolumeList(ctx, client.VolumeListOptions{Filters: options.filter.Value()})
 	if err != nil {
@@ -88,10 +103,14 @@ func runList(ctx context.Context, dockerCLI command.Cli, options listOptions) er
 	sort.Slice(res.Items, func(i, j int) bool {
 		return sortorder.NaturalLess(res.Items[i].Name, res.Items[j].Name)
 	})
+	volumes, err := formatter.FilterAndSortVolumes(res.Items, sortOptions)
+	if err != nil {
+		return err
+	}
 
 	volumeCtx := formatter.Context{
 		Output: dockerCLI.Out(),
 		Format: formatter.NewVolumeFormat(format, options.quiet),
 	}
-	return formatter.VolumeWrite(volumeCtx, res.Items)
+	return formatter.VolumeWrite(volumeCtx, volumes)
 }
@@ -11,6 +11,7 @@
 	"github.com/moby/moby/api/types/volume"
 	"github.com/moby/moby/client"
 	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/golden"
 )
 
@@ -234,3 +235,21 @@ func TestClusterVolumeList(t *testing.T) {
 	assert.NilError(t, cmd.Execute())
 	golden.Assert(t, cli.OutBuffer().String(), "volume-cluster-volume-list.golden")
 }
+
+func TestVolumeListSortByCreated(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{
+		volumeListFunc: func(client.VolumeListOptions) (client.VolumeListResult, error) {
+			return client.VolumeListResult{
+				Items: []volume.Volume{
+					{Name: "volume-a", CreatedAt: "2024-01-01T00:00:00Z"},
+					{Name: "volume-b", CreatedAt: "2024-03-01T00:00:00Z"},
+					{Name: "volume-c", CreatedAt: "2024-02-01T00:00:00Z"},
+				},
+			}, nil
+		},
+	})
+	cmd := newListCommand(cli)
+	cmd.SetArgs([]string{"--sort", "created", "--filter", "created<2024-02-15", "--format", "{{ .Name }}"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal(cli.OutBuffer().String(), "volume-c\nvolume-a\n"))
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -35,6 +36,40 @@
 	configDir     string
 )
 
+// systemConfigDir is the directory of the system-wide configuration file,
+// which holds defaults for all users.
+var systemConfigDir = func() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "docker", "cli")
+	}
+	return "/etc/docker/cli"
+}()
+
+// projectIgnoredFields are the fields that are ignored in the configuration
+// file of a project, as projects may come from untrusted sources, such as a
+// cloned repository. They would otherwise allow a project to run programs,
+// such as plugin hooks, to redirect registry or daemon traffic, or to read
+// credentials.
+var projectIgnoredFields = []string{
+	"aliases",
+	"auths",
+	"cliPluginsExtraDirs",
+	"contextProfiles",
+	"credHelpers",
+	"credsStore",
+	"currentContext",
+	"currentProfile",
+	"features",
+	"insecureRegistries",
+	"plugins",
+	"profiles",
+	"proxies",
+	"registryMirrors",
+	"registryOIDC",
+	"registryRewrites",
+	"registryTLS",
+}
+
 // resetConfigDir is used in testing to reset the "configDir" package variable
 // and its sync.Once to force re-lookup between tests.
 func resetConfigDir() {
@@ -152,12 +187,105 @@ func load(configDir string) (*configfile.ConfigFile, error) {
 	return configFile, err
 }
 
+// loadLayers merges the system-wide configuration file, and the configuration
+// file of the project that the CLI is run in, with the given config file. The
+// project configuration file takes precedence over the config file, which
+// takes precedence over the system-wide configuration file.
+//
+// Layers that cannot be read are skipped, and returned as an error.
+func loadLayers(configFile *configfile.ConfigFile) error {
+	var layers []*configfile.Layer
+	var errs []error
+	files := []struct {
+		origin, filename string
+		above            bool
+		ignore           []string
+	}{
+		{origin: configfile.OriginSystem, filename: filepath.Join(systemConfigDir, ConfigFileName)},
+		{origin: configfile.OriginProject, filename: findProjectConfig(configFile.Filename), above: true, ignore: projectIgnoredFields},
+	}
+	for _, f := range files {
+		if f.filename == "" {
+			continue
+		}
+		l, err := loadLayer(f.origin, f.filename, f.above, f.ignore)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if l != nil {
+			layers = append(layers, l)
+		}
+	}
+	if err := configFile.SetLayers(layers...); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// loadLayer reads a configuration file to merge with the config file. It
+// returns nil if the file does not exist.
+func loadLayer(origin, filename string, above bool, ignore []string) (*configfile.Layer, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("loading %s config file: %w", origin, err)
+	}
+	defer func() { _ = file.Close() }()
+	l, err := configfile.LoadLayer(origin, filename, above, file, ignore...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s config file (%s): %w", origin, filename, err)
+	}
+	return l, nil
+}
+
+// findProjectConfig returns the configuration file of the project that the
+// CLI is run in: the ".docker/config.json" file in the working directory or
+// the closest of its parent directories. The given user's config file, and
+// the config file in the home directory, are not considered.
+func findProjectConfig(userFile string) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	skip := []string{userFile, filepath.Join(getHomeDir(), configFileDir, ConfigFileName)}
+	for {
+		filename := filepath.Join(dir, configFileDir, ConfigFileName)
+		if fi, err := os.Stat(filename); err == nil && fi.Mode().IsRegular() && !isAnyFile(fi, skip) {
+			return filename
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// isAnyFile returns whether fi describes any of the given files.
+func isAnyFile(fi os.FileInfo, filenames []string) bool {
+	for _, fn := range filenames {
+		if other, err := os.Stat(fn); err == nil && os.SameFile(fi, other) {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadDefaultConfigFile attempts to load the default config file and returns
 // a reference to the ConfigFile struct. If none is found or when failing to load
 // the configuration file, it initializes a default ConfigFile struct. If no
 // credentials-store is set in the configuration file, it attempts to discover
 // the default store to use for the current platform.
 //
+// The system-wide configuration file ("/etc/docker/cli/config.json"), and the
+// configuration file of the project that the CLI is run in (".docker/config.json"
+// in the working directory or one of its parents) are merged with the config
+// file; see [configfile.ConfigFile.SetLayers]. Changes are only saved to the
+// default config file.
+//
 // Important: LoadDefaultConfigFile prints a warning to stderr when failing to
 // load the configuration file, but otherwise ignores errors. Consumers should
 // consider using [Load] (and [credentials.DetectDefaultStore]) to detect errors
@@ -169,6 +297,14 @@ func LoadDefaultConfigFile(stderr io.Writer) *configfile.ConfigFile {
 		// FIXME(thaJeztah): we should not proceed here to prevent overwriting existing (but malformed) config files; see https://github.com/docker/cli/issues/5075
 		_, _ = fmt.Fprintln(stderr, "WARNING: Error", err)
 	}
+	if err := loadLayers(configFile); err != nil {
+		_, _ = fmt.Fprintln(stderr, "WARNING: Error", err)
+	}
+	for _, l := range configFile.Layers() {
+		if len(l.Ignored) > 0 {
+			_, _ = fmt.Fprintf(stderr, "WARNING: Ignoring %s in %s config file (%s)\n", strings.Join(l.Ignored, ", "), l.Origin, l.Filename)
+		}
+	}
 	if !configFile.ContainsAuth() {
 		configFile.CredentialsStore = credentials.DetectDefaultStore(configFile.CredentialsStore)
 	}
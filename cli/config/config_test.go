@@ -11,6 +11,7 @@
 
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/config/credentials"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/skip"
@@ -378,7 +379,7 @@ func TestLoadDefaultConfigFile(t *testing.T) {
 		expected.CredentialsStore = credStore
 		expected.PsFormat = "format"
 
-		assert.Check(t, is.DeepEqual(expected, configFile))
+		assert.Check(t, is.DeepEqual(expected, configFile, cmpopts.IgnoreUnexported(configfile.ConfigFile{})))
 		assert.Check(t, is.Equal(buffer.String(), ""))
 	})
 
@@ -398,6 +399,79 @@ func TestLoadDefaultConfigFile(t *testing.T) {
 	})
 }
 
+func TestLoadDefaultConfigFileLayers(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(home, ".docker")
+	assert.NilError(t, os.Mkdir(dir, 0o700))
+	oldDir := Dir()
+	SetDir(dir)
+	defer SetDir(oldDir)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`{"psFormat": "user", "imagesFormat": "user"}`), 0o600))
+
+	systemDir := t.TempDir()
+	oldSystemConfigDir := systemConfigDir
+	systemConfigDir = systemDir
+	defer func() { systemConfigDir = oldSystemConfigDir }()
+	assert.NilError(t, os.WriteFile(filepath.Join(systemDir, ConfigFileName), []byte(`{"psFormat": "system", "statsFormat": "system"}`), 0o644))
+
+	project := t.TempDir()
+	assert.NilError(t, os.Mkdir(filepath.Join(project, ".docker"), 0o755))
+	projectFile := filepath.Join(project, ".docker", ConfigFileName)
+	assert.NilError(t, os.WriteFile(projectFile, []byte(`{"imagesFormat": "project", "credsStore": "project"}`), 0o644))
+	assert.NilError(t, os.MkdirAll(filepath.Join(project, "sub", "dir"), 0o755))
+	t.Chdir(filepath.Join(project, "sub", "dir"))
+
+	buffer := new(bytes.Buffer)
+	configFile := LoadDefaultConfigFile(buffer)
+	assert.Check(t, is.Equal(configFile.PsFormat, "user"))
+	assert.Check(t, is.Equal(configFile.StatsFormat, "system"))
+	assert.Check(t, is.Equal(configFile.ImagesFormat, "project"))
+	assert.Check(t, is.Equal(configFile.CredentialsStore, credentials.DetectDefaultStore("")))
+	assert.Check(t, is.Equal(buffer.String(), "WARNING: Ignoring credsStore in project config file ("+projectFile+")\n"))
+
+	// The user's config file is not a project config file.
+	t.Chdir(home)
+	buffer.Reset()
+	configFile = LoadDefaultConfigFile(buffer)
+	assert.Check(t, is.Len(configFile.Layers(), 1))
+	assert.Check(t, is.Equal(buffer.String(), ""))
+}
+
+func TestLoadDefaultConfigFileProjectIgnored(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(home, ".docker")
+	assert.NilError(t, os.Mkdir(dir, 0o700))
+	oldDir := Dir()
+	SetDir(dir)
+	defer SetDir(oldDir)
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`{"currentContext": "default"}`), 0o600))
+
+	oldSystemConfigDir := systemConfigDir
+	systemConfigDir = t.TempDir()
+	defer func() { systemConfigDir = oldSystemConfigDir }()
+
+	// A project must not be able to switch to another context, such as a
+	// production daemon, or to enable plugin hooks.
+	project := t.TempDir()
+	assert.NilError(t, os.Mkdir(filepath.Join(project, ".docker"), 0o755))
+	projectFile := filepath.Join(project, ".docker", ConfigFileName)
+	assert.NilError(t, os.WriteFile(projectFile, []byte(`{
+	"imagesFormat": "project",
+	"currentContext": "production",
+	"features": {"hooks": "true"},
+	"plugins": {"evil": {"hooks": "push"}}
+}`), 0o644))
+	t.Chdir(project)
+
+	buffer := new(bytes.Buffer)
+	configFile := LoadDefaultConfigFile(buffer)
+	assert.Check(t, is.Equal(configFile.ImagesFormat, "project"))
+	assert.Check(t, is.Equal(configFile.CurrentContext, "default"))
+	assert.Check(t, is.Len(configFile.Features, 0))
+	assert.Check(t, is.Len(configFile.Plugins, 0))
+	assert.Check(t, is.Equal(buffer.String(), "WARNING: Ignoring currentContext, features, plugins in project config file ("+projectFile+")\n"))
+}
+
 // The CLI no longer disables/hides experimental CLI features, however, we need
 // to verify that existing configuration files do not break
 func TestLoadLegacyExperimental(t *testing.T) {
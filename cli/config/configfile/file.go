@@ -75,6 +75,53 @@ type ConfigFile struct {
 	Plugins              map[string]map[string]string `json:"plugins,omitempty"`
 	Aliases              map[string]string            `json:"aliases,omitempty"`
 	Features             map[string]string            `json:"features,omitempty"`
+
+	// RegistryMirrors maps registry domains (such as "docker.io") to the
+	// URLs of mirrors to try, in order, before the registry itself when
+	// the CLI fetches content from it.
+	RegistryMirrors map[string][]string `json:"registryMirrors,omitempty"`
+
+	// InsecureRegistries are registries (host[:port] or CIDR) that the CLI
+	// connects to over plain HTTP, or HTTPS without verifying certificates.
+	InsecureRegistries []string `json:"insecureRegistries,omitempty"`
+
+	// RegistryRewrites maps reference name prefixes (such as
+	// "docker.io/library") to the prefixes to replace them with when the
+	// CLI communicates with a registry.
+	RegistryRewrites map[string]string `json:"registryRewrites,omitempty"`
+
+	// RegistryTLS holds TLS settings per registry (host[:port]), which are
+	// used in addition to the certificates in the "certs.d" directory.
+	RegistryTLS map[string]RegistryTLSConfig `json:"registryTLS,omitempty"`
+
+	// RegistryOIDC holds the OpenID Connect provider per registry
+	// (host[:port]) to log in with using "docker login --oidc".
+	RegistryOIDC map[string]RegistryOIDCConfig `json:"registryOIDC,omitempty"`
+
+	// CurrentProfile is the name of the profile to use, unless a profile is
+	// selected on the command line, or for the current context.
+	CurrentProfile string `json:"currentProfile,omitempty"`
+
+	// Profiles maps the names of profiles to the properties that are merged
+	// with the config file when the profile is used.
+	Profiles map[string]map[string]any `json:"profiles,omitempty"`
+
+	// ContextProfiles maps the names of contexts to the name of the profile
+	// to use with them.
+	ContextProfiles map[string]string `json:"contextProfiles,omitempty"`
+
+	// loaded is the content of the config file, merged with its layers, as
+	// it was loaded or last saved, to find the changes to merge when saving
+	// the config file.
+	loaded map[string]any
+	// user is the content of the config file without its layers, as it was
+	// loaded or last saved.
+	user   map[string]any
+	layers []*Layer
+
+	// wrapCredentialsStore wraps the stores that are returned by
+	// GetCredentialsStore, if set.
+	wrapCredentialsStore func(credentials.Store) credentials.Store
 }
 
 type configEnvAuth struct {
@@ -111,6 +158,25 @@ type ProxyConfig struct {
 	AllProxy   string `json:"allProxy,omitempty"`
 }
 
+// RegistryTLSConfig contains TLS settings for connecting to a registry.
+// Relative paths are relative to the directory of the config file.
+type RegistryTLSConfig struct {
+	CACert             string `json:"caCert,omitempty"`
+	Cert               string `json:"cert,omitempty"`
+	Key                string `json:"key,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// RegistryOIDCConfig configures the OpenID Connect provider to log in to a
+// registry with. The endpoints of the provider are discovered from its issuer
+// URL.
+type RegistryOIDCConfig struct {
+	Issuer   string   `json:"issuer,omitempty"`
+	ClientID string   `json:"clientID,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	Audience string   `json:"audience,omitempty"`
+}
+
 // New initializes an empty configuration file for the given filename 'fn'
 func New(fn string) *ConfigFile {
 	return &ConfigFile{
@@ -140,6 +206,8 @@ func (c *ConfigFile) LoadFromReader(configData io.Reader) error {
 		ac.ServerAddress = addr
 		c.AuthConfigs[addr] = ac
 	}
+	c.loaded, _ = c.normalized()
+	c.user = c.loaded
 	return nil
 }
 
@@ -203,6 +271,32 @@ func (c *ConfigFile) Save() (retErr error) {
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return err
 	}
+
+	// Handle situation where the configfile is a symlink, and allow for dangling symlinks
+	cfgFile := c.Filename
+	if f, err := filepath.EvalSymlinks(cfgFile); err == nil {
+		cfgFile = f
+	} else if os.IsNotExist(err) {
+		// extract the path from the error if the configfile does not exist or is a dangling symlink
+		var pathError *os.PathError
+		if errors.As(err, &pathError) {
+			cfgFile = pathError.Path
+		}
+	}
+
+	// Serialize updates of the config file, and merge the changes that other
+	// processes made since the config file was loaded, so that they are not
+	// lost.
+	unlock, err := lock(cfgFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	user, err := c.mergeChanges(cfgFile)
+	if err != nil {
+		return err
+	}
+
 	temp, err := os.CreateTemp(dir, filepath.Base(c.Filename))
 	if err != nil {
 		return err
@@ -217,7 +311,7 @@ func (c *ConfigFile) Save() (retErr error) {
 		}
 	}()
 
-	err = c.SaveToWriter(temp)
+	err = user.SaveToWriter(temp)
 	if err != nil {
 		return err
 	}
@@ -226,21 +320,16 @@ func (c *ConfigFile) Save() (retErr error) {
 		return fmt.Errorf("error closing temp file: %w", err)
 	}
 
-	// Handle situation where the configfile is a symlink, and allow for dangling symlinks
-	cfgFile := c.Filename
-	if f, err := filepath.EvalSymlinks(cfgFile); err == nil {
-		cfgFile = f
-	} else if os.IsNotExist(err) {
-		// extract the path from the error if the configfile does not exist or is a dangling symlink
-		var pathError *os.PathError
-		if errors.As(err, &pathError) {
-			cfgFile = pathError.Path
-		}
-	}
-
 	// Try copying the current config file (if any) ownership and permissions
 	copyFilePermissions(cfgFile, temp.Name())
-	return os.Rename(temp.Name(), cfgFile)
+	if err := os.Rename(temp.Name(), cfgFile); err != nil {
+		return err
+	}
+	if user != c {
+		user.layers, user.wrapCredentialsStore = c.layers, c.wrapCredentialsStore
+		*c = *user
+	}
+	return c.applyLayers()
 }
 
 // ParseProxyConfig computes proxy configuration by retrieving the config for the provided host and
@@ -316,14 +405,30 @@ func decodeAuth(authStr string) (string, string, error) {
 	return userName, strings.Trim(password, "\x00"), nil
 }
 
+// SetCredentialsStoreWrapper sets a function to wrap the credentials stores
+// that are returned by [ConfigFile.GetCredentialsStore] with, for example to
+// refresh credentials that expire.
+func (c *ConfigFile) SetCredentialsStoreWrapper(wrap func(credentials.Store) credentials.Store) {
+	c.wrapCredentialsStore = wrap
+}
+
 // GetCredentialsStore returns a new credentials store from the settings in the
 // configuration file
 func (c *ConfigFile) GetCredentialsStore(registryHostname string) credentials.Store {
 	store := credentials.NewFileStore(c)
 
-	if helper := getConfiguredCredentialStore(c, getAuthConfigKey(registryHostname)); helper != "" {
+	if helper := getConfiguredCredentialStore(c, getAuthConfigKey(registryHostname)); helper == credentials.EncryptedFileStore {
+		var dir string
+		if c.Filename != "" {
+			dir = filepath.Dir(c.Filename)
+		}
+		store = credentials.NewEncryptedStore(c, dir)
+	} else if helper != "" {
 		store = newNativeStore(c, helper)
 	}
+	if c.wrapCredentialsStore != nil {
+		store = c.wrapCredentialsStore(store)
+	}
 
 	envConfig := os.Getenv(DockerEnvConfigKey)
 	if envConfig == "" {
@@ -402,6 +507,13 @@ func getConfiguredCredentialStore(c *ConfigFile, registryHostname string) string
 	return c.CredentialsStore
 }
 
+// CredentialsStoreName returns the name of the credential helper or store that
+// holds the credentials for the given registry, or an empty string if the
+// credentials are stored in the config file.
+func (c *ConfigFile) CredentialsStoreName(registryHostname string) string {
+	return getConfiguredCredentialStore(c, getAuthConfigKey(registryHostname))
+}
+
 // GetAllCredentials returns all of the credentials stored in all of the
 // configured credential stores.
 func (c *ConfigFile) GetAllCredentials() (map[string]types.AuthConfig, error) {
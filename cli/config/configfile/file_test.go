@@ -4,7 +4,10 @@
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/docker/cli/cli/config/credentials"
@@ -499,6 +502,32 @@ func TestLoadFromReaderWithUsernamePassword(t *testing.T) {
 	}
 }`
 
+func TestGetCredentialsStoreEncrypted(t *testing.T) {
+	t.Setenv(credentials.EnvPassphrase, "passphrase")
+	dir := t.TempDir()
+	config := New(filepath.Join(dir, "config.json"))
+	config.CredentialsStore = credentials.EncryptedFileStore
+
+	auth := types.AuthConfig{Username: "user", Password: "pass", ServerAddress: "registry.example.com"}
+	assert.NilError(t, config.GetCredentialsStore("registry.example.com").Store(auth))
+	_, err := os.Stat(filepath.Join(dir, "credentials.enc"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(config.AuthConfigs, 0))
+
+	authConfigs, err := config.GetAllCredentials()
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(authConfigs, map[string]types.AuthConfig{"registry.example.com": auth}))
+
+	// DOCKER_AUTH_CONFIG overlays the encrypted store.
+	t.Setenv("DOCKER_AUTH_CONFIG", envTestAuthConfig)
+	authConfigs, err = config.GetAllCredentials()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(authConfigs, 2))
+	actual, err := config.GetAuthConfig("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(actual, auth))
+}
+
 func TestGetAllCredentialsFromEnvironment(t *testing.T) {
 	t.Run("can parse DOCKER_AUTH_CONFIG auth field", func(t *testing.T) {
 		config := &ConfigFile{}
@@ -611,6 +640,7 @@ func TestParseEnvConfig(t *testing.T) {
 func TestSave(t *testing.T) {
 	configFile := New("test-save")
 	defer os.Remove("test-save")
+	defer os.Remove("test-save.lock")
 	err := configFile.Save()
 	assert.NilError(t, err)
 	cfg, err := os.ReadFile("test-save")
@@ -623,6 +653,7 @@ func TestSave(t *testing.T) {
 func TestSaveCustomHTTPHeaders(t *testing.T) {
 	configFile := New(t.Name())
 	defer os.Remove(t.Name())
+	defer os.Remove(t.Name() + ".lock")
 	configFile.HTTPHeaders["CUSTOM-HEADER"] = "custom-value"
 	configFile.HTTPHeaders["User-Agent"] = "user-agent 1"
 	configFile.HTTPHeaders["user-agent"] = "user-agent 2"
@@ -696,6 +727,7 @@ func TestSaveWithRelativeSymlink(t *testing.T) {
 func TestPluginConfig(t *testing.T) {
 	configFile := New("test-plugin")
 	defer os.Remove("test-plugin")
+	defer os.Remove("test-plugin.lock")
 
 	// Populate some initial values
 	configFile.SetPluginConfig("plugin1", "data1", "some string")
@@ -715,6 +747,7 @@ func TestPluginConfig(t *testing.T) {
 	// preserved through a load/save cycle.
 	configFile = New("test-plugin2")
 	defer os.Remove("test-plugin2")
+	defer os.Remove("test-plugin2.lock")
 	assert.NilError(t, configFile.LoadFromReader(bytes.NewReader(cfg)))
 	err = configFile.Save()
 	assert.NilError(t, err)
@@ -757,3 +790,83 @@ func TestPluginConfig(t *testing.T) {
 	assert.NilError(t, err)
 	golden.Assert(t, string(cfg), "plugin-config-2.golden")
 }
+
+func TestSaveMergesChanges(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("config.json", `{
+	"auths": {
+		"one.example.com": {"auth": "dXNlcjpwYXNz"},
+		"two.example.com": {"auth": "dXNlcjpwYXNz"}
+	},
+	"plugins": {"plugin": {"option": "value"}}
+}`))
+	defer dir.Remove()
+	filename := dir.Join("config.json")
+
+	load := func() *ConfigFile {
+		t.Helper()
+		f, err := os.Open(filename)
+		assert.NilError(t, err)
+		defer f.Close()
+		configFile := New(filename)
+		assert.NilError(t, configFile.LoadFromReader(f))
+		return configFile
+	}
+
+	first, second := load(), load()
+
+	first.AuthConfigs["three.example.com"] = types.AuthConfig{Username: "user", Password: "pass"}
+	first.SetPluginConfig("plugin", "option", "first")
+	assert.NilError(t, first.Save())
+
+	delete(second.AuthConfigs, "one.example.com")
+	second.CurrentContext = "my-context"
+	second.SetPluginConfig("plugin", "option", "second")
+	assert.NilError(t, second.Save())
+
+	// Changes of both are kept, and changes of the last to save take
+	// precedence.
+	for _, configFile := range []*ConfigFile{second, load()} {
+		assert.Check(t, is.Len(configFile.AuthConfigs, 2))
+		assert.Check(t, is.Equal(configFile.AuthConfigs["two.example.com"].Username, "user"))
+		assert.Check(t, is.Equal(configFile.AuthConfigs["three.example.com"].Password, "pass"))
+		assert.Check(t, is.Equal(configFile.CurrentContext, "my-context"))
+		assert.Check(t, is.DeepEqual(configFile.Plugins, map[string]map[string]string{"plugin": {"option": "second"}}))
+	}
+
+	// Fields that were not changed are taken from the config file on disk.
+	first.Aliases = map[string]string{"builder": "buildx"}
+	assert.NilError(t, first.Save())
+	configFile := load()
+	assert.Check(t, is.Equal(configFile.CurrentContext, "my-context"))
+	assert.Check(t, is.Len(configFile.AuthConfigs, 2))
+	assert.Check(t, is.DeepEqual(configFile.Aliases, map[string]string{"builder": "buildx"}))
+}
+
+func TestSaveConcurrent(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("config.json", `{}`))
+	defer dir.Remove()
+	filename := dir.Join("config.json")
+
+	const n = 10
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			f, err := os.Open(filename)
+			assert.Check(t, err)
+			defer f.Close()
+			configFile := New(filename)
+			assert.Check(t, configFile.LoadFromReader(f))
+			registry := fmt.Sprintf("registry-%d.example.com", i)
+			configFile.AuthConfigs[registry] = types.AuthConfig{Username: "user", Password: "pass"}
+			assert.Check(t, configFile.Save())
+		})
+	}
+	wg.Wait()
+
+	f, err := os.Open(filename)
+	assert.NilError(t, err)
+	defer f.Close()
+	configFile := New(filename)
+	assert.NilError(t, configFile.LoadFromReader(f))
+	assert.Check(t, is.Len(configFile.AuthConfigs, n))
+}
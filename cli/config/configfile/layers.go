@@ -0,0 +1,267 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Origins of the values in a config file.
+const (
+	// OriginDefault is the origin of values that are not set in any of the
+	// configuration files, such as the credentials store that is detected
+	// for the current platform.
+	OriginDefault = "default"
+	// OriginSystem is the origin of values in the system-wide configuration
+	// file.
+	OriginSystem = "system"
+	// OriginUser is the origin of values in the user's configuration file,
+	// which is the file that is updated by [ConfigFile.Save].
+	OriginUser = "user"
+	// OriginProject is the origin of values in the configuration file of the
+	// project that the CLI is run in.
+	OriginProject = "project"
+	// OriginProfile is the origin of values in the profile that is used.
+	OriginProfile = "profile"
+)
+
+// Layer is a read-only configuration file that is merged with the config
+// file, such as a system-wide configuration file, the configuration file
+// of a project, or a profile.
+type Layer struct {
+	// Origin is the origin of the values in the layer, for example
+	// [OriginSystem] or [OriginProject].
+	Origin string
+	// Name is the name of the layer, such as the name of a profile.
+	Name string
+	// Filename is the file that the layer was loaded from.
+	Filename string
+	// Above is whether the values in the layer take precedence over the
+	// values in the config file.
+	Above bool
+	// Ignored holds the fields that are set in the layer, but were ignored.
+	Ignored []string
+
+	content map[string]any
+}
+
+// LoadLayer reads a layer from the given reader. Fields in ignore are not
+// merged with the config file, and are reported in [Layer.Ignored].
+func LoadLayer(origin, filename string, above bool, r io.Reader, ignore ...string) (*Layer, error) {
+	cf := New(filename)
+	if err := cf.LoadFromReader(r); err != nil {
+		return nil, err
+	}
+	content, err := cf.normalized()
+	if err != nil {
+		return nil, err
+	}
+	l := &Layer{Origin: origin, Filename: filename, Above: above, content: content}
+	for _, k := range ignore {
+		if v, ok := content[k]; ok {
+			delete(content, k)
+			if !isEmpty(v) {
+				l.Ignored = append(l.Ignored, k)
+			}
+		}
+	}
+	return l, nil
+}
+
+// Content returns the values in the layer, as they are in the JSON
+// representation of the config file.
+func (l *Layer) Content() map[string]any {
+	return maps.Clone(l.content)
+}
+
+// SetLayers merges the given layers with the config file. Layers are merged
+// in the given order: values in layers that are merged later take precedence
+// over values in layers that are merged earlier. Objects such as "proxies",
+// "plugins" and "auths" are merged per entry; other values are replaced.
+//
+// The values of the layers are not written to the config file when it is
+// saved, unless they were changed. SetLayers replaces the layers that were
+// merged before; changes that were made to the config file since it was
+// loaded are kept.
+func (c *ConfigFile) SetLayers(layers ...*Layer) error {
+	if c.user == nil {
+		c.layers = layers
+		return c.applyLayers()
+	}
+
+	mine, err := c.normalized()
+	if err != nil {
+		return err
+	}
+	result, err := decode(c.Filename, c.user)
+	if err != nil {
+		return err
+	}
+	result.layers = layers
+	if err := result.applyLayers(); err != nil {
+		return err
+	}
+	if merged := mergeObjects(c.loaded, mine, result.loaded, 1); !reflect.DeepEqual(merged, result.loaded) {
+		changed, err := decode(c.Filename, merged)
+		if err != nil {
+			return err
+		}
+		changed.loaded, changed.user, changed.layers = result.loaded, result.user, result.layers
+		result = changed
+	}
+	result.wrapCredentialsStore = c.wrapCredentialsStore
+	*c = *result
+	return nil
+}
+
+// Layers returns the layers that are merged with the config file.
+func (c *ConfigFile) Layers() []*Layer {
+	return c.layers
+}
+
+// applyLayers merges the layers with c, which holds the values of the config
+// file itself.
+func (c *ConfigFile) applyLayers() error {
+	user, err := c.normalized()
+	if err != nil {
+		return err
+	}
+	if len(c.layers) == 0 {
+		c.user, c.loaded = user, user
+		return nil
+	}
+
+	effective := make(map[string]any)
+	for _, l := range c.layers {
+		if !l.Above {
+			overlay(effective, l.content)
+		}
+	}
+	overlay(effective, user)
+	for _, l := range c.layers {
+		if l.Above {
+			overlay(effective, l.content)
+		}
+	}
+
+	result, err := decode(c.Filename, effective)
+	if err != nil {
+		return fmt.Errorf("merging configuration files: %w", err)
+	}
+	result.layers = c.layers
+	result.user = user
+	result.wrapCredentialsStore = c.wrapCredentialsStore
+	*c = *result
+	return nil
+}
+
+// decode returns the config file with the given content.
+func decode(filename string, content map[string]any) (*ConfigFile, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	c := New(filename)
+	if err := c.LoadFromReader(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// overlay merges the values of src into dst. Objects are merged per entry.
+func overlay(dst, src map[string]any) {
+	for k, v := range src {
+		d, dstIsObject := dst[k].(map[string]any)
+		s, srcIsObject := v.(map[string]any)
+		if dstIsObject && srcIsObject {
+			merged := maps.Clone(d)
+			maps.Copy(merged, s)
+			dst[k] = merged
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return v == nil
+}
+
+// Origin describes where a value in the config file comes from.
+type Origin struct {
+	// Key is the name of the field, such as "psFormat", followed by the key
+	// of the entry for objects, such as "proxies.default".
+	Key   string
+	Value any
+	// Origin is the origin of the value, such as [OriginUser].
+	Origin string
+	// Filename is the file that the value comes from, if any.
+	Filename string
+}
+
+// Origins returns the values in the config file, ordered by key, together
+// with the configuration file that each value comes from. Objects are
+// returned per entry, as they are merged per entry.
+func (c *ConfigFile) Origins() ([]Origin, error) {
+	effective, err := c.normalized()
+	if err != nil {
+		return nil, err
+	}
+
+	// sources holds the configuration files in order of precedence.
+	type source struct {
+		origin, filename string
+		content          map[string]any
+	}
+	var sources []source
+	for _, l := range slices.Backward(c.layers) {
+		if l.Above {
+			sources = append(sources, source{l.Origin, l.Filename, l.content})
+		}
+	}
+	sources = append(sources, source{OriginUser, c.Filename, c.user})
+	for _, l := range slices.Backward(c.layers) {
+		if !l.Above {
+			sources = append(sources, source{l.Origin, l.Filename, l.content})
+		}
+	}
+
+	origin := func(key, entry string, v any) (string, string) {
+		for _, s := range sources {
+			sv, ok := s.content[key]
+			if ok && entry != "" {
+				obj, _ := sv.(map[string]any)
+				sv, ok = obj[entry]
+			}
+			if ok && reflect.DeepEqual(sv, v) {
+				return s.origin, s.filename
+			}
+		}
+		return OriginDefault, ""
+	}
+
+	var origins []Origin
+	for _, key := range slices.Sorted(maps.Keys(effective)) {
+		v := effective[key]
+		if obj, ok := v.(map[string]any); ok {
+			for _, entry := range slices.Sorted(maps.Keys(obj)) {
+				o, fn := origin(key, entry, obj[entry])
+				origins = append(origins, Origin{Key: key + "." + entry, Value: obj[entry], Origin: o, Filename: fn})
+			}
+			continue
+		}
+		o, fn := origin(key, "", v)
+		origins = append(origins, Origin{Key: key, Value: v, Origin: o, Filename: fn})
+	}
+	return origins, nil
+}
@@ -0,0 +1,174 @@
+package configfile
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
+)
+
+func TestSetLayers(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("config.json", `{
+	"psFormat": "user",
+	"proxies": {"default": {"httpProxy": "http://user.example.com"}},
+	"features": {"user": "true"}
+}`))
+	defer dir.Remove()
+	filename := dir.Join("config.json")
+
+	system, err := LoadLayer(OriginSystem, "/etc/docker/cli/config.json", false, strings.NewReader(`{
+	"psFormat": "system",
+	"imagesFormat": "system",
+	"proxies": {
+		"default": {"httpProxy": "http://system.example.com"},
+		"tcp://docker.example.com": {"httpProxy": "http://system.example.com"}
+	}
+}`))
+	assert.NilError(t, err)
+	project, err := LoadLayer(OriginProject, "/project/.docker/config.json", true, strings.NewReader(`{
+	"imagesFormat": "project",
+	"features": {"project": "true"},
+	"credsStore": "project"
+}`), "credsStore", "auths")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(project.Ignored, []string{"credsStore"}))
+
+	load := func() *ConfigFile {
+		t.Helper()
+		f, err := os.Open(filename)
+		assert.NilError(t, err)
+		defer f.Close()
+		configFile := New(filename)
+		assert.NilError(t, configFile.LoadFromReader(f))
+		assert.NilError(t, configFile.SetLayers(system, project))
+		return configFile
+	}
+
+	configFile := load()
+	assert.Check(t, is.Equal(configFile.PsFormat, "user"))
+	assert.Check(t, is.Equal(configFile.ImagesFormat, "project"))
+	assert.Check(t, is.Equal(configFile.CredentialsStore, ""))
+	assert.Check(t, is.DeepEqual(configFile.Proxies, map[string]ProxyConfig{
+		"default":                  {HTTPProxy: "http://user.example.com"},
+		"tcp://docker.example.com": {HTTPProxy: "http://system.example.com"},
+	}))
+	assert.Check(t, is.DeepEqual(configFile.Features, map[string]string{"user": "true", "project": "true"}))
+
+	origins, err := configFile.Origins()
+	assert.NilError(t, err)
+	actual := make(map[string]string)
+	for _, o := range origins {
+		actual[o.Key] = o.Origin
+	}
+	assert.Check(t, is.DeepEqual(actual, map[string]string{
+		"features.project":                 OriginProject,
+		"features.user":                    OriginUser,
+		"imagesFormat":                     OriginProject,
+		"proxies.default":                  OriginUser,
+		"proxies.tcp://docker.example.com": OriginSystem,
+		"psFormat":                         OriginUser,
+	}))
+
+	// Only changes are saved to the config file; values of the layers are
+	// not.
+	configFile.StatsFormat = "user"
+	configFile.SetPluginConfig("plugin", "option", "value")
+	assert.NilError(t, configFile.Save())
+	assert.Check(t, is.Equal(configFile.ImagesFormat, "project"))
+
+	data, err := os.ReadFile(filename)
+	assert.NilError(t, err)
+	assert.Check(t, !is.Contains(string(data), "system")().Success())
+	assert.Check(t, !is.Contains(string(data), "project")().Success())
+
+	configFile = New(filename)
+	f, err := os.Open(filename)
+	assert.NilError(t, err)
+	defer f.Close()
+	assert.NilError(t, configFile.LoadFromReader(f))
+	assert.Check(t, is.Equal(configFile.PsFormat, "user"))
+	assert.Check(t, is.Equal(configFile.StatsFormat, "user"))
+	assert.Check(t, is.Equal(configFile.ImagesFormat, ""))
+	assert.Check(t, is.Len(configFile.Proxies, 1))
+	assert.Check(t, is.DeepEqual(configFile.Plugins, map[string]map[string]string{"plugin": {"option": "value"}}))
+
+	// Values of layers that are changed are saved to the config file.
+	configFile = load()
+	configFile.Proxies["tcp://docker.example.com"] = ProxyConfig{HTTPProxy: "http://changed.example.com"}
+	assert.NilError(t, configFile.Save())
+	configFile = load()
+	assert.Check(t, is.Equal(configFile.Proxies["tcp://docker.example.com"].HTTPProxy, "http://changed.example.com"))
+
+	// Replacing the layers keeps the changes that were made.
+	configFile = load()
+	configFile.NodesFormat = "changed"
+	assert.NilError(t, configFile.SetLayers(system))
+	assert.Check(t, is.Equal(configFile.NodesFormat, "changed"))
+	assert.Check(t, is.Equal(configFile.ImagesFormat, "system"))
+	assert.Check(t, is.DeepEqual(configFile.Features, map[string]string{"user": "true"}))
+	assert.NilError(t, configFile.Save())
+	configFile = load()
+	assert.Check(t, is.Equal(configFile.NodesFormat, "changed"))
+}
+
+func TestLoadLayerInvalid(t *testing.T) {
+	_, err := LoadLayer(OriginSystem, "config.json", false, strings.NewReader(`{"psFormat": 1}`))
+	assert.Check(t, is.ErrorContains(err, "cannot unmarshal number"))
+}
+
+func TestSetLayersCredentialsStoreWrapper(t *testing.T) {
+	t.Setenv(DockerEnvConfigKey, "")
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("config.json", `{"psFormat": "user"}`))
+	defer dir.Remove()
+	filename := dir.Join("config.json")
+
+	layer, err := LoadLayer(OriginSystem, "/etc/docker/cli/config.json", false, strings.NewReader(`{"imagesFormat": "system"}`))
+	assert.NilError(t, err)
+
+	f, err := os.Open(filename)
+	assert.NilError(t, err)
+	defer f.Close()
+	configFile := New(filename)
+	assert.NilError(t, configFile.LoadFromReader(f))
+	configFile.SetCredentialsStoreWrapper(func(store credentials.Store) credentials.Store {
+		return &wrappedStore{store: store}
+	})
+
+	// The wrapper is kept when the config file is replaced by the merged
+	// config file.
+	assert.NilError(t, configFile.SetLayers(layer))
+	_, ok := configFile.GetCredentialsStore("registry.example.com").(*wrappedStore)
+	assert.Check(t, ok, "credentials store is not wrapped after SetLayers")
+
+	configFile.StatsFormat = "user"
+	assert.NilError(t, configFile.Save())
+	_, ok = configFile.GetCredentialsStore("registry.example.com").(*wrappedStore)
+	assert.Check(t, ok, "credentials store is not wrapped after Save")
+}
+
+// wrappedStore is a credentials store that wraps another store.
+type wrappedStore struct {
+	store credentials.Store
+}
+
+func (s *wrappedStore) Erase(serverAddress string) error {
+	return s.store.Erase(serverAddress)
+}
+
+func (s *wrappedStore) Get(serverAddress string) (types.AuthConfig, error) {
+	return s.store.Get(serverAddress)
+}
+
+func (s *wrappedStore) GetAll() (map[string]types.AuthConfig, error) {
+	return s.store.GetAll()
+}
+
+func (s *wrappedStore) Store(authConfig types.AuthConfig) error {
+	return s.store.Store(authConfig)
+}
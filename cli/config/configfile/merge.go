@@ -0,0 +1,101 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+
+	"github.com/docker/cli/internal/filelock"
+	"github.com/sirupsen/logrus"
+)
+
+// lock acquires an advisory lock on a lock file next to the given config
+// file, waiting for other processes that are updating the config file. It
+// returns a function to release the lock.
+func lock(filename string) (unlock func(), _ error) {
+	return filelock.Lock(filename + ".lock")
+}
+
+// normalized returns the config file as it would be written to disk.
+func (c *ConfigFile) normalized() (map[string]any, error) {
+	var buf bytes.Buffer
+	if err := c.SaveToWriter(&buf); err != nil {
+		return nil, err
+	}
+	var v map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// mergeChanges returns the config file to write: the config file as it is on
+// disk, with the changes that were made to c since it was loaded applied to
+// it, so that changes that other processes made are not lost. Fields and
+// entries that were changed in c take precedence. Values that come from the
+// layers of c are not written, unless they were changed in c.
+func (c *ConfigFile) mergeChanges(filename string) (*ConfigFile, error) {
+	theirs := c.user
+	f, err := os.Open(filename)
+	if err == nil {
+		defer f.Close()
+		onDisk := New(filename)
+		if err := onDisk.LoadFromReader(f); err != nil {
+			// The config file is overwritten, as it was before merging changes.
+			logrus.WithError(err).WithField("file", filename).Debug("not merging changes from invalid config file")
+		} else {
+			theirs = onDisk.user
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	mine, err := c.normalized()
+	if err != nil {
+		return nil, err
+	}
+	merged := mergeObjects(c.loaded, mine, theirs, 1)
+	if len(c.layers) == 0 && reflect.DeepEqual(merged, mine) {
+		return c, nil
+	}
+
+	return decode(c.Filename, merged)
+}
+
+// mergeObjects performs a three-way merge of JSON objects: it applies the
+// changes between base and mine to theirs. Objects that are nested up to the
+// given depth are merged per key; other values are replaced as a whole.
+func mergeObjects(base, mine, theirs map[string]any, depth int) map[string]any {
+	merged := make(map[string]any, len(theirs))
+	keys := make(map[string]struct{}, len(mine)+len(theirs))
+	for k := range mine {
+		keys[k] = struct{}{}
+	}
+	for k := range theirs {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		b, inBase := base[k]
+		m, inMine := mine[k]
+		t, inTheirs := theirs[k]
+		switch {
+		case inMine == inBase && reflect.DeepEqual(m, b):
+			// not changed in c; keep the changes of other processes, if any.
+			if inTheirs {
+				merged[k] = t
+			}
+		case !inMine:
+			// removed in c.
+		default:
+			mo, mineIsObject := m.(map[string]any)
+			to, theirsIsObject := t.(map[string]any)
+			if depth > 0 && mineIsObject && theirsIsObject {
+				bo, _ := b.(map[string]any)
+				merged[k] = mergeObjects(bo, mo, to, depth-1)
+			} else {
+				merged[k] = m
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,376 @@
+package credentials
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/internal/filelock"
+)
+
+// EncryptedFileStore is the name of the credentials store that keeps
+// credentials encrypted in a file next to the config file. It is used
+// instead of a credential helper when "credsStore" or "credHelpers" in
+// the config file is set to this name.
+const EncryptedFileStore = "encrypted-file"
+
+const (
+	// EnvPassphrase is the name of the environment variable that holds the
+	// passphrase to encrypt the credentials in the encrypted file store with.
+	EnvPassphrase = "DOCKER_CREDENTIALS_PASSPHRASE"
+
+	// EnvKeyFile is the name of the environment variable that holds the path
+	// of a file to read the passphrase for the encrypted file store from, if
+	// [EnvPassphrase] is not set.
+	EnvKeyFile = "DOCKER_CREDENTIALS_KEY_FILE"
+)
+
+const (
+	// encryptedFileName is the name of the file that the encrypted file store
+	// keeps credentials in.
+	encryptedFileName = "credentials.enc"
+
+	// keyFileName is the name of the file that the passphrase for the
+	// encrypted file store is read from, if neither [EnvPassphrase] nor
+	// [EnvKeyFile] is set.
+	keyFileName = "credentials.key"
+
+	encryptedFileVersion = 1
+	kdfPBKDF2SHA256      = "pbkdf2-sha256"
+	kdfIterations        = 600_000
+	saltSize             = 16
+	keySize              = 32 // AES-256
+)
+
+// encryptedFile is the format of the file that the encrypted file store
+// keeps credentials in. The credentials are encrypted with AES-GCM, using
+// a key that is derived from the passphrase with PBKDF2.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// derivedKeys caches the keys that are derived from a passphrase, as
+// deriving a key is slow by design, and the store is created for every
+// lookup of credentials.
+var derivedKeys sync.Map
+
+// encryptedStore implements a credentials store that keeps credentials
+// encrypted in a file with strict permissions. It piggybacks into a file
+// store for credentials that were stored before it was configured.
+type encryptedStore struct {
+	filename  string
+	keyFile   string
+	fileStore Store
+
+	// err is returned by all operations if the store can't be used.
+	err error
+}
+
+// NewEncryptedStore creates a new credentials store that keeps credentials
+// encrypted in a file in the given directory, which is usually the directory
+// of the config file.
+//
+// The credentials are encrypted with a key that is derived from a passphrase.
+// The passphrase is taken from the DOCKER_CREDENTIALS_PASSPHRASE environment
+// variable, or read from the file in the DOCKER_CREDENTIALS_KEY_FILE
+// environment variable, or from the "credentials.key" file in the directory.
+//
+// All operations of the store fail if dir is empty, as is the case for a
+// config file that is not stored on disk.
+func NewEncryptedStore(file store, dir string) Store {
+	if dir == "" {
+		return &encryptedStore{err: errors.New("the encrypted credentials store requires a config file")}
+	}
+	return &encryptedStore{
+		filename:  filepath.Join(dir, encryptedFileName),
+		keyFile:   filepath.Join(dir, keyFileName),
+		fileStore: NewFileStore(file),
+	}
+}
+
+// Erase removes the given credentials from the encrypted store, and from the
+// file store. This function is idempotent and does not update the files if
+// credentials did not change.
+func (c *encryptedStore) Erase(serverAddress string) error {
+	err := c.update(func(auths map[string]types.AuthConfig) bool {
+		if _, ok := auths[serverAddress]; !ok {
+			return false
+		}
+		delete(auths, serverAddress)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return c.fileStore.Erase(serverAddress)
+}
+
+// Get retrieves credentials for a specific server from the encrypted store.
+// It falls back to the file store for credentials that were stored before
+// the encrypted store was configured.
+func (c *encryptedStore) Get(serverAddress string) (types.AuthConfig, error) {
+	auths, _, err := c.load()
+	if err != nil {
+		return types.AuthConfig{}, err
+	}
+	if auth, ok := auths[serverAddress]; ok {
+		return auth, nil
+	}
+	// Maybe the credentials were stored with a legacy key, as in the file
+	// store.
+	for r, auth := range auths {
+		if serverAddress == ConvertToHostname(r) {
+			return auth, nil
+		}
+	}
+	return c.fileStore.Get(serverAddress)
+}
+
+// GetAll retrieves all the credentials from the encrypted store, and the
+// credentials in the file store that are not in the encrypted store.
+func (c *encryptedStore) GetAll() (map[string]types.AuthConfig, error) {
+	auths, _, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	fileConfigs, err := c.fileStore.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	authConfigs := make(map[string]types.AuthConfig, len(auths)+len(fileConfigs))
+	maps.Copy(authConfigs, fileConfigs)
+	maps.Copy(authConfigs, auths)
+	return authConfigs, nil
+}
+
+// Store saves the given credentials in the encrypted store, and removes
+// them from the file store. This function is idempotent and does not update
+// the file if credentials did not change.
+func (c *encryptedStore) Store(authConfig types.AuthConfig) error {
+	err := c.update(func(auths map[string]types.AuthConfig) bool {
+		if old, ok := auths[authConfig.ServerAddress]; ok && old == authConfig {
+			return false
+		}
+		auths[authConfig.ServerAddress] = authConfig
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	// Remove the credentials that may have been stored unencrypted in the
+	// config file.
+	return c.fileStore.Erase(authConfig.ServerAddress)
+}
+
+// update calls fn with the credentials in the encrypted file, and saves them
+// if fn changed them. The encrypted file is locked while it is updated, and
+// read again after acquiring the lock, so that the changes of other processes
+// that update the file at the same time are not lost.
+func (c *encryptedStore) update(fn func(auths map[string]types.AuthConfig) (changed bool)) error {
+	if c.err != nil {
+		return c.err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.filename), 0o700); err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(c.filename + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	auths, ef, err := c.load()
+	if err != nil {
+		return err
+	}
+	if !fn(auths) {
+		return nil
+	}
+	return c.save(auths, ef)
+}
+
+// load decrypts the credentials in the encrypted file. It returns no
+// credentials if the file does not exist yet.
+func (c *encryptedStore) load() (map[string]types.AuthConfig, *encryptedFile, error) {
+	if c.err != nil {
+		return nil, nil, c.err
+	}
+	auths := make(map[string]types.AuthConfig)
+	data, err := os.ReadFile(c.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return auths, nil, nil
+		}
+		return nil, nil, err
+	}
+	if err := checkPermissions(c.filename); err != nil {
+		return nil, nil, err
+	}
+
+	var ef encryptedFile
+	if err := json.Unmarshal(data, &ef); err != nil {
+		return nil, nil, fmt.Errorf("failed to read encrypted credentials from %s: %w", c.filename, err)
+	}
+	if ef.Version != encryptedFileVersion || ef.KDF != kdfPBKDF2SHA256 {
+		return nil, nil, fmt.Errorf("failed to read encrypted credentials from %s: unsupported version %d (%s)", c.filename, ef.Version, ef.KDF)
+	}
+	aead, err := c.cipher(&ef)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := aead.Open(nil, ef.Nonce, ef.Data, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt credentials in %s: wrong passphrase, or the file is corrupted", c.filename)
+	}
+	if err := json.Unmarshal(plaintext, &auths); err != nil {
+		return nil, nil, fmt.Errorf("failed to read encrypted credentials from %s: %w", c.filename, err)
+	}
+	return auths, &ef, nil
+}
+
+// save encrypts the credentials, and atomically replaces the encrypted file.
+// The salt and iterations of the existing file, if any, are kept so that the
+// derived key can be reused, unless the file uses fewer iterations than the
+// current minimum.
+func (c *encryptedStore) save(auths map[string]types.AuthConfig, prev *encryptedFile) error {
+	ef := encryptedFile{
+		Version:    encryptedFileVersion,
+		KDF:        kdfPBKDF2SHA256,
+		Iterations: kdfIterations,
+	}
+	if prev != nil && prev.Iterations >= kdfIterations {
+		ef.Iterations = prev.Iterations
+		ef.Salt = prev.Salt
+	} else {
+		ef.Salt = make([]byte, saltSize)
+		if _, err := rand.Read(ef.Salt); err != nil {
+			return err
+		}
+	}
+	aead, err := c.cipher(&ef)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(auths)
+	if err != nil {
+		return err
+	}
+	ef.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(ef.Nonce); err != nil {
+		return err
+	}
+	ef.Data = aead.Seal(nil, ef.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(ef, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.filename, data)
+}
+
+// cipher returns the AEAD to encrypt and decrypt the credentials with.
+func (c *encryptedStore) cipher(ef *encryptedFile) (cipher.AEAD, error) {
+	passphrase, err := c.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := fmt.Sprintf("%d:%x:%x", ef.Iterations, ef.Salt, sha256.Sum256(passphrase))
+	key, ok := derivedKeys.Load(cacheKey)
+	if !ok {
+		k, err := pbkdf2.Key(sha256.New, string(passphrase), ef.Salt, ef.Iterations, keySize)
+		if err != nil {
+			return nil, err
+		}
+		key, _ = derivedKeys.LoadOrStore(cacheKey, k)
+	}
+	block, err := aes.NewCipher(key.([]byte))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// passphrase returns the passphrase to derive the encryption key from.
+func (c *encryptedStore) passphrase() ([]byte, error) {
+	if p := os.Getenv(EnvPassphrase); p != "" {
+		return []byte(p), nil
+	}
+	keyFile := os.Getenv(EnvKeyFile)
+	if keyFile == "" {
+		keyFile = c.keyFile
+		if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+			return nil, fmt.Errorf("no passphrase for the encrypted credentials store: set the %s or %s environment variable, or create %s", EnvPassphrase, EnvKeyFile, keyFile)
+		}
+	}
+	p, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase for the encrypted credentials store: %w", err)
+	}
+	if err := checkPermissions(keyFile); err != nil {
+		return nil, err
+	}
+	p = bytes.TrimSpace(p)
+	if len(p) == 0 {
+		return nil, fmt.Errorf("failed to read passphrase for the encrypted credentials store: %s is empty", keyFile)
+	}
+	return p, nil
+}
+
+// checkPermissions returns an error if the file can be accessed by other
+// users than its owner.
+func checkPermissions(filename string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if perm := fi.Mode().Perm(); perm&0o077 != 0 {
+		return fmt.Errorf("permissions %#o for %s are too open: it must only be accessible by its owner (0600)", perm, filename)
+	}
+	return nil
+}
+
+// writeFileAtomic writes the data to a temporary file, which is created with
+// mode 0600, and renames it to the given filename.
+func writeFileAtomic(filename string, data []byte) (retErr error) {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(dir, filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = temp.Close()
+		if retErr != nil {
+			_ = os.Remove(temp.Name())
+		}
+	}()
+	if _, err := temp.Write(data); err != nil {
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("error closing temp file: %w", err)
+	}
+	return os.Rename(temp.Name(), filename)
+}
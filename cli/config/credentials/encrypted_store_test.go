@@ -0,0 +1,191 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/docker/cli/cli/config/types"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	t.Setenv(EnvPassphrase, "correct horse battery staple")
+	dir := t.TempDir()
+	f := &fakeStore{configs: map[string]types.AuthConfig{
+		"https://example.com":     {Username: "foo", Password: "old", ServerAddress: "https://example.com"},
+		"unencrypted.example.com": {Username: "foo", Password: "plain", ServerAddress: "unencrypted.example.com"},
+	}}
+	s := NewEncryptedStore(f, dir)
+
+	// Credentials that are not in the encrypted store are taken from the file store.
+	auth, err := s.Get("https://example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, "old"))
+
+	assert.NilError(t, s.Store(types.AuthConfig{
+		Username:      "foo",
+		Password:      "bar",
+		ServerAddress: "https://example.com",
+	}))
+	assert.NilError(t, s.Store(types.AuthConfig{
+		IdentityToken: "token",
+		ServerAddress: "registry.example.com",
+	}))
+
+	// Stored credentials are removed from the file store.
+	assert.Check(t, is.DeepEqual(f.GetAuthConfigs(), map[string]types.AuthConfig{
+		"unencrypted.example.com": {Username: "foo", Password: "plain", ServerAddress: "unencrypted.example.com"},
+	}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "credentials.enc"))
+	assert.NilError(t, err)
+	assert.Check(t, !is.Contains(string(data), "bar")().Success())
+	assert.Check(t, !is.Contains(string(data), "token")().Success())
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(filepath.Join(dir, "credentials.enc"))
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(fi.Mode().Perm(), os.FileMode(0o600)))
+	}
+
+	auth, err = s.Get("https://example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(auth, types.AuthConfig{
+		Username:      "foo",
+		Password:      "bar",
+		ServerAddress: "https://example.com",
+	}))
+
+	// Credentials stored with a legacy key are found by hostname.
+	auth, err = s.Get("example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, "bar"))
+
+	all, err := NewEncryptedStore(f, dir).GetAll()
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(all, map[string]types.AuthConfig{
+		"https://example.com":     {Username: "foo", Password: "bar", ServerAddress: "https://example.com"},
+		"registry.example.com":    {IdentityToken: "token", ServerAddress: "registry.example.com"},
+		"unencrypted.example.com": {Username: "foo", Password: "plain", ServerAddress: "unencrypted.example.com"},
+	}))
+
+	assert.NilError(t, s.Erase("https://example.com"))
+	auth, err = s.Get("https://example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(auth, types.AuthConfig{}))
+
+	t.Setenv(EnvPassphrase, "wrong")
+	_, err = s.Get("registry.example.com")
+	assert.Check(t, is.ErrorContains(err, "wrong passphrase, or the file is corrupted"))
+}
+
+func TestEncryptedStoreKeyFile(t *testing.T) {
+	t.Setenv(EnvPassphrase, "")
+	t.Setenv(EnvKeyFile, "")
+	dir := t.TempDir()
+	s := NewEncryptedStore(&fakeStore{configs: map[string]types.AuthConfig{}}, dir)
+	auth := types.AuthConfig{Username: "foo", Password: "bar", ServerAddress: "registry.example.com"}
+
+	err := s.Store(auth)
+	assert.Check(t, is.ErrorContains(err, "no passphrase for the encrypted credentials store"))
+
+	keyFile := filepath.Join(dir, "credentials.key")
+	assert.NilError(t, os.WriteFile(keyFile, []byte("passphrase-from-file\n"), 0o600))
+	assert.NilError(t, s.Store(auth))
+
+	// The same passphrase can be passed through the environment.
+	t.Setenv(EnvPassphrase, "passphrase-from-file")
+	actual, err := s.Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(actual.Password, "bar"))
+
+	t.Setenv(EnvPassphrase, "")
+	t.Setenv(EnvKeyFile, filepath.Join(dir, "other.key"))
+	_, err = s.Get("registry.example.com")
+	assert.Check(t, is.ErrorContains(err, "failed to read passphrase for the encrypted credentials store"))
+}
+
+func TestEncryptedStorePermissions(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "permissions are not checked on Windows")
+	t.Setenv(EnvPassphrase, "")
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "other.key")
+	assert.NilError(t, os.WriteFile(keyFile, []byte("passphrase"), 0o644))
+	assert.NilError(t, os.Chmod(keyFile, 0o644))
+	t.Setenv(EnvKeyFile, keyFile)
+
+	s := NewEncryptedStore(&fakeStore{configs: map[string]types.AuthConfig{}}, dir)
+	err := s.Store(types.AuthConfig{Username: "foo", Password: "bar", ServerAddress: "registry.example.com"})
+	assert.Check(t, is.ErrorContains(err, "permissions 0644 for "+keyFile+" are too open"))
+}
+
+func TestEncryptedStoreConcurrentUpdates(t *testing.T) {
+	t.Setenv(EnvPassphrase, "correct horse battery staple")
+	dir := t.TempDir()
+
+	// Each store is like a separate CLI process that updates the file.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := NewEncryptedStore(&fakeStore{configs: map[string]types.AuthConfig{}}, dir)
+			assert.Check(t, s.Store(types.AuthConfig{
+				Username:      "foo",
+				Password:      "bar",
+				ServerAddress: fmt.Sprintf("registry-%d.example.com", i),
+			}))
+		}(i)
+	}
+	wg.Wait()
+
+	all, err := NewEncryptedStore(&fakeStore{configs: map[string]types.AuthConfig{}}, dir).GetAll()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(all, 5))
+}
+
+func TestEncryptedStoreIterations(t *testing.T) {
+	t.Setenv(EnvPassphrase, "correct horse battery staple")
+	dir := t.TempDir()
+	s := NewEncryptedStore(&fakeStore{configs: map[string]types.AuthConfig{}}, dir).(*encryptedStore)
+
+	// A file that is encrypted with fewer iterations than the minimum can be
+	// read, but is encrypted with the minimum when it is updated.
+	ef := encryptedFile{Version: encryptedFileVersion, KDF: kdfPBKDF2SHA256, Iterations: 1000, Salt: []byte("0123456789abcdef")}
+	aead, err := s.cipher(&ef)
+	assert.NilError(t, err)
+	ef.Nonce = make([]byte, aead.NonceSize())
+	ef.Data = aead.Seal(nil, ef.Nonce, []byte(`{"registry.example.com":{"username":"foo","password":"bar"}}`), nil)
+	data, err := json.Marshal(ef)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(s.filename, data, 0o600))
+
+	auth, err := s.Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, "bar"))
+
+	assert.NilError(t, s.Store(types.AuthConfig{Username: "foo", Password: "baz", ServerAddress: "other.example.com"}))
+	_, updated, err := s.load()
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(updated.Iterations, kdfIterations))
+	assert.Check(t, string(updated.Salt) != "0123456789abcdef")
+}
+
+func TestEncryptedStoreWithoutConfigFile(t *testing.T) {
+	t.Setenv(EnvPassphrase, "correct horse battery staple")
+	t.Chdir(t.TempDir())
+	s := NewEncryptedStore(&fakeStore{configs: map[string]types.AuthConfig{}}, "")
+
+	err := s.Store(types.AuthConfig{Username: "foo", Password: "bar", ServerAddress: "registry.example.com"})
+	assert.Check(t, is.Error(err, "the encrypted credentials store requires a config file"))
+	_, err = s.Get("registry.example.com")
+	assert.Check(t, is.Error(err, "the encrypted credentials store requires a config file"))
+	_, err = os.Stat(encryptedFileName)
+	assert.Check(t, os.IsNotExist(err))
+}
@@ -0,0 +1,329 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/sirupsen/logrus"
+)
+
+// EnvHelperCacheTTL is the name of the environment variable that enables
+// caching the credentials that are returned by credential helpers on disk,
+// for the given duration (for example, "30s"). The duration is capped at
+// [maxHelperCacheTTL].
+const EnvHelperCacheTTL = "DOCKER_CREDENTIAL_CACHE_TTL"
+
+const (
+	// maxHelperCacheTTL is the maximum duration to cache credentials on disk.
+	maxHelperCacheTTL = 10 * time.Minute
+
+	// helperCacheDir is the directory, relative to the directory of the config
+	// file, that credentials are cached in.
+	helperCacheDir = "credentials-cache"
+
+	// helperCacheKeyURL is the server URL under which the key to encrypt the
+	// cache on disk with is stored in the credential helper itself, so that
+	// the cache can only be read by who can read the credential helper. It is
+	// not a registry, and the native store hides it from its callers.
+	helperCacheKeyURL      = "https://credentials-cache.docker.invalid"
+	helperCacheKeyUsername = "docker-cli"
+)
+
+// helperCaches holds the cache for each credential helper and config file.
+// The caches live for the duration of the CLI invocation.
+var helperCaches sync.Map
+
+// helperCacheID identifies the cache of a credential helper in helperCaches.
+type helperCacheID struct {
+	program    string
+	configFile string
+}
+
+// helperCache caches the results of a credential helper, so that commands
+// that look up credentials for many registries don't have to run the helper
+// for each lookup. Failures of the helper are never cached.
+type helperCache struct {
+	mu      sync.Mutex
+	creds   map[string]types.AuthConfig
+	list    map[string]string
+	hasList bool
+
+	// On-disk cache; the file is empty if the on-disk cache is disabled.
+	file     string
+	ttl      time.Duration
+	loaded   bool
+	key      []byte
+	fetched  map[string]time.Time
+	listedAt time.Time
+}
+
+// getHelperCache returns the cache for the given credential helper program
+// and config file. It enables the on-disk cache in the directory of the
+// config file if [EnvHelperCacheTTL] is set. Config files that are not
+// stored in a file, such as in-memory configs, don't share their cache.
+func getHelperCache(program, configFile string) *helperCache {
+	if configFile == "" {
+		return newHelperCache(program, configFile)
+	}
+	id := helperCacheID{program: program, configFile: configFile}
+	if c, ok := helperCaches.Load(id); ok {
+		return c.(*helperCache)
+	}
+	c, _ := helperCaches.LoadOrStore(id, newHelperCache(program, configFile))
+	return c.(*helperCache)
+}
+
+func newHelperCache(program, configFile string) *helperCache {
+	c := &helperCache{
+		creds:   make(map[string]types.AuthConfig),
+		fetched: make(map[string]time.Time),
+	}
+	if ttl := helperCacheTTL(); ttl > 0 && configFile != "" {
+		c.ttl = ttl
+		c.file = filepath.Join(filepath.Dir(configFile), helperCacheDir, program+".enc")
+	}
+	return c
+}
+
+func helperCacheTTL() time.Duration {
+	v := os.Getenv(EnvHelperCacheTTL)
+	if v == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil || ttl < 0 {
+		logrus.WithField("value", v).Debugf("ignoring invalid %s", EnvHelperCacheTTL)
+		return 0
+	}
+	return min(ttl, maxHelperCacheTTL)
+}
+
+// get returns the cached credentials for the given server.
+func (c *helperCache) get(programFunc client.ProgramFunc, serverAddress string) (types.AuthConfig, bool) {
+	if c == nil {
+		return types.AuthConfig{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked(programFunc)
+	ac, ok := c.creds[serverAddress]
+	return ac, ok
+}
+
+// getList returns the cached listing of the credentials in the helper.
+func (c *helperCache) getList(programFunc client.ProgramFunc) (map[string]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked(programFunc)
+	return c.list, c.hasList
+}
+
+// set caches the credentials for the given server.
+func (c *helperCache) set(programFunc client.ProgramFunc, serverAddress string, ac types.AuthConfig) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked(programFunc)
+	c.creds[serverAddress] = ac
+	c.fetched[serverAddress] = time.Now()
+	c.saveLocked(programFunc)
+}
+
+// setList caches the listing of the credentials in the helper.
+func (c *helperCache) setList(programFunc client.ProgramFunc, list map[string]string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked(programFunc)
+	c.list, c.hasList, c.listedAt = list, true, time.Now()
+	c.saveLocked(programFunc)
+}
+
+// invalidate removes the credentials for the given server from the cache,
+// after they were changed or removed.
+func (c *helperCache) invalidate(programFunc client.ProgramFunc, serverAddress string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked(programFunc)
+	delete(c.creds, serverAddress)
+	delete(c.fetched, serverAddress)
+	c.list, c.hasList = nil, false
+	c.saveLocked(programFunc)
+}
+
+// helperCacheFile is the format of the on-disk cache. The cached credentials
+// are encrypted with AES-GCM, using a key that is stored in the credential
+// helper.
+type helperCacheFile struct {
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+type cachedCredentials struct {
+	AuthConfig types.AuthConfig `json:"authConfig"`
+	Fetched    time.Time        `json:"fetched"`
+}
+
+type helperCacheData struct {
+	Creds    map[string]cachedCredentials `json:"creds,omitempty"`
+	List     map[string]string            `json:"list,omitempty"`
+	ListedAt time.Time                    `json:"listedAt,omitzero"`
+}
+
+// loadLocked loads the unexpired entries of the on-disk cache into memory,
+// the first time the cache is used. Errors are ignored, and the credentials
+// are fetched from the helper instead.
+func (c *helperCache) loadLocked(programFunc client.ProgramFunc) {
+	if c.file == "" || c.loaded {
+		return
+	}
+	c.loaded = true
+	data, err := c.readFile(programFunc)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.WithError(err).WithField("file", c.file).Debug("ignoring credentials cache")
+		}
+		return
+	}
+	now := time.Now()
+	for server, cc := range data.Creds {
+		if _, ok := c.creds[server]; !ok && now.Sub(cc.Fetched) < c.ttl {
+			c.creds[server] = cc.AuthConfig
+			c.fetched[server] = cc.Fetched
+		}
+	}
+	if !c.hasList && data.List != nil && now.Sub(data.ListedAt) < c.ttl {
+		c.list, c.hasList, c.listedAt = data.List, true, data.ListedAt
+	}
+}
+
+func (c *helperCache) readFile(programFunc client.ProgramFunc) (*helperCacheData, error) {
+	raw, err := os.ReadFile(c.file)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPermissions(c.file); err != nil {
+		return nil, err
+	}
+	var f helperCacheFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, err
+	}
+	aead, err := c.cipher(programFunc, false)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, err
+	}
+	var data helperCacheData
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// saveLocked writes the unexpired entries of the cache to disk. Errors are
+// ignored, as the cache is only an optimization, but disable the on-disk
+// cache for the rest of the invocation.
+func (c *helperCache) saveLocked(programFunc client.ProgramFunc) {
+	if c.file == "" {
+		return
+	}
+	if err := c.writeFile(programFunc); err != nil {
+		logrus.WithError(err).WithField("file", c.file).Debug("failed to write credentials cache")
+		c.file = ""
+	}
+}
+
+func (c *helperCache) writeFile(programFunc client.ProgramFunc) error {
+	now := time.Now()
+	data := helperCacheData{Creds: make(map[string]cachedCredentials)}
+	for server, ac := range c.creds {
+		if fetched := c.fetched[server]; now.Sub(fetched) < c.ttl {
+			data.Creds[server] = cachedCredentials{AuthConfig: ac, Fetched: fetched}
+		}
+	}
+	if c.hasList && now.Sub(c.listedAt) < c.ttl {
+		data.List, data.ListedAt = c.list, c.listedAt
+	}
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	aead, err := c.cipher(programFunc, true)
+	if err != nil {
+		return err
+	}
+	f := helperCacheFile{Nonce: make([]byte, aead.NonceSize())}
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = aead.Seal(nil, f.Nonce, plaintext, nil)
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.file, raw)
+}
+
+// cipher returns the AEAD to encrypt and decrypt the on-disk cache with. The
+// key is read from the credential helper, and created if create is true and
+// the helper does not have a key yet.
+func (c *helperCache) cipher(programFunc client.ProgramFunc, create bool) (cipher.AEAD, error) {
+	if c.key == nil {
+		creds, err := client.Get(programFunc, helperCacheKeyURL)
+		switch {
+		case err == nil:
+			c.key, err = base64.StdEncoding.DecodeString(creds.Secret)
+			if err != nil {
+				return nil, err
+			}
+		case credentials.IsErrCredentialsNotFound(err) && create:
+			key := make([]byte, keySize)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			if err := client.Store(programFunc, &credentials.Credentials{
+				ServerURL: helperCacheKeyURL,
+				Username:  helperCacheKeyUsername,
+				Secret:    base64.StdEncoding.EncodeToString(key),
+			}); err != nil {
+				return nil, err
+			}
+			c.key = key
+		default:
+			return nil, err
+		}
+	}
+	if len(c.key) != keySize {
+		return nil, errors.New("invalid key for credentials cache")
+	}
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
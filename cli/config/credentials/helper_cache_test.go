@@ -0,0 +1,197 @@
+package credentials
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// fakeHelper is a credential helper that keeps credentials in memory, and
+// counts how often it is run for each action.
+type fakeHelper struct {
+	creds map[string]credentials.Credentials
+	calls map[string]int
+	fail  bool
+}
+
+func newFakeHelper() *fakeHelper {
+	return &fakeHelper{
+		creds: make(map[string]credentials.Credentials),
+		calls: make(map[string]int),
+	}
+}
+
+func (h *fakeHelper) programFunc(args ...string) client.Program {
+	return &fakeHelperCommand{helper: h, arg: args[0]}
+}
+
+type fakeHelperCommand struct {
+	helper *fakeHelper
+	arg    string
+	input  io.Reader
+}
+
+func (m *fakeHelperCommand) Input(in io.Reader) {
+	m.input = in
+}
+
+func (m *fakeHelperCommand) Output() ([]byte, error) {
+	in, err := io.ReadAll(m.input)
+	if err != nil {
+		return nil, err
+	}
+	h := m.helper
+	h.calls[m.arg]++
+	if h.fail {
+		return []byte("program failed"), errCommandExited
+	}
+	switch m.arg {
+	case "store":
+		var c credentials.Credentials
+		if err := json.Unmarshal(in, &c); err != nil {
+			return nil, err
+		}
+		h.creds[c.ServerURL] = c
+		return nil, nil
+	case "get":
+		c, ok := h.creds[strings.TrimSpace(string(in))]
+		if !ok {
+			return []byte(credentials.NewErrCredentialsNotFound().Error()), errCommandExited
+		}
+		return json.Marshal(c)
+	case "erase":
+		delete(h.creds, strings.TrimSpace(string(in)))
+		return nil, nil
+	case "list":
+		list := make(map[string]string)
+		for url, c := range h.creds {
+			list[url] = c.Username
+		}
+		return json.Marshal(list)
+	}
+	return nil, errCommandExited
+}
+
+func TestNativeStoreCache(t *testing.T) {
+	t.Setenv(EnvHelperCacheTTL, "")
+	h := newFakeHelper()
+	h.creds[validServerAddress] = credentials.Credentials{ServerURL: validServerAddress, Username: "foo", Secret: "bar"}
+	s := &nativeStore{
+		programFunc: h.programFunc,
+		fileStore:   NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{}}),
+		cache:       newHelperCache("docker-credential-fake", "config.json"),
+	}
+
+	for range 2 {
+		auth, err := s.Get(validServerAddress)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(auth.Password, "bar"))
+		auth, err = s.Get(missingCredsAddress)
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(auth, types.AuthConfig{}))
+		all, err := s.GetAll()
+		assert.NilError(t, err)
+		assert.Check(t, is.Len(all, 1))
+	}
+	assert.Check(t, is.DeepEqual(h.calls, map[string]int{"get": 2, "list": 1}))
+
+	// Storing credentials invalidates the cache.
+	assert.NilError(t, s.Store(types.AuthConfig{Username: "foo", Password: "baz", ServerAddress: validServerAddress}))
+	auth, err := s.Get(validServerAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, "baz"))
+	assert.Check(t, is.Equal(h.calls["get"], 3))
+
+	// Failures are not cached.
+	h.fail = true
+	_, err = s.Get(invalidServerAddress)
+	assert.Check(t, is.ErrorContains(err, "program failed"))
+	_, err = s.Get(invalidServerAddress)
+	assert.Check(t, is.ErrorContains(err, "program failed"))
+	assert.Check(t, is.Equal(h.calls["get"], 5))
+}
+
+func TestNativeStoreDiskCache(t *testing.T) {
+	t.Setenv(EnvHelperCacheTTL, "1m")
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	h := newFakeHelper()
+	h.creds[validServerAddress] = credentials.Credentials{ServerURL: validServerAddress, Username: "foo", Secret: "bar"}
+
+	// newStore returns the store for a new invocation of the CLI.
+	newStore := func() *nativeStore {
+		return &nativeStore{
+			programFunc: h.programFunc,
+			fileStore:   NewFileStore(&fakeStore{configs: map[string]types.AuthConfig{}}),
+			cache:       newHelperCache("docker-credential-fake", configFile),
+		}
+	}
+
+	auth, err := newStore().Get(validServerAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, "bar"))
+
+	// The cache is encrypted with a key that is stored in the helper.
+	cacheFile := filepath.Join(filepath.Dir(configFile), "credentials-cache", "docker-credential-fake.enc")
+	data, err := os.ReadFile(cacheFile)
+	assert.NilError(t, err)
+	assert.Check(t, !is.Contains(string(data), "bar")().Success())
+	assert.Check(t, is.Contains(h.creds, helperCacheKeyURL))
+
+	// The key is not listed as a credential, and can't be read, changed, or
+	// removed as one.
+	all, err := newStore().GetAll()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(all, 1))
+	auth, err = newStore().Get(helperCacheKeyURL)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(auth, types.AuthConfig{}))
+	err = newStore().Store(types.AuthConfig{Username: "foo", Password: "bar", ServerAddress: helperCacheKeyURL})
+	assert.Check(t, is.ErrorIs(err, errReservedServerAddress))
+	err = newStore().Erase(helperCacheKeyURL)
+	assert.Check(t, is.ErrorIs(err, errReservedServerAddress))
+	assert.Check(t, is.Contains(h.creds, helperCacheKeyURL))
+
+	h.calls = make(map[string]int)
+	s := newStore()
+	auth, err = s.Get(validServerAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, "bar"))
+	assert.Check(t, is.DeepEqual(h.calls, map[string]int{"get": 1}), "only the key should be read from the helper")
+
+	// Erasing credentials removes them from the cache on disk.
+	assert.NilError(t, s.Erase(validServerAddress))
+	auth, err = newStore().Get(validServerAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(auth, types.AuthConfig{}))
+
+	// Expired entries are not used.
+	h.creds[validServerAddress] = credentials.Credentials{ServerURL: validServerAddress, Username: "foo", Secret: "new"}
+	t.Setenv(EnvHelperCacheTTL, "1ns")
+	auth, err = newStore().Get(validServerAddress)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(auth.Password, "new"))
+}
+
+func TestGetHelperCache(t *testing.T) {
+	t.Setenv(EnvHelperCacheTTL, "")
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	otherConfigFile := filepath.Join(dir, "other", "config.json")
+
+	c := getHelperCache("docker-credential-fake", configFile)
+	assert.Check(t, c == getHelperCache("docker-credential-fake", configFile))
+	assert.Check(t, c != getHelperCache("docker-credential-other", configFile))
+	assert.Check(t, c != getHelperCache("docker-credential-fake", otherConfigFile))
+
+	// Configs that are not stored in a file don't share their cache.
+	assert.Check(t, getHelperCache("docker-credential-fake", "") != getHelperCache("docker-credential-fake", ""))
+}
@@ -1,6 +1,8 @@
 package credentials
 
 import (
+	"errors"
+
 	"github.com/docker/cli/cli/config/types"
 	"github.com/docker/docker-credential-helpers/client"
 	"github.com/docker/docker-credential-helpers/credentials"
@@ -11,27 +13,54 @@
 	tokenUsername           = "<token>"
 )
 
+// errReservedServerAddress is returned when storing or erasing credentials
+// for the server address under which the key of the on-disk cache is stored.
+var errReservedServerAddress = errors.New(helperCacheKeyURL + " is reserved for the credential helper cache")
+
 // nativeStore implements a credentials store
 // using native keychain to keep credentials secure.
 // It piggybacks into a file store to keep users' emails.
 type nativeStore struct {
 	programFunc client.ProgramFunc
 	fileStore   Store
+	cache       *helperCache // nil if results are not cached
 }
 
 // NewNativeStore creates a new native store that
 // uses a remote helper program to manage credentials.
+// The results of the helper program are cached for
+// the duration of the CLI invocation.
 func NewNativeStore(file store, helperSuffix string) Store {
 	name := remoteCredentialsPrefix + helperSuffix
 	return &nativeStore{
 		programFunc: client.NewShellProgramFunc(name),
 		fileStore:   NewFileStore(file),
+		cache:       getHelperCache(name, file.GetFilename()),
+	}
+}
+
+// InvalidateCache removes the credentials for the given server addresses from
+// the cache of the given store, so that they are read from the credential
+// helper again, for example after they were changed by another process. It
+// does nothing for stores that are not a native store.
+func InvalidateCache(store Store, serverAddresses ...string) {
+	c, ok := store.(*nativeStore)
+	if !ok {
+		return
+	}
+	for _, serverAddress := range serverAddresses {
+		c.cache.invalidate(c.programFunc, serverAddress)
 	}
 }
 
 // Erase removes the given credentials from the native store.
 func (c *nativeStore) Erase(serverAddress string) error {
-	if err := client.Erase(c.programFunc, serverAddress); err != nil {
+	if serverAddress == helperCacheKeyURL {
+		return errReservedServerAddress
+	}
+	err := client.Erase(c.programFunc, serverAddress)
+	c.cache.invalidate(c.programFunc, serverAddress)
+	if err != nil {
 		return err
 	}
 
@@ -88,6 +117,9 @@ func (c *nativeStore) GetAll() (map[string]types.AuthConfig, error) {
 
 // Store saves the given credentials in the file store.
 func (c *nativeStore) Store(authConfig types.AuthConfig) error {
+	if authConfig.ServerAddress == helperCacheKeyURL {
+		return errReservedServerAddress
+	}
 	if err := c.storeCredentialsInStore(authConfig); err != nil {
 		return err
 	}
@@ -112,11 +144,20 @@ func (c *nativeStore) storeCredentialsInStore(config types.AuthConfig) error {
 		creds.Secret = config.IdentityToken
 	}
 
-	return client.Store(c.programFunc, creds)
+	err := client.Store(c.programFunc, creds)
+	c.cache.invalidate(c.programFunc, config.ServerAddress)
+	return err
 }
 
 // getCredentialsFromStore executes the command to get the credentials from the native store.
 func (c *nativeStore) getCredentialsFromStore(serverAddress string) (types.AuthConfig, error) {
+	if serverAddress == helperCacheKeyURL {
+		// The key for the on-disk cache is not a registry credential.
+		return types.AuthConfig{}, nil
+	}
+	if ret, ok := c.cache.get(c.programFunc, serverAddress); ok {
+		return ret, nil
+	}
 	var ret types.AuthConfig
 
 	creds, err := client.Get(c.programFunc, serverAddress)
@@ -124,6 +165,7 @@ func (c *nativeStore) getCredentialsFromStore(serverAddress string) (types.AuthC
 		if credentials.IsErrCredentialsNotFound(err) {
 			// do not return an error if the credentials are not
 			// in the keychain. Let docker ask for new credentials.
+			c.cache.set(c.programFunc, serverAddress, ret)
 			return ret, nil
 		}
 		return ret, err
@@ -137,11 +179,22 @@ func (c *nativeStore) getCredentialsFromStore(serverAddress string) (types.AuthC
 	}
 
 	ret.ServerAddress = serverAddress
+	c.cache.set(c.programFunc, serverAddress, ret)
 	return ret, nil
 }
 
 // listCredentialsInStore returns a listing of stored credentials as a map of
 // URL -> username.
 func (c *nativeStore) listCredentialsInStore() (map[string]string, error) {
-	return client.List(c.programFunc)
+	if list, ok := c.cache.getList(c.programFunc); ok {
+		return list, nil
+	}
+	list, err := client.List(c.programFunc)
+	if err != nil {
+		return nil, err
+	}
+	// The key for the on-disk cache is not a registry credential.
+	delete(list, helperCacheKeyURL)
+	c.cache.setList(c.programFunc, list)
+	return list, nil
 }
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/docker/cli/cli/config/configfile"
+)
+
+// DefaultProfileName is the name that is reserved to use no profile.
+const DefaultProfileName = "default"
+
+// profileIgnoredFields are the fields that are ignored in profiles. Profiles
+// cannot select or define other profiles, and credentials are stored in the
+// config file itself, or in a credentials store.
+var profileIgnoredFields = []string{
+	"auths",
+	"contextProfiles",
+	"currentProfile",
+	"profiles",
+}
+
+var validProfileName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.+-]*$`)
+
+// Profile describes a profile of the config file.
+type Profile struct {
+	Name string
+	// Filename is the file that the profile is defined in: either the
+	// config file, or a "config.<name>.json" file in the same directory.
+	Filename string
+}
+
+// ValidateProfileName checks whether the given name can be used as the name
+// of a profile.
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return errors.New("profile name cannot be empty")
+	}
+	if name == DefaultProfileName {
+		return errors.New(`"default" is a reserved profile name`)
+	}
+	if !validProfileName.MatchString(name) {
+		return fmt.Errorf("profile name %q is invalid, names are validated against regexp %q", name, validProfileName.String())
+	}
+	return nil
+}
+
+// Profiles returns the profiles of the config file, sorted by name. Profiles
+// are defined in the "profiles" property of the config file, or in
+// "config.<name>.json" files in the same directory as the config file.
+// Profiles in the config file take precedence over files.
+func Profiles(configFile *configfile.ConfigFile) ([]Profile, error) {
+	var profiles []Profile
+	for name := range configFile.Profiles {
+		profiles = append(profiles, Profile{Name: name, Filename: configFile.Filename})
+	}
+	if configFile.Filename != "" {
+		matches, err := filepath.Glob(profileFilename(configFile, "*"))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), "config."), ".json")
+			if _, ok := configFile.Profiles[name]; ok || ValidateProfileName(name) != nil {
+				continue
+			}
+			profiles = append(profiles, Profile{Name: name, Filename: m})
+		}
+	}
+	slices.SortFunc(profiles, func(a, b Profile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return profiles, nil
+}
+
+// LoadProfile merges the profile with the given name with the config file.
+// Its values take precedence over the values in the config file and its
+// other layers. It replaces the profile that was merged before, if any; the
+// "default" profile, or an empty name, only removes it.
+func LoadProfile(configFile *configfile.ConfigFile, name string) error {
+	layers := slices.DeleteFunc(slices.Clone(configFile.Layers()), func(l *configfile.Layer) bool {
+		return l.Origin == configfile.OriginProfile
+	})
+	if name != "" && name != DefaultProfileName {
+		l, err := GetProfile(configFile, name)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, l)
+	}
+	return configFile.SetLayers(layers...)
+}
+
+// ActiveProfile returns the name of the profile that is merged with the
+// config file, or "default" if no profile is used.
+func ActiveProfile(configFile *configfile.ConfigFile) string {
+	for _, l := range configFile.Layers() {
+		if l.Origin == configfile.OriginProfile {
+			return l.Name
+		}
+	}
+	return DefaultProfileName
+}
+
+// profileNotFoundErr is the error returned when a profile could not be found.
+type profileNotFoundErr string
+
+func (profileNotFoundErr) NotFound() {}
+
+func (e profileNotFoundErr) Error() string {
+	return fmt.Sprintf("profile %q not found", string(e))
+}
+
+// GetProfile reads the profile with the given name.
+func GetProfile(configFile *configfile.ConfigFile, name string) (*configfile.Layer, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+	var l *configfile.Layer
+	if content, ok := configFile.Profiles[name]; ok {
+		data, err := json.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		l, err = configfile.LoadLayer(configfile.OriginProfile, configFile.Filename, true, bytes.NewReader(data), profileIgnoredFields...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing profile %q (%s): %w", name, configFile.Filename, err)
+		}
+	} else if configFile.Filename != "" {
+		var err error
+		l, err = loadLayer(configfile.OriginProfile, profileFilename(configFile, name), true, profileIgnoredFields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if l == nil {
+		return nil, profileNotFoundErr(name)
+	}
+	l.Name = name
+	return l, nil
+}
+
+// profileFilename returns the file for the profile with the given name, in
+// the same directory as the config file.
+func profileFilename(configFile *configfile.ConfigFile, name string) string {
+	return filepath.Join(filepath.Dir(configFile.Filename), "config."+name+".json")
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/cli/cli/config/configfile"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, ConfigFileName)
+	assert.NilError(t, os.WriteFile(filename, []byte(`{
+	"psFormat": "user",
+	"proxies": {"default": {"httpProxy": "http://user.example.com"}},
+	"profiles": {
+		"dev": {"psFormat": "dev", "currentProfile": "ci"}
+	}
+}`), 0o600))
+	ciFile := filepath.Join(dir, "config.ci.json")
+	assert.NilError(t, os.WriteFile(ciFile, []byte(`{
+	"proxies": {"default": {"httpProxy": "http://ci.example.com"}},
+	"currentContext": "ci"
+}`), 0o600))
+	// Profiles in the config file take precedence over files.
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "config.dev.json"), []byte(`{}`), 0o600))
+
+	configFile, err := load(dir)
+	assert.NilError(t, err)
+	profiles, err := Profiles(configFile)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(profiles, []Profile{
+		{Name: "ci", Filename: ciFile},
+		{Name: "dev", Filename: filename},
+	}))
+	assert.Check(t, is.Equal(ActiveProfile(configFile), DefaultProfileName))
+
+	assert.NilError(t, LoadProfile(configFile, "dev"))
+	assert.Check(t, is.Equal(ActiveProfile(configFile), "dev"))
+	assert.Check(t, is.Equal(configFile.PsFormat, "dev"))
+	assert.Check(t, is.Equal(configFile.CurrentProfile, ""))
+
+	// Loading another profile replaces the profile.
+	assert.NilError(t, LoadProfile(configFile, "ci"))
+	assert.Check(t, is.Equal(ActiveProfile(configFile), "ci"))
+	assert.Check(t, is.Equal(configFile.PsFormat, "user"))
+	assert.Check(t, is.Equal(configFile.CurrentContext, "ci"))
+	assert.Check(t, is.Equal(configFile.Proxies["default"].HTTPProxy, "http://ci.example.com"))
+
+	// Values of the profile are not saved to the config file.
+	configFile.StatsFormat = "user"
+	assert.NilError(t, configFile.Save())
+	data, err := os.ReadFile(filename)
+	assert.NilError(t, err)
+	assert.Check(t, !is.Contains(string(data), "ci.example.com")().Success())
+	assert.Check(t, is.Contains(string(data), `"statsFormat": "user"`))
+
+	assert.NilError(t, LoadProfile(configFile, DefaultProfileName))
+	assert.Check(t, is.Equal(ActiveProfile(configFile), DefaultProfileName))
+	assert.Check(t, is.Equal(configFile.CurrentContext, ""))
+}
+
+func TestGetProfile(t *testing.T) {
+	configFile := configfile.New(filepath.Join(t.TempDir(), ConfigFileName))
+	configFile.Profiles = map[string]map[string]any{
+		"dev": {"psFormat": "dev", "profiles": map[string]any{"nested": map[string]any{}}, "auths": map[string]any{"example.com": map[string]any{}}},
+	}
+
+	profile, err := GetProfile(configFile, "dev")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(profile.Name, "dev"))
+	assert.Check(t, is.Equal(profile.Origin, configfile.OriginProfile))
+	assert.Check(t, is.DeepEqual(profile.Content(), map[string]any{"psFormat": "dev"}))
+	assert.Check(t, is.DeepEqual(profile.Ignored, []string{"auths", "profiles"}))
+
+	_, err = GetProfile(configFile, "prod")
+	assert.Check(t, is.Error(err, `profile "prod" not found`))
+	assert.Check(t, errdefs.IsNotFound(err))
+
+	_, err = GetProfile(configFile, DefaultProfileName)
+	assert.Check(t, is.Error(err, `"default" is a reserved profile name`))
+	_, err = GetProfile(configFile, "../prod")
+	assert.Check(t, is.ErrorContains(err, `profile name "../prod" is invalid`))
+}
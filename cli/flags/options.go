@@ -96,6 +96,7 @@ type ClientOptions struct {
 	TLSVerify  bool
 	TLSOptions *tlsconfig.Options
 	Context    string
+	Profile    string
 	ConfigDir  string
 }
 
@@ -130,6 +131,7 @@ func (o *ClientOptions) InstallFlags(flags *pflag.FlagSet) {
 	flags.VarP(&hostVar{dst: &o.Hosts}, "host", "H", "Daemon socket to connect to")
 	flags.StringVarP(&o.Context, "context", "c", "",
 		`Name of the context to use to connect to the daemon (overrides `+client.EnvOverrideHost+` env var and default context set with "docker context use")`)
+	flags.StringVar(&o.Profile, "profile", "", `Name of the configuration profile to use (overrides DOCKER_PROFILE env var and default profile set with "docker profile use")`)
 }
 
 // SetDefaultOptions sets default values for options after flag parsing is
@@ -21,8 +21,15 @@ type Store interface {
 	Get(listRef reference.Reference, manifest reference.Reference) (types.ImageManifest, error)
 	GetList(listRef reference.Reference) ([]types.ImageManifest, error)
 	Save(listRef reference.Reference, manifest reference.Reference, image types.ImageManifest) error
+	GetIndex(listRef reference.Reference) (types.ImageIndex, error)
+	SaveIndex(listRef reference.Reference, index types.ImageIndex) error
 }
 
+// indexFilename is the name of the file in the directory of a manifest list
+// that stores the properties of the list itself. References cannot start with
+// a dot, so it does not conflict with the files of the manifests in the list.
+const indexFilename = ".index.json"
+
 // fsStore manages manifest files stored on the local filesystem
 type fsStore struct {
 	root string
@@ -121,6 +128,9 @@ func (s *fsStore) listManifests(transaction string) ([]string, error) {
 
 	filenames := make([]string, 0, len(fileInfos))
 	for _, info := range fileInfos {
+		if info.Name() == indexFilename {
+			continue
+		}
 		filenames = append(filenames, info.Name())
 	}
 	return filenames, nil
@@ -139,6 +149,35 @@ func (s *fsStore) Save(listRef reference.Reference, manifest reference.Reference
 	return os.WriteFile(filename, bytes, 0o644)
 }
 
+// GetIndex returns the properties of a local manifest list. The zero value is
+// returned for a manifest list that was created without any.
+func (s *fsStore) GetIndex(listRef reference.Reference) (types.ImageIndex, error) {
+	bytes, err := os.ReadFile(filepath.Join(s.root, makeFilesafeName(listRef.String()), indexFilename))
+	switch {
+	case os.IsNotExist(err):
+		return types.ImageIndex{}, nil
+	case err != nil:
+		return types.ImageIndex{}, err
+	}
+	var index types.ImageIndex
+	if err := json.Unmarshal(bytes, &index); err != nil {
+		return types.ImageIndex{}, err
+	}
+	return index, nil
+}
+
+// SaveIndex saves the properties of a local manifest list
+func (s *fsStore) SaveIndex(listRef reference.Reference, index types.ImageIndex) error {
+	if err := s.createManifestListDirectory(listRef.String()); err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.root, makeFilesafeName(listRef.String()), indexFilename), bytes, 0o644)
+}
+
 func (s *fsStore) createManifestListDirectory(transaction string) error {
 	path := filepath.Join(s.root, makeFilesafeName(transaction))
 	return os.MkdirAll(path, 0o755)
@@ -8,6 +8,7 @@
 	"github.com/distribution/reference"
 	"github.com/docker/cli/cli/manifest/types"
 	"github.com/google/go-cmp/cmp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 )
@@ -120,3 +121,28 @@ func TestStoreGetListDoesNotExist(t *testing.T) {
 	assert.Error(t, err, "No such manifest: list")
 	assert.Check(t, errdefs.IsNotFound(err))
 }
+
+func TestStoreSaveAndGetIndex(t *testing.T) {
+	store := NewStore(t.TempDir())
+	listRef := ref("list")
+
+	index, err := store.GetIndex(listRef)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(index, types.ImageIndex{}))
+
+	assert.NilError(t, store.Save(listRef, ref("first"), types.ImageManifest{Ref: sref(t, "first")}))
+	expected := types.ImageIndex{
+		MediaType:   ocispec.MediaTypeImageIndex,
+		Annotations: map[string]string{"org.opencontainers.image.version": "1.0"},
+	}
+	assert.NilError(t, store.SaveIndex(listRef, expected))
+
+	index, err = store.GetIndex(listRef)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(index, expected))
+
+	// The index is not one of the manifests in the list.
+	list, err := store.GetList(listRef)
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(list, 1))
+}
@@ -13,6 +13,14 @@
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// Annotations that are set by BuildKit on attestation manifests in an image
+// index.
+const (
+	AnnotationReferenceType   = "vnd.docker.reference.type"
+	AnnotationReferenceDigest = "vnd.docker.reference.digest"
+	AttestationManifestType   = "attestation-manifest"
+)
+
 // ImageManifest contains info to output for a manifest object.
 type ImageManifest struct {
 	Ref        *SerializableNamed
@@ -25,6 +33,33 @@ type ImageManifest struct {
 	OCIManifest *ocischema.DeserializedManifest `json:",omitempty"`
 }
 
+// ImageIndex contains the properties of a local manifest list that are not
+// part of the manifests it references. The zero value describes a Docker
+// manifest list.
+type ImageIndex struct {
+	// MediaType is the media type of the manifest list; either a Docker
+	// manifest list, or an OCI image index. An empty value is equivalent to
+	// a Docker manifest list.
+	MediaType string `json:",omitempty"`
+
+	// ArtifactType, Subject and Annotations are only supported by an OCI
+	// image index.
+	ArtifactType string              `json:",omitempty"`
+	Subject      *ocispec.Descriptor `json:",omitempty"`
+	Annotations  map[string]string   `json:",omitempty"`
+}
+
+// IsOCI returns whether the manifest list is an OCI image index.
+func (i ImageIndex) IsOCI() bool {
+	return i.MediaType == ocispec.MediaTypeImageIndex
+}
+
+// IsAttestation returns whether the manifest is an attestation manifest, as
+// created by BuildKit, that refers to an image manifest in the same index.
+func (i ImageManifest) IsAttestation() bool {
+	return i.Descriptor.Annotations[AnnotationReferenceType] == AttestationManifestType
+}
+
 // OCIPlatform creates an OCI platform from a manifest list platform spec
 func OCIPlatform(ps *manifestlist.PlatformSpec) *ocispec.Platform {
 	if ps == nil {
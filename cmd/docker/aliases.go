@@ -1,44 +1,87 @@
+// FIXME(thaJeztah): remove once we are a module; the go:build directive prevents go from downgrading language version to go1.16:
+//go:build go1.25
+
 package main
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/docker/cli/cli"
 	pluginmanager "github.com/docker/cli/cli-plugins/manager"
 	"github.com/docker/cli/cli/command"
+	"github.com/google/shlex"
 	"github.com/spf13/cobra"
 )
 
 const (
 	keyBuilderAlias = "builder"
+
+	// shellAliasPrefix is the prefix of aliases that run a shell command,
+	// instead of a docker command.
+	shellAliasPrefix = "!"
+
+	// envShellAlias is set to the name of the alias in the environment of the
+	// shell commands of aliases, to prevent them from running shell aliases
+	// themselves, such as an alias "x" for "!docker x" that would otherwise
+	// run itself forever.
+	envShellAlias = "DOCKER_CLI_SHELL_ALIAS"
 )
 
-var allowedAliases = map[string]struct{}{
-	keyBuilderAlias: {},
-}
+var (
+	// validAliasName matches the names that can be used for aliases.
+	validAliasName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	// positionalArg matches references to positional arguments in aliases,
+	// such as "$1" or "${10}".
+	positionalArg = regexp.MustCompile(`\$(?:([1-9])|\{([1-9][0-9]*)\})`)
+
+	// reservedAliasNames are names that can't be used for aliases, in
+	// addition to the names of built-in commands.
+	reservedAliasNames = []string{"help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+)
 
 func processAliases(dockerCli command.Cli, cmd *cobra.Command, args, osArgs []string) ([]string, []string, []string, error) {
 	var err error
 	var envs []string
 	aliasMap := dockerCli.ConfigFile().Aliases
-	aliases := make([][2][]string, 0, len(aliasMap))
+	aliases := make([][2][]string, 0, 1)
 
-	for k, v := range aliasMap {
-		if _, ok := allowedAliases[k]; !ok {
-			return args, osArgs, envs, fmt.Errorf("not allowed to alias %q (allowed: %#v)", k, allowedAliases)
-		}
+	if v, ok := aliasMap[keyBuilderAlias]; ok {
 		if c, _, err := cmd.Find(strings.Split(v, " ")); err == nil {
 			if !pluginmanager.IsPluginCommand(c) {
 				return args, osArgs, envs, fmt.Errorf("not allowed to alias with builtin %q as target", v)
 			}
 		}
-		aliases = append(aliases, [2][]string{{k}, {v}})
+		aliases = append(aliases, [2][]string{{keyBuilderAlias}, {v}})
+	}
+
+	userAliases := loadUserAliases(dockerCli, cmd)
+	for _, name := range slices.Sorted(maps.Keys(userAliases)) {
+		cmd.AddCommand(newAliasCommand(dockerCli, userAliases, name))
+	}
+	if len(args) > 0 && !hasCompletionArg(args) {
+		expanded, err := expandAlias(userAliases, args)
+		if err != nil {
+			return args, osArgs, envs, err
+		}
+		// args are the arguments that follow the global options in osArgs.
+		if n := len(osArgs) - len(args); n >= 0 && slices.Equal(osArgs[n:], args) {
+			osArgs = slices.Concat(osArgs[:n], expanded)
+		}
+		args = expanded
 	}
 
-	args, osArgs, envs, err = processBuilder(dockerCli, cmd, args, os.Args)
+	args, osArgs, envs, err = processBuilder(dockerCli, cmd, args, osArgs)
 	if err != nil {
-		return args, os.Args, envs, err
+		return args, osArgs, envs, err
 	}
 
 	for _, al := range aliases {
@@ -52,3 +95,162 @@ func processAliases(dockerCli command.Cli, cmd *cobra.Command, args, osArgs []st
 
 	return args, osArgs, envs, nil
 }
+
+// loadUserAliases returns the aliases in the config file, except for the
+// "builder" alias. Aliases that would shadow a built-in command or an
+// installed plugin are ignored with a warning.
+func loadUserAliases(dockerCli command.Cli, root *cobra.Command) map[string]string {
+	aliases := make(map[string]string)
+	for name, value := range dockerCli.ConfigFile().Aliases {
+		if name == keyBuilderAlias {
+			continue
+		}
+		var reason string
+		switch {
+		case !validAliasName.MatchString(name):
+			reason = "invalid name"
+		case slices.Contains(reservedAliasNames, name):
+			reason = "it is a built-in command"
+		case strings.TrimSpace(strings.TrimPrefix(value, shellAliasPrefix)) == "":
+			reason = "it is empty"
+		default:
+			if c, _, err := root.Find([]string{name}); err == nil && c != root {
+				reason = "it is a built-in command"
+			} else if p, err := pluginmanager.GetPlugin(name, dockerCli, root); err == nil && p.Err == nil {
+				reason = "it is a plugin command"
+			}
+		}
+		if reason != "" {
+			_, _ = fmt.Fprintf(dockerCli.Err(), "WARNING: ignoring alias %q: %s\n", name, reason)
+			continue
+		}
+		aliases[name] = value
+	}
+	return aliases
+}
+
+// expandAlias expands the alias in args[0], if any, to the command it is an
+// alias for. Aliases that are expanded to other aliases are expanded as well.
+// Aliases that run a shell command are not expanded; they are run by their
+// alias command.
+func expandAlias(aliases map[string]string, args []string) ([]string, error) {
+	var chain []string
+	for {
+		value, ok := aliases[args[0]]
+		if !ok || strings.HasPrefix(value, shellAliasPrefix) {
+			return args, nil
+		}
+		if slices.Contains(chain, args[0]) {
+			return nil, fmt.Errorf("alias loop detected: %s", strings.Join(append(chain, args[0]), " -> "))
+		}
+		chain = append(chain, args[0])
+
+		expanded, err := expandAliasArgs(args[0], value, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		args = expanded
+	}
+}
+
+// expandAliasArgs expands an alias with the given arguments. References to
+// positional arguments ("$1", "${10}") are substituted, and "$@" expands to
+// all arguments. If the alias has no such references, the arguments are
+// appended to the alias.
+func expandAliasArgs(name, value string, args []string) ([]string, error) {
+	words, err := shlex.Split(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias %q: %w", name, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("invalid alias %q: it is empty", name)
+	}
+	var expanded []string
+	var positional bool
+	var missing int
+	for _, w := range words {
+		if w == "$@" {
+			positional = true
+			expanded = append(expanded, args...)
+			continue
+		}
+		w = positionalArg.ReplaceAllStringFunc(w, func(ref string) string {
+			positional = true
+			m := positionalArg.FindStringSubmatch(ref)
+			n, _ := strconv.Atoi(m[1] + m[2])
+			if n > len(args) {
+				missing = max(missing, n)
+				return ""
+			}
+			return args[n-1]
+		})
+		expanded = append(expanded, w)
+	}
+	if missing > 0 {
+		return nil, fmt.Errorf("alias %q requires at least %d argument(s)", name, missing)
+	}
+	if !positional {
+		expanded = append(expanded, args...)
+	}
+	return expanded, nil
+}
+
+// newAliasCommand returns the command for an alias, which shows the alias in
+// help and completion, and runs aliases of shell commands.
+func newAliasCommand(dockerCli command.Cli, aliases map[string]string, name string) *cobra.Command {
+	value := aliases[name]
+	short := "Alias for: " + value
+	if script, ok := strings.CutPrefix(value, shellAliasPrefix); ok {
+		short = "Alias for shell command: " + script
+	}
+	return &cobra.Command{
+		Use:                name + " [ARG...]",
+		Short:              short,
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{"user-alias": value},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			script, ok := strings.CutPrefix(value, shellAliasPrefix)
+			if !ok {
+				// Aliases are expanded before the command is run; this is
+				// only reached for aliases that could not be expanded.
+				return fmt.Errorf("alias %q could not be expanded", name)
+			}
+			return runShellAlias(dockerCli, name, script, args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			expanded, err := expandAlias(aliases, append([]string{name}, args...))
+			if err != nil || expanded[0] == name {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			target, targetArgs, err := cmd.Root().Find(expanded)
+			if err != nil || target == cmd.Root() || target.ValidArgsFunction == nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return target.ValidArgsFunction(target, targetArgs, toComplete)
+		},
+	}
+}
+
+// runShellAlias runs the shell command of an alias. The arguments are
+// passed to the shell as positional parameters. Shell aliases can't be run
+// from the shell command of another alias.
+func runShellAlias(dockerCli command.Cli, name, script string, args []string) error {
+	if parent := os.Getenv(envShellAlias); parent != "" {
+		return fmt.Errorf("refusing to run alias %q from the shell command of alias %q", name, parent)
+	}
+	c := exec.Command("sh", append([]string{"-c", script, name}, args...)...)
+	// Using dockerCli.In() results in a hang until something is input; see
+	// the comment in [pluginmanager.PluginRunCommand].
+	c.Stdin = os.Stdin
+	c.Stdout = dockerCli.Out()
+	c.Stderr = dockerCli.Err()
+	c.Env = append(os.Environ(), envShellAlias+"="+name)
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to run alias %q: %w", name, err)
+		}
+		return cli.StatusError{StatusCode: exitErr.ExitCode()}
+	}
+	return nil
+}
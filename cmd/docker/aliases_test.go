@@ -0,0 +1,162 @@
+// FIXME(thaJeztah): remove once we are a module; the go:build directive prevents go from downgrading language version to go1.16:
+//go:build go1.25
+
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/flags"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
+)
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"lsa":   `container ls -a --format 'table {{.Names}}\t{{.Status}}'`,
+		"l":     "lsa",
+		"sh":    "exec -it $1 sh -c 'cd ${2} && sh'",
+		"all":   "run --rm $@ --",
+		"loop1": "loop2 --foo",
+		"loop2": "loop1",
+		"clean": "!docker ps -q | xargs docker rm",
+	}
+
+	tests := []struct {
+		args        []string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			args:     []string{"ps", "-a"},
+			expected: []string{"ps", "-a"},
+		},
+		{
+			args:     []string{"lsa", "--no-trunc"},
+			expected: []string{"container", "ls", "-a", "--format", `table {{.Names}}\t{{.Status}}`, "--no-trunc"},
+		},
+		{
+			args:     []string{"l"},
+			expected: []string{"container", "ls", "-a", "--format", `table {{.Names}}\t{{.Status}}`},
+		},
+		{
+			args:     []string{"sh", "web", "/srv"},
+			expected: []string{"exec", "-it", "web", "sh", "-c", "cd /srv && sh"},
+		},
+		{
+			args:        []string{"sh", "web"},
+			expectedErr: `alias "sh" requires at least 2 argument(s)`,
+		},
+		{
+			args:     []string{"all", "-e", "FOO=bar", "alpine"},
+			expected: []string{"run", "--rm", "-e", "FOO=bar", "alpine", "--"},
+		},
+		{
+			args:        []string{"loop1"},
+			expectedErr: "alias loop detected: loop1 -> loop2 -> loop1",
+		},
+		{
+			args:     []string{"clean", "-f"},
+			expected: []string{"clean", "-f"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.args[0], func(t *testing.T) {
+			actual, err := expandAlias(aliases, tc.args)
+			if tc.expectedErr != "" {
+				assert.Check(t, is.Error(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(actual, tc.expected))
+		})
+	}
+}
+
+func TestProcessAliases(t *testing.T) {
+	var b bytes.Buffer
+	dockerCli, err := command.NewDockerCli(
+		command.WithBaseContext(t.Context()),
+		command.WithAPIClient(&fakeClient{}),
+		command.WithInputStream(discard),
+		command.WithCombinedStreams(&b),
+	)
+	assert.NilError(t, err)
+	assert.NilError(t, dockerCli.Initialize(flags.NewClientOptions()))
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("docker-compose", `#!/bin/sh
+echo '{"SchemaVersion":"0.1.0","Vendor":"Docker Inc.","Version":"v2.0.0","ShortDescription":"Docker Compose"}'`, fs.WithMode(0o777)),
+	)
+	dockerCli.ConfigFile().CLIPluginsExtraDirs = []string{dir.Path()}
+	dockerCli.ConfigFile().Aliases = map[string]string{
+		"lsa":     "container ls -a",
+		"compose": "container ls",
+		"ps":      "container ls -a",
+		"help":    "version",
+		"-x":      "version",
+		"empty":   " ",
+		"format":  "inspect --format '{{.Name}}'",
+	}
+
+	tcmd := newDockerCommand(dockerCli)
+	tcmd.SetArgs([]string{"--debug", "lsa", "-q"})
+	cmd, args, err := tcmd.HandleGlobalFlags()
+	assert.NilError(t, err)
+
+	args, osArgs, _, err := processAliases(dockerCli, cmd, args, []string{"docker", "--debug", "lsa", "-q"})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(args, []string{"container", "ls", "-a", "-q"}))
+	assert.Check(t, is.DeepEqual(osArgs, []string{"docker", "--debug", "container", "ls", "-a", "-q"}))
+
+	assert.Check(t, is.Contains(b.String(), `WARNING: ignoring alias "ps": it is a built-in command`))
+	assert.Check(t, is.Contains(b.String(), `WARNING: ignoring alias "help": it is a built-in command`))
+	assert.Check(t, is.Contains(b.String(), `WARNING: ignoring alias "compose": it is a plugin command`))
+	assert.Check(t, is.Contains(b.String(), `WARNING: ignoring alias "-x": invalid name`))
+	assert.Check(t, is.Contains(b.String(), `WARNING: ignoring alias "empty": it is empty`))
+
+	// Aliases are added as commands, to show them in help and completion.
+	var aliases []string
+	for _, c := range cmd.Commands() {
+		if _, ok := c.Annotations["user-alias"]; ok {
+			aliases = append(aliases, c.Name())
+		}
+	}
+	assert.Check(t, is.DeepEqual(aliases, []string{"format", "lsa"}))
+}
+
+func TestShellAlias(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell aliases require sh")
+	}
+	var b bytes.Buffer
+	dockerCli, err := command.NewDockerCli(
+		command.WithBaseContext(t.Context()),
+		command.WithAPIClient(&fakeClient{}),
+		command.WithInputStream(discard),
+		command.WithCombinedStreams(&b),
+	)
+	assert.NilError(t, err)
+	assert.NilError(t, dockerCli.Initialize(flags.NewClientOptions()))
+
+	err = runShellAlias(dockerCli, "greet", `echo "hello $1 from $0"`, []string{"world"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(b.String(), "hello world from greet\n"))
+
+	err = runShellAlias(dockerCli, "fail", "exit 3", nil)
+	assert.Check(t, is.DeepEqual(err, cli.StatusError{StatusCode: 3}))
+
+	// Shell aliases can't run shell aliases, which could run themselves.
+	b.Reset()
+	err = runShellAlias(dockerCli, "parent", `echo "$DOCKER_CLI_SHELL_ALIAS"`, nil)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(b.String(), "parent\n"))
+
+	t.Setenv(envShellAlias, "parent")
+	err = runShellAlias(dockerCli, "child", "echo nested", nil)
+	assert.Check(t, is.Error(err, `refusing to run alias "child" from the shell command of alias "parent"`))
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/cli/cli/context/store"
 	"github.com/spf13/cobra"
 )
@@ -16,6 +18,21 @@ func completeContextNames(dockerCLI contextStoreProvider) func(*cobra.Command, [
 	}
 }
 
+type configFileProvider interface {
+	ConfigFile() *configfile.ConfigFile
+}
+
+func completeProfileNames(dockerCLI configFileProvider) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		profiles, _ := config.Profiles(dockerCLI.ConfigFile())
+		names := make([]string, 0, len(profiles))
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
 var logLevels = []string{"debug", "info", "warn", "error", "fatal", "panic"}
 
 func completeLogLevels(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
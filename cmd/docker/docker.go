@@ -175,6 +175,7 @@ func newDockerCommand(dockerCli *command.DockerCli) *cli.TopLevelCommand {
 
 	// TODO(thaJeztah): move configuring completion for these flags to where the flags are added.
 	_ = cmd.RegisterFlagCompletionFunc("context", completeContextNames(dockerCli))
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeProfileNames(dockerCli))
 	_ = cmd.RegisterFlagCompletionFunc("log-level", completeLogLevels)
 
 	cmd.Flags().BoolP("version", "v", false, "Print version information and quit")
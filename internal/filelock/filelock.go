@@ -0,0 +1,26 @@
+// Package filelock provides advisory locks on files, to serialize updates
+// of files that are shared between processes, such as the config file.
+package filelock
+
+import "os"
+
+// Lock acquires an exclusive advisory lock on the given lock file, which is
+// created if it does not exist, waiting for other processes that hold the
+// lock. It returns a function to release the lock.
+//
+// Locks are held by open files, so a process that locks the same file twice
+// waits for itself.
+func Lock(filename string) (unlock func(), _ error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
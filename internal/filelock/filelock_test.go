@@ -0,0 +1,36 @@
+package filelock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json.lock")
+	unlock, err := Lock(filename)
+	assert.NilError(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlock, err := Lock(filename)
+		if err == nil {
+			unlock()
+		}
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("the lock was acquired while it was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the lock was not acquired after it was released")
+	}
+}
@@ -21,11 +21,12 @@
 type OAuthAPI interface {
 	GetDeviceCode(ctx context.Context, audience string) (State, error)
 	WaitForDeviceToken(ctx context.Context, state State) (TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (TokenResponse, error)
 	RevokeToken(ctx context.Context, refreshToken string) error
 	GetAutoPAT(ctx context.Context, audience string, res TokenResponse) (string, error)
 }
 
-// API represents API interactions with Auth0.
+// API represents API interactions with Auth0, or another OAuth tenant.
 type API struct {
 	// TenantURL is the base used for each request to Auth0.
 	TenantURL string
@@ -33,6 +34,14 @@ type API struct {
 	ClientID string
 	// Scopes are the scopes that are requested during the device auth flow.
 	Scopes []string
+
+	// DeviceAuthorizationEndpoint, TokenEndpoint and RevocationEndpoint are
+	// the endpoints of tenants other than Auth0, such as the ones returned
+	// by [Discover]. The Auth0 endpoints of TenantURL are used for endpoints
+	// that are not set.
+	DeviceAuthorizationEndpoint string
+	TokenEndpoint               string
+	RevocationEndpoint          string
 }
 
 // TokenResponse represents the response of the /oauth/token route.
@@ -49,17 +58,32 @@ type TokenResponse struct {
 
 var ErrTimeout = errors.New("timed out waiting for device token")
 
+// ErrRevocationUnsupported is returned by RevokeToken if the tenant has no
+// endpoint to revoke tokens.
+var ErrRevocationUnsupported = errors.New("the tenant does not support revoking tokens")
+
+// endpoint returns the given endpoint, or the Auth0 endpoint with the given
+// path if it is not set.
+func (a API) endpoint(endpoint, path string) string {
+	if endpoint != "" || a.TenantURL == "" {
+		return endpoint
+	}
+	return a.TenantURL + path
+}
+
 // GetDeviceCode initiates the device-code auth flow with the tenant.
 // The state returned contains the device code that the user must use to
 // authenticate, as well as the URL to visit, etc.
 func (a API) GetDeviceCode(ctx context.Context, audience string) (State, error) {
 	data := url.Values{
 		"client_id": {a.ClientID},
-		"audience":  {audience},
 		"scope":     {strings.Join(a.Scopes, " ")},
 	}
+	if audience != "" {
+		data.Set("audience", audience)
+	}
 
-	deviceCodeURL := a.TenantURL + "/oauth/device/code"
+	deviceCodeURL := a.endpoint(a.DeviceAuthorizationEndpoint, "/oauth/device/code")
 	resp, err := postForm(ctx, deviceCodeURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return State{}, err
@@ -125,10 +149,19 @@ func (a API) WaitForDeviceToken(ctx context.Context, state State) (TokenResponse
 			}
 
 			if res.Error != nil {
-				if *res.Error == "authorization_pending" {
+				switch *res.Error {
+				case "authorization_pending":
+					continue
+				case "slow_down":
+					// the tenant asks to increase the polling interval
+					// by 5 seconds; see RFC 8628, section 3.5.
+					state.Interval += 5
 					continue
 				}
 
+				if res.ErrorDescription == "" {
+					return res, errors.New(*res.Error)
+				}
 				return res, errors.New(res.ErrorDescription)
 			}
 
@@ -155,7 +188,7 @@ func resetTimer(t *time.Timer, d time.Duration) {
 	t.Reset(d)
 }
 
-// getDeviceToken calls the token endpoint of Auth0 and returns the response.
+// getDeviceToken calls the token endpoint of the tenant and returns the response.
 func (a API) getDeviceToken(ctx context.Context, state State) (TokenResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
 	defer cancel()
@@ -165,7 +198,7 @@ func (a API) getDeviceToken(ctx context.Context, state State) (TokenResponse, er
 		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
 		"device_code": {state.DeviceCode},
 	}
-	oauthTokenURL := a.TenantURL + "/oauth/token"
+	oauthTokenURL := a.endpoint(a.TokenEndpoint, "/oauth/token")
 
 	resp, err := postForm(ctx, oauthTokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
@@ -187,6 +220,37 @@ func (a API) getDeviceToken(ctx context.Context, state State) (TokenResponse, er
 	return res, nil
 }
 
+// Refresh uses a refresh token to get new tokens from the tenant. Tenants
+// may return a new refresh token, which replaces the one that was used.
+func (a API) Refresh(ctx context.Context, refreshToken string) (TokenResponse, error) {
+	data := url.Values{
+		"client_id":     {a.ClientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+
+	oauthTokenURL := a.endpoint(a.TokenEndpoint, "/oauth/token")
+	resp, err := postForm(ctx, oauthTokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return TokenResponse{}, fmt.Errorf("failed to refresh tokens: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return TokenResponse{}, tryDecodeOAuthError(resp)
+	}
+
+	var res TokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return res, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return res, nil
+}
+
 // RevokeToken revokes a refresh token with the tenant so that it can no longer
 // be used to get new tokens.
 func (a API) RevokeToken(ctx context.Context, refreshToken string) error {
@@ -195,7 +259,10 @@ func (a API) RevokeToken(ctx context.Context, refreshToken string) error {
 		"token":     {refreshToken},
 	}
 
-	revokeURL := a.TenantURL + "/oauth/revoke"
+	revokeURL := a.endpoint(a.RevocationEndpoint, "/oauth/revoke")
+	if revokeURL == "" {
+		return ErrRevocationUnsupported
+	}
 	resp, err := postForm(ctx, revokeURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return err
@@ -218,12 +285,16 @@ func postForm(ctx context.Context, reqURL string, data io.Reader) (*http.Respons
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	cliVersion := strings.ReplaceAll(version.Version, ".", "_")
-	req.Header.Set("User-Agent", fmt.Sprintf("docker-cli:%s:%s-%s", cliVersion, runtime.GOOS, runtime.GOARCH))
+	req.Header.Set("User-Agent", userAgent())
 
 	return http.DefaultClient.Do(req)
 }
 
+func userAgent() string {
+	cliVersion := strings.ReplaceAll(version.Version, ".", "_")
+	return fmt.Sprintf("docker-cli:%s:%s-%s", cliVersion, runtime.GOOS, runtime.GOARCH)
+}
+
 func (API) GetAutoPAT(ctx context.Context, audience string, res TokenResponse) (string, error) {
 	patURL := audience + "/v2/access-tokens/desktop-generate"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, patURL, nil)
@@ -241,6 +241,55 @@ func TestWaitForDeviceToken(t *testing.T) {
 	})
 }
 
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		expectedToken := TokenResponse{
+			AccessToken:  "a-new-token",
+			RefreshToken: "a-new-refresh-token",
+			ExpiresIn:    300,
+		}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/token", r.URL.Path)
+			assert.Equal(t, r.FormValue("client_id"), "aClientID")
+			assert.Equal(t, r.FormValue("grant_type"), "refresh_token")
+			assert.Equal(t, r.FormValue("refresh_token"), "a-refresh-token")
+
+			jsonResponse, err := json.Marshal(expectedToken)
+			assert.NilError(t, err)
+			_, _ = w.Write(jsonResponse)
+		}))
+		defer ts.Close()
+		api := API{
+			ClientID:      "aClientID",
+			TokenEndpoint: ts.URL + "/token",
+		}
+
+		token, err := api.Refresh(context.Background(), "a-refresh-token")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, token, expectedToken)
+	})
+
+	t.Run("error w/ description", func(t *testing.T) {
+		t.Parallel()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"refresh token expired"}`))
+		}))
+		defer ts.Close()
+		api := API{
+			TenantURL: ts.URL,
+			ClientID:  "aClientID",
+		}
+
+		_, err := api.Refresh(context.Background(), "a-refresh-token")
+		assert.ErrorContains(t, err, "refresh token expired")
+	})
+}
+
 func TestRevoke(t *testing.T) {
 	t.Parallel()
 
@@ -308,6 +357,17 @@ func TestRevoke(t *testing.T) {
 		assert.ErrorContains(t, err, "invalid client id")
 	})
 
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+		api := API{
+			ClientID:      "aClientID",
+			TokenEndpoint: "https://idp.example.com/token",
+		}
+
+		err := api.RevokeToken(context.Background(), "v1.a-refresh-token")
+		assert.ErrorIs(t, err, ErrRevocationUnsupported)
+	})
+
 	t.Run("canceled context", func(t *testing.T) {
 		t.Parallel()
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProviderMetadata is the metadata of an OpenID Connect provider, as far as
+// it's used for the device authorization flow.
+type ProviderMetadata struct {
+	Issuer                      string `json:"issuer"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint,omitempty"`
+}
+
+// Discover gets the metadata of the OpenID Connect provider with the given
+// issuer URL from its discovery endpoint. See [OpenID Connect Discovery].
+//
+// The issuer must use https, except for loopback addresses.
+//
+// [OpenID Connect Discovery]: https://openid.net/specs/openid-connect-discovery-1_0.html
+func Discover(ctx context.Context, issuer string) (ProviderMetadata, error) {
+	if err := validateIssuer(issuer); err != nil {
+		return ProviderMetadata{}, err
+	}
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return ProviderMetadata{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ProviderMetadata{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return ProviderMetadata{}, errors.New("unexpected response from provider: " + resp.Status)
+	}
+
+	var md ProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+		return ProviderMetadata{}, fmt.Errorf("failed to decode provider metadata: %w", err)
+	}
+
+	// The issuer in the metadata must match the issuer it was retrieved
+	// from; see section 4.3 of the specification.
+	if strings.TrimSuffix(md.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return ProviderMetadata{}, fmt.Errorf("provider metadata is for a different issuer: %q", md.Issuer)
+	}
+	if md.DeviceAuthorizationEndpoint == "" {
+		return ProviderMetadata{}, errors.New("provider does not support the device authorization grant")
+	}
+	if md.TokenEndpoint == "" {
+		return ProviderMetadata{}, errors.New("provider metadata has no token endpoint")
+	}
+	return md, nil
+}
+
+// validateIssuer checks that the issuer is a https URL, or a http URL of a
+// loopback address, such as a provider that runs locally.
+func validateIssuer(issuer string) error {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return fmt.Errorf("invalid issuer %q: %w", issuer, err)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil
+		}
+		return fmt.Errorf("invalid issuer %q: issuer must use https", issuer)
+	default:
+		return fmt.Errorf("invalid issuer %q: issuer must be a https URL", issuer)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	newProvider := func(t *testing.T, md func(issuer string) ProviderMetadata) *httptest.Server {
+		t.Helper()
+		var ts *httptest.Server
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "/realms/docker/.well-known/openid-configuration", r.URL.Path)
+
+			jsonResponse, err := json.Marshal(md(ts.URL + "/realms/docker"))
+			assert.NilError(t, err)
+			_, _ = w.Write(jsonResponse)
+		}))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		ts := newProvider(t, func(issuer string) ProviderMetadata {
+			return ProviderMetadata{
+				Issuer:                      issuer,
+				DeviceAuthorizationEndpoint: issuer + "/device",
+				TokenEndpoint:               issuer + "/token",
+			}
+		})
+
+		md, err := Discover(context.Background(), ts.URL+"/realms/docker/")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, md, ProviderMetadata{
+			Issuer:                      ts.URL + "/realms/docker",
+			DeviceAuthorizationEndpoint: ts.URL + "/realms/docker/device",
+			TokenEndpoint:               ts.URL + "/realms/docker/token",
+		})
+	})
+
+	t.Run("different issuer", func(t *testing.T) {
+		t.Parallel()
+		ts := newProvider(t, func(string) ProviderMetadata {
+			return ProviderMetadata{
+				Issuer:                      "https://idp.example.com",
+				DeviceAuthorizationEndpoint: "https://idp.example.com/device",
+				TokenEndpoint:               "https://idp.example.com/token",
+			}
+		})
+
+		_, err := Discover(context.Background(), ts.URL+"/realms/docker")
+		assert.ErrorContains(t, err, `provider metadata is for a different issuer: "https://idp.example.com"`)
+	})
+
+	t.Run("no device authorization grant", func(t *testing.T) {
+		t.Parallel()
+		ts := newProvider(t, func(issuer string) ProviderMetadata {
+			return ProviderMetadata{
+				Issuer:        issuer,
+				TokenEndpoint: issuer + "/token",
+			}
+		})
+
+		_, err := Discover(context.Background(), ts.URL+"/realms/docker")
+		assert.ErrorContains(t, err, "provider does not support the device authorization grant")
+	})
+
+	t.Run("insecure issuer", func(t *testing.T) {
+		t.Parallel()
+		_, err := Discover(context.Background(), "http://idp.example.com")
+		assert.ErrorContains(t, err, `invalid issuer "http://idp.example.com": issuer must use https`)
+	})
+}
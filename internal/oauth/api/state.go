@@ -1,6 +1,7 @@
 package api
 
 import (
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,28 @@ type State struct {
 	VerificationURI string `json:"verification_uri_complete"`
 	ExpiresIn       int    `json:"expires_in"`
 	Interval        int    `json:"interval"`
+
+	// VerificationURIBase is the URI to submit the user code at. Unlike
+	// VerificationURI, which includes the user code, it is returned by all
+	// tenants.
+	VerificationURIBase string `json:"verification_uri,omitempty"`
+}
+
+// BrowserURI returns the URI to open in the browser for the user to
+// authenticate.
+func (s State) BrowserURI() string {
+	if s.VerificationURI != "" {
+		return s.VerificationURI
+	}
+	return s.VerificationURIBase
+}
+
+// SubmitURI returns the URI to submit the user code at.
+func (s State) SubmitURI() string {
+	if s.VerificationURIBase != "" {
+		return s.VerificationURIBase
+	}
+	return strings.Split(s.VerificationURI, "?")[0]
 }
 
 // IntervalDuration returns the duration that should be waited between each auth
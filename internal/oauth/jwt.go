@@ -14,6 +14,11 @@ type Claims struct {
 
 	// Scope is the scopes for the claims as a string that is space delimited.
 	Scope string `json:"scope,omitempty"`
+
+	// PreferredUsername and Email are standard OpenID Connect claims that
+	// identify the user of tokens issued by providers other than Auth0.
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
 }
 
 // DomainClaims represents a custom claim data set that doesn't change the spec
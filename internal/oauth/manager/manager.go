@@ -31,6 +31,11 @@ type OAuthManager struct {
 	clientID    string
 	api         api.OAuthAPI
 	openBrowser func(string) error
+
+	// serverAddress is the registry to log in to with an OpenID Connect
+	// provider (see NewOIDCManager). It is empty for Docker Hub, for which
+	// a PAT is created after logging in.
+	serverAddress string
 }
 
 // OAuthManagerOptions are the options used for New to create a new auth manager.
@@ -44,8 +49,11 @@ type OAuthManagerOptions struct {
 	OpenBrowser func(string) error
 }
 
+// defaultScopes are the scopes that are requested if no scopes are set.
+var defaultScopes = []string{"openid", "offline_access"}
+
 func New(options OAuthManagerOptions) *OAuthManager {
-	scopes := []string{"openid", "offline_access"}
+	scopes := defaultScopes
 	if len(options.Scopes) > 0 {
 		scopes = options.Scopes
 	}
@@ -103,9 +111,11 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 	default:
 		out = tui.NewOutput(streams.NewOut(w))
 	}
-	out.PrintNote("To sign in with credentials on the command line, use 'docker login -u <username>'\n")
+	if m.serverAddress == "" {
+		out.PrintNote("To sign in with credentials on the command line, use 'docker login -u <username>'\n")
+	}
 	_, _ = fmt.Fprintf(w, "\nYour one-time device confirmation code is: "+aec.Bold.Apply("%s\n"), state.UserCode)
-	_, _ = fmt.Fprintf(w, aec.Bold.Apply("Press ENTER")+" to open your browser or submit your device code here: "+aec.Underline.Apply("%s\n"), strings.Split(state.VerificationURI, "?")[0])
+	_, _ = fmt.Fprintf(w, aec.Bold.Apply("Press ENTER")+" to open your browser or submit your device code here: "+aec.Underline.Apply("%s\n"), state.SubmitURI())
 
 	tokenResChan := make(chan api.TokenResponse)
 	waitForTokenErrChan := make(chan error)
@@ -121,7 +131,7 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 	go func() {
 		reader := bufio.NewReader(os.Stdin)
 		_, _ = reader.ReadString('\n')
-		_ = m.openBrowser(state.VerificationURI)
+		_ = m.openBrowser(state.BrowserURI())
 	}()
 
 	_, _ = fmt.Fprint(w, "\nWaiting for authentication in the browser…\n")
@@ -134,6 +144,10 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 	case tokenRes = <-tokenResChan:
 	}
 
+	if m.serverAddress != "" {
+		return m.storeOIDCTokens(tokenRes, "")
+	}
+
 	claims, err := oauth.GetClaims(tokenRes.AccessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token claims: %w", err)
@@ -162,15 +176,17 @@ func (m *OAuthManager) LoginDevice(ctx context.Context, w io.Writer) (*types.Aut
 // If the refresh token is not found in the store, an error is not
 // returned.
 func (m *OAuthManager) Logout(ctx context.Context) error {
-	refreshConfig, err := m.store.Get(refreshTokenKey)
+	refreshConfig, err := m.store.Get(m.refreshTokenKey())
 	if err != nil {
 		return err
 	}
 	if refreshConfig.Password == "" {
 		return nil
 	}
+	// Refresh tokens that are stored by "docker login --oidc" are followed
+	// by the expiry of the access token.
 	parts := strings.Split(refreshConfig.Password, "..")
-	if len(parts) != 2 {
+	if len(parts) != 2 && len(parts) != 3 {
 		// the token wasn't stored by the CLI, so don't revoke it
 		// or erase it from the store/error
 		return nil
@@ -180,7 +196,7 @@ func (m *OAuthManager) Logout(ctx context.Context) error {
 	if err := m.eraseTokensFromStore(); err != nil {
 		return fmt.Errorf("failed to erase tokens: %w", err)
 	}
-	if err := m.api.RevokeToken(ctx, parts[0]); err != nil {
+	if err := m.api.RevokeToken(ctx, parts[0]); err != nil && !errors.Is(err, api.ErrRevocationUnsupported) {
 		return fmt.Errorf("credentials erased successfully, but there was a failure to revoke the OAuth refresh token with the tenant: %w", err)
 	}
 	return nil
@@ -189,8 +205,29 @@ func (m *OAuthManager) Logout(ctx context.Context) error {
 const (
 	accessTokenKey  = registry.IndexServer + "access-token"
 	refreshTokenKey = registry.IndexServer + "refresh-token"
+
+	refreshTokenSuffix = "/refresh-token"
 )
 
+// RefreshTokenKey returns the key that the refresh token for the registry
+// with the given server address is stored under in the credentials store.
+func RefreshTokenKey(serverAddress string) string {
+	return strings.TrimSuffix(serverAddress, "/") + refreshTokenSuffix
+}
+
+// IsTokenKey returns whether the given key of the credentials store holds a
+// token that is stored by the manager, instead of credentials for a registry.
+func IsTokenKey(key string) bool {
+	return key == accessTokenKey || strings.HasSuffix(key, refreshTokenSuffix)
+}
+
+func (m *OAuthManager) refreshTokenKey() string {
+	if m.serverAddress == "" {
+		return refreshTokenKey
+	}
+	return RefreshTokenKey(m.serverAddress)
+}
+
 func (m *OAuthManager) storeTokensInStore(tokens api.TokenResponse, username string) error {
 	return errors.Join(
 		m.store.Store(types.AuthConfig{
@@ -207,6 +244,11 @@ func (m *OAuthManager) storeTokensInStore(tokens api.TokenResponse, username str
 }
 
 func (m *OAuthManager) eraseTokensFromStore() error {
+	if m.serverAddress != "" {
+		// the access token is stored as the credentials of the registry,
+		// which are erased by the caller.
+		return m.store.Erase(m.refreshTokenKey())
+	}
 	return errors.Join(
 		m.store.Erase(accessTokenKey),
 		m.store.Erase(refreshTokenKey),
@@ -0,0 +1,321 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/internal/filelock"
+	"github.com/docker/cli/internal/oauth"
+	"github.com/docker/cli/internal/oauth/api"
+	"github.com/sirupsen/logrus"
+)
+
+// refreshMargin is how long before they expire access tokens are refreshed.
+const refreshMargin = time.Minute
+
+// providers holds the metadata of the OpenID Connect providers that were
+// discovered, by issuer, so that they are discovered once per invocation.
+var providers sync.Map
+
+// discover returns the metadata of the OpenID Connect provider with the given
+// issuer URL, which is only discovered the first time.
+func discover(ctx context.Context, issuer string) (api.ProviderMetadata, error) {
+	if md, ok := providers.Load(issuer); ok {
+		return md.(api.ProviderMetadata), nil
+	}
+	md, err := api.Discover(ctx, issuer)
+	if err != nil {
+		return api.ProviderMetadata{}, err
+	}
+	providers.Store(issuer, md)
+	return md, nil
+}
+
+// NewOIDCManager returns a manager to log in to the registry with the given
+// server address with the OpenID Connect provider in cfg. The endpoints of
+// the provider are discovered from its issuer URL.
+//
+// The access token of the provider is stored as the password for the
+// registry, and the refresh token under a separate key.
+func NewOIDCManager(ctx context.Context, store credentials.Store, serverAddress string, cfg configfile.RegistryOIDCConfig) (*OAuthManager, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("invalid OIDC provider for %s: issuer and clientID are required", serverAddress)
+	}
+	md, err := discover(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider %s: %w", cfg.Issuer, err)
+	}
+	scopes := defaultScopes
+	if len(cfg.Scopes) > 0 {
+		scopes = cfg.Scopes
+	}
+	m := New(OAuthManagerOptions{
+		Store:    store,
+		Audience: cfg.Audience,
+		ClientID: cfg.ClientID,
+	})
+	m.tenant = md.Issuer
+	m.serverAddress = serverAddress
+	m.api = api.API{
+		ClientID:                    cfg.ClientID,
+		Scopes:                      scopes,
+		DeviceAuthorizationEndpoint: md.DeviceAuthorizationEndpoint,
+		TokenEndpoint:               md.TokenEndpoint,
+		RevocationEndpoint:          md.RevocationEndpoint,
+	}
+	return m, nil
+}
+
+// Refresh uses the refresh token in the store to get new tokens from the
+// provider, and stores them. It returns the refreshed credentials for the
+// registry.
+func (m *OAuthManager) Refresh(ctx context.Context) (*types.AuthConfig, error) {
+	refreshConfig, err := m.store.Get(m.refreshTokenKey())
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, _, ok := strings.Cut(refreshConfig.Password, "..")
+	if !ok || refreshToken == "" {
+		return nil, errors.New("no refresh token found")
+	}
+	tokenRes, err := m.api.Refresh(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if tokenRes.RefreshToken == "" {
+		// providers that don't rotate refresh tokens don't return them
+		tokenRes.RefreshToken = refreshToken
+	}
+	return m.storeOIDCTokens(tokenRes, refreshConfig.Username)
+}
+
+// storeOIDCTokens stores the access token as the credentials for the
+// registry, and the refresh token under a separate key, concatenated with
+// the client ID and, if the provider returned it, the time the access token
+// expires, as a Unix timestamp. The username is taken from the tokens, or
+// defaultUsername if the tokens don't identify the user.
+func (m *OAuthManager) storeOIDCTokens(tokens api.TokenResponse, defaultUsername string) (*types.AuthConfig, error) {
+	if tokens.AccessToken == "" {
+		return nil, errors.New("no access token received")
+	}
+	username := tokenUsername(tokens.IDToken, tokens.AccessToken)
+	if username == "" {
+		username = defaultUsername
+	}
+	if username == "" {
+		return nil, errors.New("failed to get the username from the tokens")
+	}
+
+	authConfig := types.AuthConfig{
+		Username:      username,
+		Password:      tokens.AccessToken,
+		ServerAddress: m.serverAddress,
+	}
+	var refreshErr error
+	if tokens.RefreshToken != "" {
+		refreshToken := tokens.RefreshToken + ".." + m.clientID
+		if tokens.ExpiresIn > 0 {
+			expiry := time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second)
+			refreshToken += ".." + strconv.FormatInt(expiry.Unix(), 10)
+		}
+		refreshErr = m.store.Store(types.AuthConfig{
+			Username:      username,
+			Password:      refreshToken,
+			ServerAddress: m.refreshTokenKey(),
+		})
+	}
+	if err := errors.Join(m.store.Store(authConfig), refreshErr); err != nil {
+		return nil, fmt.Errorf("failed to store tokens: %w", err)
+	}
+	return &authConfig, nil
+}
+
+// tokenUsername returns the name of the user of the first token that is a
+// JWT that identifies the user, or an empty string if none does.
+func tokenUsername(tokens ...string) string {
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		claims, err := oauth.GetClaims(token)
+		if err != nil {
+			continue
+		}
+		for _, name := range []string{claims.PreferredUsername, claims.Email, claims.Subject} {
+			if name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// expiresSoon returns whether the access token expires within refreshMargin.
+// The expiry is taken from the access token if it is a JWT, or otherwise from
+// the stored refresh token (see [OAuthManager.storeOIDCTokens]). Access tokens
+// of which the expiry is not known are assumed to expire.
+func expiresSoon(accessToken, storedRefreshToken string) bool {
+	if claims, err := oauth.GetClaims(accessToken); err == nil && claims.Expiry != nil {
+		return time.Until(claims.Expiry.Time()) < refreshMargin
+	}
+	parts := strings.Split(storedRefreshToken, "..")
+	if len(parts) != 3 {
+		return true
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return true
+	}
+	return time.Until(time.Unix(expiry, 0)) < refreshMargin
+}
+
+// NewRefreshingStore returns a credentials store that refreshes the access
+// tokens of registries that were logged in to with "docker login --oidc"
+// before they expire. It uses the providers in the "registryOIDC" property
+// of the config file.
+func NewRefreshingStore(ctx context.Context, configFile *configfile.ConfigFile, store credentials.Store) credentials.Store {
+	return &refreshingStore{store: store, ctx: ctx, configFile: configFile}
+}
+
+type refreshingStore struct {
+	store      credentials.Store
+	ctx        context.Context
+	configFile *configfile.ConfigFile
+}
+
+// Erase removes the credentials for the given server address.
+func (s *refreshingStore) Erase(serverAddress string) error {
+	return s.store.Erase(serverAddress)
+}
+
+// Store saves the given credentials.
+func (s *refreshingStore) Store(authConfig types.AuthConfig) error {
+	return s.store.Store(authConfig)
+}
+
+// Get returns the credentials for the given server address, after refreshing
+// them if needed.
+func (s *refreshingStore) Get(serverAddress string) (types.AuthConfig, error) {
+	authConfig, err := s.store.Get(serverAddress)
+	if err != nil {
+		return authConfig, err
+	}
+	return s.refresh(serverAddress, authConfig), nil
+}
+
+// GetAll returns all the credentials in the store. They are not refreshed,
+// as that would contact the providers of all registries, of which most are
+// not used.
+func (s *refreshingStore) GetAll() (map[string]types.AuthConfig, error) {
+	return s.store.GetAll()
+}
+
+// refresh returns refreshed credentials for the registry if it has an OIDC
+// provider and its access token expires soon. The given credentials are
+// returned if they can't be refreshed; the registry rejects them if they
+// have expired, in which case the user has to log in again.
+func (s *refreshingStore) refresh(serverAddress string, authConfig types.AuthConfig) types.AuthConfig {
+	if IsTokenKey(serverAddress) {
+		return authConfig
+	}
+	hostname := credentials.ConvertToHostname(serverAddress)
+	cfg, ok := s.configFile.RegistryOIDC[hostname]
+	if !ok || authConfig.Password == "" {
+		return authConfig
+	}
+	rt, err := s.store.Get(RefreshTokenKey(hostname))
+	if err != nil || rt.Password == "" {
+		// the credentials were not stored by "docker login --oidc"
+		return authConfig
+	}
+	if !expiresSoon(authConfig.Password, rt.Password) {
+		return authConfig
+	}
+
+	refreshed, err := s.refreshLocked(serverAddress, hostname, cfg)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to refresh credentials for registry: %s", hostname)
+		return authConfig
+	}
+	refreshed.ServerAddress = authConfig.ServerAddress
+	return *refreshed
+}
+
+// refreshLocked refreshes the credentials for the registry while holding a
+// lock, so that other invocations of the CLI don't refresh them at the same
+// time. Providers that rotate refresh tokens reject a refresh token that was
+// used before, so the tokens are read again after acquiring the lock, and are
+// only refreshed if another invocation didn't refresh them already.
+func (s *refreshingStore) refreshLocked(serverAddress, hostname string, cfg configfile.RegistryOIDCConfig) (*types.AuthConfig, error) {
+	store := s.store
+	if s.configFile.Filename != "" {
+		unlock, err := filelock.Lock(s.configFile.Filename + ".refresh.lock")
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		store, err = s.reloadStore(serverAddress, hostname)
+		if err != nil {
+			return nil, err
+		}
+		current, err := store.Get(serverAddress)
+		if err != nil {
+			return nil, err
+		}
+		rt, err := store.Get(RefreshTokenKey(hostname))
+		if err != nil {
+			return nil, err
+		}
+		if current.Password != "" && !expiresSoon(current.Password, rt.Password) {
+			logrus.Debugf("credentials for registry were refreshed by another process: %s", hostname)
+			return &current, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+	m, err := NewOIDCManager(ctx, store, hostname, cfg)
+	if err != nil {
+		return nil, err
+	}
+	refreshed, err := m.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("refreshed credentials for registry: %s", hostname)
+	return refreshed, nil
+}
+
+// reloadStore returns the credentials store for the registry from the config
+// file as it is on disk, and drops the cached credentials of the registry,
+// to see the changes of other invocations of the CLI.
+func (s *refreshingStore) reloadStore(serverAddress, hostname string) (credentials.Store, error) {
+	f, err := os.Open(s.configFile.Filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s.store, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	configFile := configfile.New(s.configFile.Filename)
+	if err := configFile.LoadFromReader(f); err != nil {
+		return nil, err
+	}
+	if err := configFile.SetLayers(s.configFile.Layers()...); err != nil {
+		return nil, err
+	}
+	store := configFile.GetCredentialsStore(hostname)
+	credentials.InvalidateCache(store, serverAddress, RefreshTokenKey(hostname))
+	return store, nil
+}
@@ -0,0 +1,293 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/cli/internal/oauth/api"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// testProvider is a stand-in OpenID Connect provider, which supports the
+// device authorization and refresh token grants.
+type testProvider struct {
+	*httptest.Server
+	t *testing.T
+
+	mu            sync.Mutex
+	refreshToken  string
+	deviceForm    map[string]string
+	opaque        bool // issue access tokens that are not a JWT
+	discovered    int
+	refreshed     int
+	revokedTokens []string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	p := &testProvider{t: t, refreshToken: "refresh-1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		p.mu.Lock()
+		p.discovered++
+		p.mu.Unlock()
+		p.writeJSON(w, api.ProviderMetadata{
+			Issuer:                      p.URL,
+			DeviceAuthorizationEndpoint: p.URL + "/device",
+			TokenEndpoint:               p.URL + "/token",
+			RevocationEndpoint:          p.URL + "/revoke",
+		})
+	})
+	mux.HandleFunc("POST /device", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.deviceForm = map[string]string{
+			"client_id": r.FormValue("client_id"),
+			"scope":     r.FormValue("scope"),
+			"audience":  r.FormValue("audience"),
+		}
+		p.mu.Unlock()
+		p.writeJSON(w, api.State{
+			DeviceCode:          "device-code",
+			UserCode:            "ABCD-EFGH",
+			VerificationURIBase: p.URL + "/activate",
+			ExpiresIn:           30,
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		assert.Check(t, is.Equal(r.FormValue("client_id"), "docker-cli"))
+		switch r.FormValue("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			assert.Check(t, is.Equal(r.FormValue("device_code"), "device-code"))
+		case "refresh_token":
+			if r.FormValue("refresh_token") != p.refreshToken {
+				w.WriteHeader(http.StatusBadRequest)
+				p.writeJSON(w, map[string]string{"error": "invalid_grant", "error_description": "invalid refresh token"})
+				return
+			}
+			p.refreshed++
+			p.refreshToken = "refresh-2"
+		default:
+			t.Errorf("unexpected grant type: %s", r.FormValue("grant_type"))
+		}
+		accessToken := p.token(t, jwt.Claims{Subject: "1234", Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+		if p.opaque {
+			accessToken = "opaque-" + p.refreshToken
+		}
+		p.writeJSON(w, api.TokenResponse{
+			AccessToken:  accessToken,
+			IDToken:      p.token(t, map[string]any{"sub": "1234", "preferred_username": "bork"}),
+			RefreshToken: p.refreshToken,
+			ExpiresIn:    3600,
+		})
+	})
+	mux.HandleFunc("POST /revoke", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.revokedTokens = append(p.revokedTokens, r.FormValue("token"))
+		p.mu.Unlock()
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProvider) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	assert.Check(p.t, json.NewEncoder(w).Encode(v))
+}
+
+func (p *testProvider) config() configfile.RegistryOIDCConfig {
+	return configfile.RegistryOIDCConfig{Issuer: p.URL, ClientID: "docker-cli"}
+}
+
+// token returns a signed JWT with the given claims. It is also called by the
+// handlers of the provider, so it does not stop the test on failures.
+func (*testProvider) token(t *testing.T, claims any) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("a-secret-of-at-least-32-bytes!!!")}, nil)
+	assert.Check(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	assert.Check(t, err)
+	return token
+}
+
+func TestOIDCLoginDevice(t *testing.T) {
+	p := newTestProvider(t)
+	store := newStore(map[string]types.AuthConfig{})
+	m, err := NewOIDCManager(context.Background(), credentials.NewFileStore(store), "registry.example.com", p.config())
+	assert.NilError(t, err)
+	m.openBrowser = func(string) error {
+		return nil
+	}
+
+	authConfig, err := m.LoginDevice(context.Background(), io.Discard)
+	assert.NilError(t, err)
+
+	assert.Check(t, is.DeepEqual(p.deviceForm, map[string]string{
+		"client_id": "docker-cli",
+		"scope":     "openid offline_access",
+		"audience":  "",
+	}))
+	assert.Check(t, is.Equal(authConfig.Username, "bork"))
+	assert.Check(t, is.Equal(authConfig.ServerAddress, "registry.example.com"))
+	assert.Check(t, is.Len(store.configs, 2))
+	assert.Check(t, is.DeepEqual(store.configs["registry.example.com"], *authConfig))
+
+	// the refresh token is stored with the client ID, and the expiry of the
+	// access token.
+	rt := store.configs["registry.example.com/refresh-token"]
+	assert.Check(t, is.Equal(rt.Username, "bork"))
+	refreshToken, expiry, ok := strings.Cut(rt.Password, "..docker-cli..")
+	assert.Check(t, ok, "unexpected refresh token: %s", rt.Password)
+	assert.Check(t, is.Equal(refreshToken, "refresh-1"))
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	assert.NilError(t, err)
+	assert.Check(t, time.Until(time.Unix(expiresAt, 0)) > 59*time.Minute)
+}
+
+func TestRefreshingStore(t *testing.T) {
+	p := newTestProvider(t)
+	configFile := configfile.New(filepath.Join(t.TempDir(), "config.json"))
+	configFile.RegistryOIDC = map[string]configfile.RegistryOIDCConfig{
+		"registry.example.com": p.config(),
+		"other.example.com":    p.config(),
+	}
+	expired := p.token(t, jwt.Claims{Subject: "1234", Expiry: jwt.NewNumericDate(time.Now().Add(-time.Minute))})
+	valid := p.token(t, jwt.Claims{Subject: "1234", Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+	configFile.AuthConfigs = map[string]types.AuthConfig{
+		"registry.example.com":               {Username: "bork", Password: expired, ServerAddress: "registry.example.com"},
+		"registry.example.com/refresh-token": {Username: "bork", Password: "refresh-1..docker-cli"},
+		"other.example.com":                  {Username: "bork", Password: "a-password", ServerAddress: "other.example.com"},
+		"valid.example.com":                  {Username: "bork", Password: valid, ServerAddress: "valid.example.com"},
+	}
+	assert.NilError(t, configFile.Save())
+
+	// load returns the config file for a new invocation of the CLI.
+	load := func() *configfile.ConfigFile {
+		t.Helper()
+		f, err := os.Open(configFile.Filename)
+		assert.NilError(t, err)
+		defer f.Close()
+		cfg := configfile.New(configFile.Filename)
+		assert.NilError(t, cfg.LoadFromReader(f))
+		return cfg
+	}
+	otherConfigFile := load()
+	store := NewRefreshingStore(context.Background(), configFile, credentials.NewFileStore(configFile))
+
+	// GetAll does not refresh credentials.
+	all, err := store.GetAll()
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(p.refreshed, 0))
+	assert.Check(t, is.Equal(all["registry.example.com"].Password, expired))
+
+	authConfig, err := store.Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(p.refreshed, 1))
+	assert.Check(t, is.Equal(authConfig.Username, "bork"))
+	assert.Check(t, authConfig.Password != expired)
+	assert.Check(t, !expiresSoon(authConfig.Password, ""))
+
+	// the refreshed tokens are stored, and are not refreshed again.
+	saved := load()
+	assert.Check(t, is.DeepEqual(saved.AuthConfigs["registry.example.com"], authConfig))
+	assert.Check(t, is.Contains(saved.AuthConfigs["registry.example.com/refresh-token"].Password, "refresh-2..docker-cli.."))
+	again, err := store.Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(p.refreshed, 1))
+	assert.Check(t, is.DeepEqual(again, authConfig))
+
+	// another invocation that read the tokens before they were refreshed
+	// uses the refreshed tokens, instead of the refresh token that was
+	// rotated.
+	otherStore := NewRefreshingStore(context.Background(), otherConfigFile, credentials.NewFileStore(otherConfigFile))
+	other, err := otherStore.Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(p.refreshed, 1))
+	assert.Check(t, is.DeepEqual(other, authConfig))
+
+	// credentials that were not stored by "docker login --oidc" are not
+	// refreshed.
+	authConfig, err = store.Get("other.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(authConfig.Password, "a-password"))
+	assert.Check(t, is.Equal(p.refreshed, 1))
+
+	// the provider is only discovered once.
+	assert.Check(t, is.Equal(p.discovered, 1))
+}
+
+func TestRefreshingStoreOpaqueTokens(t *testing.T) {
+	p := newTestProvider(t)
+	p.opaque = true
+	configFile := configfile.New(filepath.Join(t.TempDir(), "config.json"))
+	configFile.RegistryOIDC = map[string]configfile.RegistryOIDCConfig{
+		"registry.example.com": p.config(),
+	}
+	expiry := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+	}
+	configFile.AuthConfigs = map[string]types.AuthConfig{
+		"registry.example.com":               {Username: "bork", Password: "opaque-refresh-0", ServerAddress: "registry.example.com"},
+		"registry.example.com/refresh-token": {Username: "bork", Password: "refresh-1..docker-cli.." + expiry(time.Hour)},
+	}
+	store := NewRefreshingStore(context.Background(), configFile, credentials.NewFileStore(configFile))
+
+	// access tokens that are not a JWT are not refreshed before the expiry
+	// that is stored with the refresh token.
+	for i := 0; i < 3; i++ {
+		authConfig, err := store.Get("registry.example.com")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(authConfig.Password, "opaque-refresh-0"))
+	}
+	assert.Check(t, is.Equal(p.refreshed, 0))
+
+	configFile.AuthConfigs["registry.example.com/refresh-token"] = types.AuthConfig{Username: "bork", Password: "refresh-1..docker-cli.." + expiry(-time.Minute)}
+	for i := 0; i < 3; i++ {
+		authConfig, err := store.Get("registry.example.com")
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(authConfig.Password, "opaque-refresh-2"))
+	}
+	assert.Check(t, is.Equal(p.refreshed, 1))
+
+	// access tokens of which the expiry is not known are refreshed.
+	configFile.AuthConfigs["registry.example.com/refresh-token"] = types.AuthConfig{Username: "bork", Password: "refresh-2..docker-cli"}
+	assert.NilError(t, configFile.Save())
+	_, err := store.Get("registry.example.com")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(p.refreshed, 2))
+}
+
+func TestOIDCLogout(t *testing.T) {
+	p := newTestProvider(t)
+	store := newStore(map[string]types.AuthConfig{
+		"registry.example.com":               {Username: "bork", Password: "an-access-token"},
+		"registry.example.com/refresh-token": {Username: "bork", Password: "refresh-1..docker-cli..1700000000"},
+	})
+	m, err := NewOIDCManager(context.Background(), credentials.NewFileStore(store), "registry.example.com", p.config())
+	assert.NilError(t, err)
+
+	assert.NilError(t, m.Logout(context.Background()))
+	assert.Check(t, is.DeepEqual(p.revokedTokens, []string{"refresh-1"}))
+	// the credentials of the registry are erased by "docker logout".
+	assert.Check(t, is.DeepEqual(store.configs, map[string]types.AuthConfig{
+		"registry.example.com": {Username: "bork", Password: "an-access-token"},
+	}))
+}
@@ -26,6 +26,22 @@
 // TODO(thaJeztah): add CertsDir as option to replace the [CertsDir] function, which sets the location magically.
 type ServiceOptions struct {
 	InsecureRegistries []string `json:"insecure-registries,omitempty"`
+
+	// CertsDirs are directories with certificates per registry, with the
+	// same layout as [CertsDir]. They are used in addition to [CertsDir].
+	CertsDirs []string `json:"-"`
+
+	// TLS holds TLS options per registry (host[:port]), which are applied
+	// after the certificates in the certificates directories.
+	TLS map[string]TLSOptions `json:"-"`
+}
+
+// TLSOptions are the TLS options for connecting to a registry.
+type TLSOptions struct {
+	CAFile             string // CA bundle to trust, in addition to the system's CAs
+	CertFile           string // client certificate
+	KeyFile            string // key of the client certificate
+	InsecureSkipVerify bool   // don't verify the certificate of the registry
 }
 
 // serviceConfig holds daemon configuration for the registry service.
@@ -34,6 +50,8 @@ type ServiceOptions struct {
 type serviceConfig struct {
 	insecureRegistryCIDRs []*net.IPNet
 	indexConfigs          map[string]*registry.IndexInfo
+	certsDirs             []string
+	tls                   map[string]TLSOptions
 }
 
 // TODO(thaJeztah) both the "index.docker.io" and "registry-1.docker.io" domains
@@ -20,34 +20,79 @@
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-// hostCertsDir returns the config directory for a specific host.
-func hostCertsDir(hostnameAndPort string) string {
+// hostCertsDir returns the directory with the certificates for a specific
+// host in the given certificates directory.
+func hostCertsDir(certsDir, hostnameAndPort string) string {
 	if runtime.GOOS == "windows" {
 		// Ensure that a directory name is valid; hostnameAndPort may contain
 		// a colon (:) if a port is included, and Windows does not allow colons
 		// in directory names.
 		hostnameAndPort = filepath.FromSlash(strings.ReplaceAll(hostnameAndPort, ":", ""))
 	}
-	return filepath.Join(CertsDir(), hostnameAndPort)
+	return filepath.Join(certsDir, hostnameAndPort)
 }
 
-// newTLSConfig constructs a client TLS configuration based on server defaults
-func newTLSConfig(ctx context.Context, hostname string, isSecure bool) (*tls.Config, error) {
+// newTLSConfig constructs a client TLS configuration based on server defaults,
+// with the certificates for the host in [CertsDir] and the configured
+// certificates directories, and the configured TLS options for the host.
+func (config *serviceConfig) newTLSConfig(ctx context.Context, hostname string, isSecure bool) (*tls.Config, error) {
 	// PreferredServerCipherSuites should have no effect
 	tlsConfig := tlsconfig.ServerDefault()
 	tlsConfig.InsecureSkipVerify = !isSecure
 
 	if isSecure {
-		hostDir := hostCertsDir(hostname)
-		log.G(ctx).Debugf("hostDir: %s", hostDir)
-		if err := loadTLSConfig(ctx, hostDir, tlsConfig); err != nil {
-			return nil, err
+		for _, certsDir := range append([]string{CertsDir()}, config.certsDirs...) {
+			hostDir := hostCertsDir(certsDir, hostname)
+			log.G(ctx).Debugf("hostDir: %s", hostDir)
+			if err := loadTLSConfig(ctx, hostDir, tlsConfig); err != nil {
+				return nil, err
+			}
+		}
+		if opts, ok := config.tls[hostname]; ok {
+			if err := applyTLSOptions(opts, tlsConfig); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return tlsConfig, nil
 }
 
+// applyTLSOptions updates the provided TLS configuration with the CA bundle,
+// client certificate, and skip-verify option of opts.
+func applyTLSOptions(opts TLSOptions, tlsConfig *tls.Config) error {
+	if opts.CAFile != "" {
+		data, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return invalidParam(err)
+		}
+		if tlsConfig.RootCAs == nil {
+			systemPool, err := x509.SystemCertPool()
+			if err != nil {
+				return invalidParam(fmt.Errorf("unable to get system cert pool: %w", err))
+			}
+			tlsConfig.RootCAs = systemPool
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return invalidParamf("no certificates found in CA bundle %s", opts.CAFile)
+		}
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return invalidParamf("both a client certificate and key must be set")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return invalidParam(err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	if opts.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return nil
+}
+
 func hasFile(files []os.DirEntry, name string) bool {
 	for _, f := range files {
 		if f.Name() == name {
@@ -1,7 +1,18 @@
 package registry
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/distribution/reference"
 	"github.com/moby/moby/api/types/registry"
@@ -279,3 +290,76 @@ type staticRepositoryInfo struct {
 		})
 	}
 }
+
+// writeTestCert writes a self-signed certificate and its key to the given
+// files, and returns the certificate.
+func writeTestCert(t *testing.T, certFile, keyFile string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: filepath.Base(certFile)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NilError(t, err)
+	assert.NilError(t, os.MkdirAll(filepath.Dir(certFile), 0o755))
+	assert.NilError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	if keyFile != "" {
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		assert.NilError(t, err)
+		assert.NilError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	}
+	return cert
+}
+
+func TestEndpointsTLS(t *testing.T) {
+	dir := t.TempDir()
+	certsDir := filepath.Join(dir, "certs.d")
+	writeTestCert(t, filepath.Join(certsDir, "registry.example.com", "client.cert"), filepath.Join(certsDir, "registry.example.com", "client.key"))
+	ca := writeTestCert(t, filepath.Join(dir, "ca.pem"), "")
+	writeTestCert(t, filepath.Join(dir, "other.cert"), filepath.Join(dir, "other.key"))
+
+	svc, err := NewService(ServiceOptions{
+		CertsDirs: []string{certsDir},
+		TLS: map[string]TLSOptions{
+			"registry.example.com": {
+				CAFile:   filepath.Join(dir, "ca.pem"),
+				CertFile: filepath.Join(dir, "other.cert"),
+				KeyFile:  filepath.Join(dir, "other.key"),
+			},
+			"skip.example.com": {InsecureSkipVerify: true},
+		},
+	})
+	assert.NilError(t, err)
+
+	ctx := context.Background()
+	endpoints, err := svc.Endpoints(ctx, "registry.example.com")
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(endpoints, 1))
+	tlsConfig := endpoints[0].TLSConfig
+	assert.Check(t, is.Len(tlsConfig.Certificates, 2), "expected the client certificates of the certs directory and the TLS options")
+	assert.Check(t, !tlsConfig.InsecureSkipVerify)
+	_, err = ca.Verify(x509.VerifyOptions{Roots: tlsConfig.RootCAs})
+	assert.Check(t, err, "expected the CA bundle to be trusted")
+
+	// Skipping verification does not allow plain HTTP, unlike insecure registries.
+	endpoints, err = svc.Endpoints(ctx, "skip.example.com")
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(endpoints, 1))
+	assert.Check(t, endpoints[0].TLSConfig.InsecureSkipVerify)
+
+	svc, err = NewService(ServiceOptions{
+		TLS: map[string]TLSOptions{"registry.example.com": {CertFile: filepath.Join(dir, "other.cert")}},
+	})
+	assert.NilError(t, err)
+	_, err = svc.Endpoints(ctx, "registry.example.com")
+	assert.Check(t, is.ErrorContains(err, "both a client certificate and key must be set"))
+}
@@ -26,6 +26,8 @@ func NewService(options ServiceOptions) (*Service, error) {
 	if err != nil {
 		return nil, err
 	}
+	config.certsDirs = options.CertsDirs
+	config.tls = options.TLS
 	return &Service{config: config}, nil
 }
 
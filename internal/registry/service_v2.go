@@ -15,7 +15,8 @@ func (s *Service) Endpoints(ctx context.Context, hostname string) ([]APIEndpoint
 		}}, nil
 	}
 
-	tlsConfig, err := newTLSConfig(ctx, hostname, s.config.isSecureIndex(hostname))
+	isSecure := s.config.isSecureIndex(hostname)
+	tlsConfig, err := s.config.newTLSConfig(ctx, hostname, isSecure)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +26,7 @@ func (s *Service) Endpoints(ctx context.Context, hostname string) ([]APIEndpoint
 		TLSConfig: tlsConfig,
 	}}
 
-	if tlsConfig.InsecureSkipVerify {
+	if !isSecure {
 		endpoints = append(endpoints, APIEndpoint{
 			URL: &url.URL{Scheme: "http", Host: hostname},
 			// used to check if supposed to be secure via InsecureSkipVerify
@@ -2,16 +2,21 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
 	"github.com/distribution/reference"
 	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/internal/registry"
 	"github.com/docker/distribution"
 	distributionclient "github.com/docker/distribution/registry/client"
+	"github.com/docker/distribution/registry/client/auth"
 	registrytypes "github.com/moby/moby/api/types/registry"
 	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,15 +27,30 @@ type RegistryClient interface {
 	GetManifestList(ctx context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error)
 	MountBlob(ctx context.Context, source reference.Canonical, target reference.Named) error
 	PutManifest(ctx context.Context, ref reference.Named, manifest distribution.Manifest) (digest.Digest, error)
+	GetRawManifest(ctx context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error)
+	GetUpstreamManifest(ctx context.Context, ref reference.Named, actions ...string) (ocispec.Descriptor, []byte, error)
+	GetBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]byte, error)
+	GetTags(ctx context.Context, ref reference.Named) ([]string, error)
+	GetCatalog(ctx context.Context, hostname string) ([]string, error)
+	BlobExists(ctx context.Context, ref reference.Named, dgst digest.Digest) (bool, error)
+	OpenBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error)
+	PutBlob(ctx context.Context, ref reference.Named, desc ocispec.Descriptor, content io.Reader) error
+	PutRawManifest(ctx context.Context, ref reference.Named, mediaType string, raw []byte) (digest.Digest, error)
+	GetReferrers(ctx context.Context, ref reference.Canonical, artifactType string) ([]ocispec.Descriptor, error)
+	DeleteManifest(ctx context.Context, ref reference.Canonical) error
 }
 
 // NewRegistryClient returns a new RegistryClient with a resolver
-func NewRegistryClient(resolver AuthConfigResolver, userAgent string, insecure bool) RegistryClient {
-	return &client{
+func NewRegistryClient(resolver AuthConfigResolver, userAgent string, insecure bool, opts ...Opt) RegistryClient {
+	c := &client{
 		authConfigResolver: resolver,
 		insecureRegistry:   insecure,
 		userAgent:          userAgent,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // AuthConfigResolver returns Auth Configuration for an index
@@ -40,6 +60,10 @@ type client struct {
 	authConfigResolver AuthConfigResolver
 	insecureRegistry   bool
 	userAgent          string
+
+	mirrors        map[string][]string // mirror URLs by registry domain
+	rewrites       map[string]string   // replacement by reference name prefix
+	serviceOptions registry.ServiceOptions
 }
 
 // ErrBlobCreated returned when a blob mount request was created
@@ -66,16 +90,15 @@ func (e httpProtoError) Error() string {
 
 // MountBlob into the registry, so it can be referenced by a manifest
 func (c *client) MountBlob(ctx context.Context, sourceRef reference.Canonical, targetRef reference.Named) error {
-	repoEndpoint, err := newDefaultRepositoryEndpoint(targetRef, c.insecureRegistry)
+	repo, err := c.getPushRepository(ctx, targetRef)
 	if err != nil {
 		return err
 	}
-	repoEndpoint.actions = []string{"pull", "push"}
-	repo, err := c.getRepositoryForReference(ctx, targetRef, repoEndpoint)
+	mountFrom, err := c.rewriteReference(sourceRef)
 	if err != nil {
 		return err
 	}
-	lu, err := repo.Blobs(ctx).Create(ctx, distributionclient.WithMountFrom(sourceRef))
+	lu, err := repo.Blobs(ctx).Create(ctx, distributionclient.WithMountFrom(mountFrom.(reference.Canonical)))
 	switch err.(type) {
 	case distribution.ErrBlobMounted:
 		logrus.Debugf("mount of blob %s succeeded", sourceRef)
@@ -91,13 +114,7 @@ func (c *client) MountBlob(ctx context.Context, sourceRef reference.Canonical, t
 
 // PutManifest sends the manifest to a registry and returns the new digest
 func (c *client) PutManifest(ctx context.Context, ref reference.Named, manifest distribution.Manifest) (digest.Digest, error) {
-	repoEndpoint, err := newDefaultRepositoryEndpoint(ref, c.insecureRegistry)
-	if err != nil {
-		return "", err
-	}
-
-	repoEndpoint.actions = []string{"pull", "push"}
-	repo, err := c.getRepositoryForReference(ctx, ref, repoEndpoint)
+	repo, err := c.getPushRepository(ctx, ref)
 	if err != nil {
 		return "", err
 	}
@@ -119,6 +136,29 @@ func (c *client) PutManifest(ctx context.Context, ref reference.Named, manifest
 	return dgst, nil
 }
 
+// getPushRepository returns the repository of the reference on its default
+// endpoint, with pull and push access.
+func (c *client) getPushRepository(ctx context.Context, ref reference.Named) (distribution.Repository, error) {
+	return c.getRepositoryWithActions(ctx, ref, "pull", "push")
+}
+
+// getRepositoryWithActions returns the repository of the reference on its
+// default endpoint, with access for the given actions. Mirrors are not used,
+// as they are read-only.
+func (c *client) getRepositoryWithActions(ctx context.Context, ref reference.Named, actions ...string) (distribution.Repository, error) {
+	ref, err := c.rewriteReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	repoEndpoint, err := newDefaultRepositoryEndpoint(ref, c.insecureRegistry, c.serviceOptions)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("using registry endpoint %s for %s", repoEndpoint.BaseURL(), ref)
+	repoEndpoint.actions = actions
+	return c.getRepositoryForReference(ctx, ref, repoEndpoint)
+}
+
 func (c *client) getRepositoryForReference(ctx context.Context, ref reference.Named, repoEndpoint repositoryEndpoint) (distribution.Repository, error) {
 	repoName, err := reference.WithName(repoEndpoint.repoName)
 	if err != nil {
@@ -141,16 +181,32 @@ func (c *client) getRepositoryForReference(ctx context.Context, ref reference.Na
 			}
 		}
 	}
-	return distributionclient.NewRepository(repoName, repoEndpoint.BaseURL(), httpTransport)
+	repo, err := distributionclient.NewRepository(repoName, repoEndpoint.BaseURL(), httpTransport)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{Repository: repo, baseURL: repoEndpoint.BaseURL(), client: &http.Client{Transport: httpTransport}}, nil
+}
+
+// repository is a distribution.Repository that also provides access to the
+// parts of the registry API that are not implemented by the distribution
+// client, such as the referrers API.
+type repository struct {
+	distribution.Repository
+	baseURL string
+	client  *http.Client
 }
 
 func (c *client) getHTTPTransportForRepoEndpoint(ctx context.Context, repoEndpoint repositoryEndpoint) (http.RoundTripper, error) {
+	actions := repoEndpoint.actions
+	if len(actions) == 0 {
+		actions = []string{"pull"}
+	}
 	httpTransport, err := getHTTPTransport(
 		c.authConfigResolver(ctx, repoEndpoint.indexInfo.Name),
 		repoEndpoint.endpoint,
-		repoEndpoint.repoName,
 		c.userAgent,
-		repoEndpoint.actions,
+		auth.RepositoryScope{Repository: repoEndpoint.repoName, Actions: actions},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure transport: %w", err)
@@ -184,6 +240,259 @@ func (c *client) GetManifestList(ctx context.Context, ref reference.Named) ([]ma
 	return result, err
 }
 
+// GetRawManifest returns the descriptor and the raw content of the manifest,
+// manifest list, or image index for the reference. Unlike [GetManifest] and
+// [GetManifestList], it does not fetch the manifests or image configs that
+// are referenced by it.
+func (c *client) GetRawManifest(ctx context.Context, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+	var (
+		desc ocispec.Descriptor
+		raw  []byte
+	)
+	fetch := func(ctx context.Context, repo distribution.Repository, ref reference.Named) (bool, error) {
+		var err error
+		desc, raw, err = fetchRawManifest(ctx, repo, ref)
+		return raw != nil, err
+	}
+
+	err := c.iterateEndpoints(ctx, ref, fetch)
+	return desc, raw, err
+}
+
+// GetUpstreamManifest is like [GetRawManifest], but it fetches the manifest
+// from the registry of the reference itself, and never from a mirror, which
+// may be out of date. It is intended for checking which manifest a tag
+// currently refers to. The repository is accessed with the given actions,
+// or "pull" if none are given.
+func (c *client) GetUpstreamManifest(ctx context.Context, ref reference.Named, actions ...string) (ocispec.Descriptor, []byte, error) {
+	if len(actions) == 0 {
+		actions = []string{"pull"}
+	}
+	repo, err := c.getRepositoryWithActions(ctx, ref, actions...)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	desc, raw, err := fetchRawManifest(ctx, repo, ref)
+	if isManifestUnknown(err) {
+		return ocispec.Descriptor{}, nil, notFoundError{errors.New("no such manifest: " + ref.String())}
+	}
+	return desc, raw, err
+}
+
+// GetBlob returns the content of the blob with the given digest from the
+// repository of the reference. The content is verified against the digest.
+// It is intended for small blobs, such as image configs, as the content is
+// read into memory.
+func (c *client) GetBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) ([]byte, error) {
+	var blob []byte
+	fetch := func(ctx context.Context, repo distribution.Repository, _ reference.Named) (bool, error) {
+		var err error
+		blob, err = pullManifestSchemaV2ImageConfig(ctx, dgst, repo)
+		return blob != nil, err
+	}
+
+	err := c.iterateEndpoints(ctx, ref, fetch)
+	return blob, err
+}
+
+// BlobExists returns whether the blob with the given digest exists in the
+// repository of the reference.
+func (c *client) BlobExists(ctx context.Context, ref reference.Named, dgst digest.Digest) (bool, error) {
+	repo, err := c.getPushRepository(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	_, err = repo.Blobs(ctx).Stat(ctx, dgst)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, distribution.ErrBlobUnknown):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// OpenBlob returns a reader for the content of the blob with the given digest
+// in the repository of the reference. The content is not verified against the
+// digest.
+func (c *client) OpenBlob(ctx context.Context, ref reference.Named, dgst digest.Digest) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	fetch := func(ctx context.Context, repo distribution.Repository, _ reference.Named) (bool, error) {
+		var err error
+		rc, err = repo.Blobs(ctx).Open(ctx, dgst)
+		return rc != nil, err
+	}
+
+	err := c.iterateEndpoints(ctx, ref, fetch)
+	return rc, err
+}
+
+// PutBlob uploads the content of a blob to the repository of the reference.
+// The registry verifies the content against the digest of the descriptor.
+func (c *client) PutBlob(ctx context.Context, ref reference.Named, desc ocispec.Descriptor, content io.Reader) error {
+	repo, err := c.getPushRepository(ctx, ref)
+	if err != nil {
+		return err
+	}
+	bw, err := repo.Blobs(ctx).Create(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s to %s: %w", desc.Digest, ref, err)
+	}
+	if _, err := io.Copy(bw, content); err != nil {
+		_ = bw.Cancel(ctx)
+		return fmt.Errorf("failed to upload blob %s to %s: %w", desc.Digest, ref, err)
+	}
+	_, err = bw.Commit(ctx, distribution.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s to %s: %w", desc.Digest, ref, err)
+	}
+	return nil
+}
+
+// PutRawManifest sends the raw content of a manifest, manifest list, or
+// image index to a registry, and returns its digest. Unlike PutManifest,
+// the content is sent as-is, so that its digest is preserved.
+func (c *client) PutRawManifest(ctx context.Context, ref reference.Named, mediaType string, raw []byte) (digest.Digest, error) {
+	return c.PutManifest(ctx, ref, rawManifest{mediaType: mediaType, raw: raw})
+}
+
+// rawManifest is a distribution.Manifest for the raw content of a manifest.
+type rawManifest struct {
+	mediaType string
+	raw       []byte
+}
+
+func (rawManifest) References() []distribution.Descriptor {
+	return nil
+}
+
+func (m rawManifest) Payload() (string, []byte, error) {
+	return m.mediaType, m.raw, nil
+}
+
+// DeleteManifest deletes the manifest with the digest of the reference from
+// the registry, which also removes all tags that refer to it. Registries are
+// not required to support deleting manifests; an error that satisfies
+// errdefs.IsNotImplemented is returned if the registry does not.
+func (c *client) DeleteManifest(ctx context.Context, ref reference.Canonical) error {
+	repo, err := c.getRepositoryWithActions(ctx, ref, "pull", "delete")
+	if err != nil {
+		return err
+	}
+	if err := deleteManifest(ctx, repo, ref.Digest()); err != nil {
+		return fmt.Errorf("failed to delete manifest %s: %w", ref, err)
+	}
+	return nil
+}
+
+// GetTags returns all tags of the repository of the reference, in the order
+// they are returned by the registry. Paginated results are followed until
+// all tags are fetched.
+func (c *client) GetTags(ctx context.Context, ref reference.Named) ([]string, error) {
+	var tags []string
+	fetch := func(ctx context.Context, repo distribution.Repository, _ reference.Named) (bool, error) {
+		var err error
+		tags, err = repo.Tags(ctx).All(ctx)
+		return err == nil, err
+	}
+
+	err := c.iterateEndpoints(ctx, reference.TrimNamed(ref), fetch)
+	if errors.As(err, &notFoundError{}) {
+		return nil, notFoundError{errors.New("no such repository: " + reference.FamiliarName(ref))}
+	}
+	return tags, err
+}
+
+// GetReferrers returns the descriptors of the manifests that have the
+// manifest of the reference as their subject, such as signatures and SBOMs.
+// If artifactType is not empty, only referrers with that artifact type are
+// returned. The referrers API is used if the registry supports it; otherwise
+// the referrers are read from the image index that is tagged according to the
+// referrers tag schema.
+func (c *client) GetReferrers(ctx context.Context, ref reference.Canonical, artifactType string) ([]ocispec.Descriptor, error) {
+	var referrers []ocispec.Descriptor
+	fetch := func(ctx context.Context, repo distribution.Repository, _ reference.Named) (bool, error) {
+		var err error
+		referrers, err = fetchReferrers(ctx, repo, ref.Digest(), artifactType)
+		return err == nil, err
+	}
+
+	err := c.iterateEndpoints(ctx, ref, fetch)
+	return referrers, err
+}
+
+// catalogPageSize is the number of repositories to request per page when
+// listing the catalog of a registry.
+const catalogPageSize = 100
+
+// GetCatalog returns the names of all repositories in the registry with the
+// given hostname, using the catalog API. Paginated results are followed until
+// all names are fetched. Not all registries implement the catalog API, or
+// allow it to be used by all users.
+func (c *client) GetCatalog(ctx context.Context, hostname string) ([]string, error) {
+	endpoints, err := c.allEndpoints(ctx, hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := auth.RegistryScope{Name: "catalog", Actions: []string{"*"}}
+	for _, endpoint := range endpoints {
+		if endpoint.URL.Scheme == "http" && !c.insecureRegistry && !endpoint.TLSConfig.InsecureSkipVerify {
+			logrus.Debugf("skipping non-tls registry endpoint: %s", endpoint.URL)
+			continue
+		}
+		if c.insecureRegistry {
+			endpoint.TLSConfig.InsecureSkipVerify = true
+		}
+		httpTransport, err := getHTTPTransport(c.authConfigResolver(ctx, hostname), endpoint, c.userAgent, scope)
+		if err != nil {
+			if strings.Contains(err.Error(), "server gave HTTP response to HTTPS client") {
+				logrus.Debugf("skipping registry endpoint %s: %v", endpoint.URL, err)
+				continue
+			}
+			return nil, fmt.Errorf("failed to configure transport: %w", err)
+		}
+		reg, err := distributionclient.NewRegistry(endpoint.URL.String(), httpTransport)
+		if err != nil {
+			return nil, err
+		}
+		names, err := fetchCatalog(ctx, reg)
+		if err != nil {
+			if continueOnError(err) {
+				logrus.Debugf("continuing on error (%T) %s", err, err)
+				continue
+			}
+			return nil, err
+		}
+		return names, nil
+	}
+	return nil, notFoundError{errors.New("no catalog available for registry: " + hostname)}
+}
+
+func fetchCatalog(ctx context.Context, reg distributionclient.Registry) ([]string, error) {
+	var (
+		names []string
+		last  string
+	)
+	for {
+		entries := make([]string, catalogPageSize)
+		n, err := reg.Repositories(ctx, entries, last)
+		names = append(names, entries[:n]...)
+		if errors.Is(err, io.EOF) || (err == nil && n == 0) {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		last = entries[n-1]
+	}
+}
+
 func getManifestOptionsFromReference(ref reference.Named) (digest.Digest, []distribution.ManifestServiceOption, error) {
 	if tagged, isTagged := ref.(reference.NamedTagged); isTagged {
 		tag := tagged.Tag()
@@ -0,0 +1,437 @@
+package registryclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	registrytypes "github.com/moby/moby/api/types/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+// testRegistry is a minimal implementation of the registry API, serving
+// manifests and blobs from memory. Manifests can be deleted, but not pushed.
+type testRegistry struct {
+	*httptest.Server
+	manifests map[string]ocispec.Descriptor // "name:tag" or "name@digest"
+	blobs     map[digest.Digest][]byte
+
+	// referrersAPI enables the referrers API, which lists the manifests
+	// in the repository that have the given digest as their subject, with
+	// a page size of 1.
+	referrersAPI bool
+
+	// deleteDisabled makes the registry reject deleting manifests, as
+	// registries do if deleting is not enabled.
+	deleteDisabled bool
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+	r := &testRegistry{
+		manifests: make(map[string]ocispec.Descriptor),
+		blobs:     make(map[digest.Digest][]byte),
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// host returns the host (and port) of the registry.
+func (r *testRegistry) host() string {
+	u, _ := url.Parse(r.URL)
+	return u.Host
+}
+
+// addBlob adds a blob, and returns its descriptor.
+func (r *testRegistry) addBlob(t *testing.T, mediaType string, v any) ocispec.Descriptor {
+	t.Helper()
+	b, err := json.Marshal(v)
+	assert.NilError(t, err)
+	dgst := digest.FromBytes(b)
+	r.blobs[dgst] = b
+	return ocispec.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(b))}
+}
+
+// addManifest adds a manifest to the repository, and tags it if tag is not
+// empty.
+func (r *testRegistry) addManifest(t *testing.T, name, tag, mediaType string, v any) ocispec.Descriptor {
+	t.Helper()
+	desc := r.addBlob(t, mediaType, v)
+	r.manifests[name+"@"+desc.Digest.String()] = desc
+	if tag != "" {
+		r.manifests[name+":"+tag] = desc
+	}
+	return desc
+}
+
+func (r *testRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if path == "_catalog" {
+		var names []string
+		for key := range r.manifests {
+			if name, _, ok := strings.Cut(key, "@"); ok && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		r.writeList(w, req, "repositories", names)
+		return
+	}
+	if name, ok := strings.CutSuffix(path, "/tags/list"); ok {
+		var tags []string
+		for key := range r.manifests {
+			if n, tag, ok := strings.Cut(key, ":"); ok && n == name {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			writeError(w, http.StatusNotFound, "NAME_UNKNOWN")
+			return
+		}
+		r.writeList(w, req, "tags", tags)
+		return
+	}
+	if name, dgst, ok := strings.Cut(path, "/referrers/"); ok && r.referrersAPI {
+		r.writeReferrers(w, req, name, digest.Digest(dgst))
+		return
+	}
+	if name, ref, ok := strings.Cut(path, "/manifests/"); ok {
+		key := name + ":" + ref
+		if strings.Contains(ref, ":") {
+			key = name + "@" + ref
+		}
+		desc, ok := r.manifests[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN")
+			return
+		}
+		if req.Method == http.MethodDelete {
+			r.deleteManifest(w, name, desc)
+			return
+		}
+		r.writeContent(w, req, desc)
+		return
+	}
+	if _, dgst, ok := strings.Cut(path, "/blobs/"); ok {
+		b, ok := r.blobs[digest.Digest(dgst)]
+		if !ok {
+			writeError(w, http.StatusNotFound, "BLOB_UNKNOWN")
+			return
+		}
+		r.writeContent(w, req, ocispec.Descriptor{
+			MediaType: "application/octet-stream",
+			Digest:    digest.Digest(dgst),
+			Size:      int64(len(b)),
+		})
+		return
+	}
+	writeError(w, http.StatusNotFound, "NAME_UNKNOWN")
+}
+
+// writeList writes a paginated list, sorted lexically, with the "n" (page
+// size, which defaults to 2) and "last" query parameters. A Link header is set
+// if there are more results.
+func (r *testRegistry) writeList(w http.ResponseWriter, req *http.Request, key string, items []string) {
+	slices.Sort(items)
+	pageSize := 2
+	if n, err := strconv.Atoi(req.URL.Query().Get("n")); err == nil {
+		pageSize = n
+	}
+	if last := req.URL.Query().Get("last"); last != "" {
+		i, found := slices.BinarySearch(items, last)
+		if found {
+			i++
+		}
+		items = items[i:]
+	}
+	if len(items) > pageSize {
+		items = items[:pageSize]
+		next := *req.URL
+		q := next.Query()
+		q.Set("n", strconv.Itoa(pageSize))
+		q.Set("last", items[len(items)-1])
+		next.RawQuery = q.Encode()
+		w.Header().Set("Link", `<`+next.String()+`>; rel="next"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string][]string{key: items})
+}
+
+func (r *testRegistry) writeReferrers(w http.ResponseWriter, req *http.Request, name string, subject digest.Digest) {
+	var referrers []ocispec.Descriptor
+	for key, desc := range r.manifests {
+		if n, _, ok := strings.Cut(key, "@"); !ok || n != name {
+			continue
+		}
+		var m ocispec.Manifest
+		if err := json.Unmarshal(r.blobs[desc.Digest], &m); err != nil || m.Subject == nil || m.Subject.Digest != subject {
+			continue
+		}
+		desc.ArtifactType = m.ArtifactType
+		desc.Annotations = m.Annotations
+		referrers = append(referrers, desc)
+	}
+	slices.SortFunc(referrers, func(a, b ocispec.Descriptor) int {
+		return strings.Compare(a.Digest.String(), b.Digest.String())
+	})
+	if last := req.URL.Query().Get("last"); last != "" {
+		referrers = slices.DeleteFunc(referrers, func(desc ocispec.Descriptor) bool {
+			return desc.Digest.String() <= last
+		})
+	}
+	if len(referrers) > 1 {
+		referrers = referrers[:1]
+		next := *req.URL
+		q := next.Query()
+		q.Set("last", referrers[0].Digest.String())
+		next.RawQuery = q.Encode()
+		w.Header().Set("Link", `<`+next.String()+`>; rel="next"`)
+	}
+	w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+	_ = json.NewEncoder(w).Encode(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: referrers,
+	})
+}
+
+// deleteManifest deletes a manifest and all tags that refer to it.
+func (r *testRegistry) deleteManifest(w http.ResponseWriter, name string, desc ocispec.Descriptor) {
+	if r.deleteDisabled {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	for key, d := range r.manifests {
+		if d.Digest == desc.Digest && (strings.HasPrefix(key, name+":") || strings.HasPrefix(key, name+"@")) {
+			delete(r.manifests, key)
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *testRegistry) writeContent(w http.ResponseWriter, req *http.Request, desc ocispec.Descriptor) {
+	w.Header().Set("Content-Type", desc.MediaType)
+	w.Header().Set("Content-Length", strconv.FormatInt(desc.Size, 10))
+	w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+	w.WriteHeader(http.StatusOK)
+	if req.Method != http.MethodHead {
+		_, _ = w.Write(r.blobs[desc.Digest])
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`{"errors":[{"code":"` + code + `","message":"` + strings.ToLower(code) + `"}]}`))
+}
+
+func newTestClient() RegistryClient {
+	return NewRegistryClient(func(context.Context, string) registrytypes.AuthConfig {
+		return registrytypes.AuthConfig{}
+	}, "test", true)
+}
+
+func TestGetRawManifestAndBlob(t *testing.T) {
+	r := newTestRegistry(t)
+	config := r.addBlob(t, ocispec.MediaTypeImageConfig, ocispec.Image{
+		Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"},
+		RootFS:   ocispec.RootFS{Type: "layers"},
+	})
+	manifest := r.addManifest(t, "app", "", ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{},
+	})
+	manifest.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	index := r.addManifest(t, "app", "latest", ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifest},
+	})
+
+	ctx := context.Background()
+	c := newTestClient()
+
+	ref, err := reference.ParseNormalizedNamed(r.host() + "/app:latest")
+	assert.NilError(t, err)
+	desc, raw, err := c.GetRawManifest(ctx, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(desc.MediaType, ocispec.MediaTypeImageIndex))
+	assert.Check(t, is.Equal(desc.Digest, index.Digest))
+	assert.Check(t, is.DeepEqual(raw, r.blobs[index.Digest]))
+
+	byDigest, err := reference.WithDigest(reference.TrimNamed(ref), manifest.Digest)
+	assert.NilError(t, err)
+	desc, raw, err = c.GetRawManifest(ctx, byDigest)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(desc.MediaType, ocispec.MediaTypeImageManifest))
+	assert.Check(t, is.DeepEqual(raw, r.blobs[manifest.Digest]))
+
+	blob, err := c.GetBlob(ctx, ref, config.Digest)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(blob, r.blobs[config.Digest]))
+
+	_, err = c.GetBlob(ctx, ref, digest.FromString("missing"))
+	assert.Check(t, is.ErrorContains(err, "unknown blob"))
+
+	missing, err := reference.ParseNormalizedNamed(r.host() + "/app:missing")
+	assert.NilError(t, err)
+	_, _, err = c.GetRawManifest(ctx, missing)
+	assert.Check(t, is.ErrorContains(err, "no such manifest"))
+}
+
+func TestGetTagsAndCatalog(t *testing.T) {
+	r := newTestRegistry(t)
+	for _, tag := range []string{"latest", "1.0", "1.1", "2.0", "edge"} {
+		r.addManifest(t, "app", tag, ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest})
+	}
+	r.addManifest(t, "other/image", "latest", ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest})
+	r.addManifest(t, "tools", "", ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Annotations: map[string]string{"untagged": "true"}})
+
+	ctx := context.Background()
+	c := newTestClient()
+
+	ref, err := reference.ParseNormalizedNamed(r.host() + "/app")
+	assert.NilError(t, err)
+	tags, err := c.GetTags(ctx, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(tags, []string{"1.0", "1.1", "2.0", "edge", "latest"}))
+
+	missing, err := reference.ParseNormalizedNamed(r.host() + "/missing")
+	assert.NilError(t, err)
+	_, err = c.GetTags(ctx, missing)
+	assert.Check(t, is.Error(err, "no such repository: "+r.host()+"/missing"))
+
+	names, err := c.GetCatalog(ctx, r.host())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(names, []string{"app", "other/image", "tools"}))
+}
+
+func TestGetReferrers(t *testing.T) {
+	reg := newTestRegistry(t)
+	config := reg.addBlob(t, ocispec.MediaTypeImageConfig, ocispec.Image{})
+	subject := reg.addManifest(t, "app", "latest", ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{},
+	})
+	addReferrer := func(artifactType string) ocispec.Descriptor {
+		desc := reg.addManifest(t, "app", "", ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			Versioned:    specs.Versioned{SchemaVersion: 2},
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: artifactType,
+			Config:       ocispec.DescriptorEmptyJSON,
+			Layers:       []ocispec.Descriptor{ocispec.DescriptorEmptyJSON},
+			Subject:      &subject,
+			Annotations:  map[string]string{"org.example.type": artifactType},
+		})
+		desc.ArtifactType = artifactType
+		desc.Annotations = map[string]string{"org.example.type": artifactType}
+		return desc
+	}
+	sbom := addReferrer("application/spdx+json")
+	signature := addReferrer("application/vnd.dev.sigstore.bundle.v0.3+json")
+	expected := []ocispec.Descriptor{sbom, signature}
+	slices.SortFunc(expected, func(a, b ocispec.Descriptor) int {
+		return strings.Compare(a.Digest.String(), b.Digest.String())
+	})
+
+	ref, err := reference.ParseNormalizedNamed(reg.host() + "/app@" + subject.Digest.String())
+	assert.NilError(t, err)
+	canonical := ref.(reference.Canonical)
+
+	t.Run("tag schema without referrers", func(t *testing.T) {
+		referrers, err := newTestClient().GetReferrers(context.Background(), canonical, "")
+		assert.NilError(t, err)
+		assert.Check(t, is.Len(referrers, 0))
+	})
+
+	t.Run("tag schema", func(t *testing.T) {
+		reg.addManifest(t, "app", "sha256-"+subject.Digest.Encoded(), ocispec.MediaTypeImageIndex, ocispec.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: expected,
+		})
+		referrers, err := newTestClient().GetReferrers(context.Background(), canonical, "")
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(referrers, expected))
+
+		referrers, err = newTestClient().GetReferrers(context.Background(), canonical, "application/spdx+json")
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(referrers, []ocispec.Descriptor{sbom}))
+	})
+
+	t.Run("referrers API", func(t *testing.T) {
+		reg.referrersAPI = true
+		delete(reg.manifests, "app:sha256-"+subject.Digest.Encoded())
+
+		referrers, err := newTestClient().GetReferrers(context.Background(), canonical, "")
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(referrers, expected))
+
+		referrers, err = newTestClient().GetReferrers(context.Background(), canonical, "application/vnd.dev.sigstore.bundle.v0.3+json")
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(referrers, []ocispec.Descriptor{signature}))
+	})
+}
+
+func TestDeleteManifest(t *testing.T) {
+	r := newTestRegistry(t)
+	manifest := r.addManifest(t, "app", "latest", ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest})
+	r.manifests["app:stable"] = manifest
+	other := r.addManifest(t, "app", "edge", ocispec.MediaTypeImageManifest, ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Annotations: map[string]string{"edge": "true"}})
+
+	ctx := context.Background()
+	c := newTestClient()
+
+	named, err := reference.ParseNormalizedNamed(r.host() + "/app")
+	assert.NilError(t, err)
+	ref, err := reference.WithDigest(named, manifest.Digest)
+	assert.NilError(t, err)
+
+	t.Run("unsupported", func(t *testing.T) {
+		r.deleteDisabled = true
+		defer func() { r.deleteDisabled = false }()
+		err := c.DeleteManifest(ctx, ref)
+		assert.Check(t, is.ErrorContains(err, "registry does not support deleting manifests"))
+		assert.Check(t, errdefs.IsNotImplemented(err))
+		assert.Check(t, is.Len(r.manifests, 5))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NilError(t, c.DeleteManifest(ctx, ref))
+		tags, err := c.GetTags(ctx, named)
+		assert.NilError(t, err)
+		assert.Check(t, is.DeepEqual(tags, []string{"edge"}))
+		_, ok := r.manifests["app@"+other.Digest.String()]
+		assert.Check(t, ok)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		err := c.DeleteManifest(ctx, ref)
+		assert.Check(t, is.ErrorContains(err, "no such manifest"))
+		assert.Check(t, errdefs.IsNotFound(err))
+	})
+}
+
+func TestReferrersTag(t *testing.T) {
+	dgst := digest.FromString("foo")
+	assert.Check(t, is.Equal(referrersTag(dgst), "sha256-"+dgst.Encoded()))
+}
@@ -6,6 +6,7 @@
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"time"
 
 	"github.com/distribution/reference"
@@ -27,9 +28,9 @@ func (r repositoryEndpoint) BaseURL() string {
 	return r.endpoint.URL.String()
 }
 
-func newDefaultRepositoryEndpoint(ref reference.Named, insecure bool) (repositoryEndpoint, error) {
+func newDefaultRepositoryEndpoint(ref reference.Named, insecure bool, serviceOpts registry.ServiceOptions) (repositoryEndpoint, error) {
 	indexInfo := registry.NewIndexInfo(ref)
-	endpoint, err := getDefaultEndpoint(ref, !indexInfo.Secure)
+	endpoint, err := getDefaultEndpoint(ref, !indexInfo.Secure, serviceOpts)
 	if err != nil {
 		return repositoryEndpoint{}, err
 	}
@@ -43,8 +44,9 @@ func newDefaultRepositoryEndpoint(ref reference.Named, insecure bool) (repositor
 	}, nil
 }
 
-func getDefaultEndpoint(repoName reference.Named, insecure bool) (registry.APIEndpoint, error) {
-	registryService, err := registry.NewService(registry.ServiceOptions{})
+func getDefaultEndpoint(repoName reference.Named, insecure bool, serviceOpts registry.ServiceOptions) (registry.APIEndpoint, error) {
+	serviceOpts.InsecureRegistries = slices.Clone(serviceOpts.InsecureRegistries)
+	registryService, err := registry.NewService(serviceOpts)
 	if err != nil {
 		return registry.APIEndpoint{}, err
 	}
@@ -64,8 +66,10 @@ func getDefaultEndpoint(repoName reference.Named, insecure bool) (registry.APIEn
 	return endpoint, nil
 }
 
-// getHTTPTransport builds a transport for use in communicating with a registry
-func getHTTPTransport(authConfig registrytypes.AuthConfig, endpoint registry.APIEndpoint, repoName, userAgent string, actions []string) (http.RoundTripper, error) {
+// getHTTPTransport builds a transport for use in communicating with a registry,
+// requesting a token for the given scope if the registry uses token-based
+// authentication.
+func getHTTPTransport(authConfig registrytypes.AuthConfig, endpoint registry.APIEndpoint, userAgent string, scope auth.Scope) (http.RoundTripper, error) {
 	// get the http transport, this will be used in a client to upload manifest
 	base := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -88,11 +92,12 @@ func getHTTPTransport(authConfig registrytypes.AuthConfig, endpoint registry.API
 		passThruTokenHandler := &existingTokenHandler{token: authConfig.RegistryToken}
 		modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, passThruTokenHandler))
 	} else {
-		if len(actions) == 0 {
-			actions = []string{"pull"}
-		}
 		creds := &staticCredentialStore{authConfig: &authConfig}
-		tokenHandler := auth.NewTokenHandler(authTransport, creds, repoName, actions...)
+		tokenHandler := auth.NewTokenHandlerWithOptions(auth.TokenHandlerOptions{
+			Transport:   authTransport,
+			Credentials: creds,
+			Scopes:      []auth.Scope{scope},
+		})
 		basicHandler := auth.NewBasicHandler(creds)
 		modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler))
 	}
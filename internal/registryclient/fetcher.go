@@ -5,6 +5,10 @@
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/distribution/reference"
 	"github.com/docker/cli/cli/manifest/types"
@@ -16,6 +20,7 @@
 	"github.com/docker/distribution/registry/api/errcode"
 	v2 "github.com/docker/distribution/registry/api/v2"
 	distclient "github.com/docker/distribution/registry/client"
+	registrytypes "github.com/moby/moby/api/types/registry"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
@@ -55,6 +60,24 @@ func fetchList(ctx context.Context, repo distribution.Repository, ref reference.
 	}
 }
 
+// fetchRawManifest pulls a manifest, manifest list, or image index from a
+// registry, and returns its descriptor and canonical content.
+func fetchRawManifest(ctx context.Context, repo distribution.Repository, ref reference.Named) (ocispec.Descriptor, []byte, error) {
+	manifest, err := getManifest(ctx, repo, ref)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	desc, err := validateManifestDigest(ref, manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	_, raw, err := manifest.Payload()
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, raw, nil
+}
+
 func getManifest(ctx context.Context, repo distribution.Repository, ref reference.Named) (distribution.Manifest, error) {
 	manSvc, err := repo.Manifests(ctx)
 	if err != nil {
@@ -189,12 +212,197 @@ func pullManifestList(ctx context.Context, ref reference.Named, repo distributio
 		// Replace platform from config
 		p := manifestDescriptor.Platform
 		imageManifest.Descriptor.Platform = types.OCIPlatform(&p)
+		imageManifest.Descriptor.Annotations = manifestDescriptor.Annotations
 
 		infos = append(infos, imageManifest)
 	}
 	return infos, nil
 }
 
+// errReferrersUnsupported is returned if a registry does not support the
+// referrers API.
+var errReferrersUnsupported = errors.New("registry does not support the referrers API")
+
+// fetchReferrers fetches the descriptors of the manifests that have the
+// manifest with the given digest as their subject, using the referrers API
+// if the registry supports it, or the referrers tag schema otherwise.
+func fetchReferrers(ctx context.Context, repo distribution.Repository, dgst digest.Digest, artifactType string) ([]ocispec.Descriptor, error) {
+	referrers, err := fetchReferrersFromAPI(ctx, repo, dgst, artifactType)
+	if errors.Is(err, errReferrersUnsupported) {
+		logrus.Debugf("%s; falling back to the referrers tag schema", err)
+		referrers, err = fetchReferrersFromTag(ctx, repo, dgst)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Registries are not required to apply the filter, and the filter is
+	// never applied when using the tag schema.
+	if artifactType != "" {
+		referrers = slices.DeleteFunc(referrers, func(desc ocispec.Descriptor) bool {
+			return desc.ArtifactType != artifactType
+		})
+	}
+	return referrers, nil
+}
+
+func fetchReferrersFromAPI(ctx context.Context, repo distribution.Repository, dgst digest.Digest, artifactType string) ([]ocispec.Descriptor, error) {
+	r, ok := repo.(*repository)
+	if !ok {
+		return nil, errReferrersUnsupported
+	}
+	u, err := url.Parse(r.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u = u.JoinPath("v2", r.Named().Name(), "referrers", dgst.String())
+	if artifactType != "" {
+		u.RawQuery = url.Values{"artifactType": {artifactType}}.Encode()
+	}
+
+	referrers := []ocispec.Descriptor{}
+	for next, first := u, true; next != nil; first = false {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", ocispec.MediaTypeImageIndex)
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		index, err := readReferrersResponse(resp, first)
+		if err != nil {
+			return nil, err
+		}
+		referrers = append(referrers, index.Manifests...)
+		next = nextLink(resp)
+	}
+	return referrers, nil
+}
+
+func readReferrersResponse(resp *http.Response, first bool) (ocispec.Index, error) {
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound && first:
+		// Registries that support the referrers API must not return a 404.
+		return ocispec.Index{}, errReferrersUnsupported
+	case !distclient.SuccessStatus(resp.StatusCode):
+		return ocispec.Index{}, distclient.HandleErrorResponse(resp)
+	}
+	var index ocispec.Index
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return ocispec.Index{}, fmt.Errorf("invalid referrers response: %w", err)
+	}
+	return index, nil
+}
+
+// nextLink returns the URL of the next page of a paginated response, as set
+// in its Link header, or nil if it is the last page.
+func nextLink(resp *http.Response) *url.URL {
+	for _, link := range resp.Header.Values("Link") {
+		target, params, _ := strings.Cut(link, ";")
+		if !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		u, err := resp.Request.URL.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return nil
+		}
+		return u
+	}
+	return nil
+}
+
+func fetchReferrersFromTag(ctx context.Context, repo distribution.Repository, dgst digest.Digest) ([]ocispec.Descriptor, error) {
+	tagged, err := reference.WithTag(repo.Named(), referrersTag(dgst))
+	if err != nil {
+		return nil, err
+	}
+	_, raw, err := fetchRawManifest(ctx, repo, tagged)
+	if err != nil {
+		if isManifestUnknown(err) {
+			return []ocispec.Descriptor{}, nil
+		}
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return nil, fmt.Errorf("invalid referrers index %s: %w", tagged.Tag(), err)
+	}
+	return index.Manifests, nil
+}
+
+// referrersTag returns the tag of the image index that lists the referrers of
+// a manifest, according to the referrers tag schema: "<alg>-<ref>", with any
+// characters that are not allowed in a tag replaced by "-".
+func referrersTag(dgst digest.Digest) string {
+	alg, enc := dgst.Algorithm().String(), dgst.Encoded()
+	if len(alg) > 32 {
+		alg = alg[:32]
+	}
+	if len(enc) > 64 {
+		enc = enc[:64]
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, alg+"-"+enc)
+}
+
+// deleteManifest deletes the manifest with the given digest from the
+// repository.
+func deleteManifest(ctx context.Context, repo distribution.Repository, dgst digest.Digest) error {
+	r, ok := repo.(*repository)
+	if !ok {
+		return errors.New("unsupported repository")
+	}
+	u, err := url.Parse(r.baseURL)
+	if err != nil {
+		return err
+	}
+	u = u.JoinPath("v2", r.Named().Name(), "manifests", dgst.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if distclient.SuccessStatus(resp.StatusCode) {
+		return nil
+	}
+	err = distclient.HandleErrorResponse(resp)
+	switch {
+	case resp.StatusCode == http.StatusMethodNotAllowed, hasErrorCode(err, errcode.ErrorCodeUnsupported):
+		// Registries that do not allow deleting manifests return a 405,
+		// which is not always accompanied by an error in the body.
+		return notImplementedError{errors.New("registry does not support deleting manifests")}
+	case hasErrorCode(err, v2.ErrorCodeManifestUnknown):
+		return notFoundError{errors.New("no such manifest: " + dgst.String())}
+	}
+	return err
+}
+
+func isManifestUnknown(err error) bool {
+	return hasErrorCode(err, v2.ErrorCodeManifestUnknown)
+}
+
+// hasErrorCode returns whether err is, or starts with, a registry error with
+// the given error code.
+func hasErrorCode(err error, code errcode.ErrorCode) bool {
+	var errs errcode.Errors
+	if errors.As(err, &errs) && len(errs) > 0 {
+		err = errs[0]
+	}
+	var e errcode.Error
+	return errors.As(err, &e) && e.Code == code
+}
+
 func continueOnError(err error) bool {
 	switch v := err.(type) {
 	case errcode.Errors:
@@ -215,14 +423,53 @@ func continueOnError(err error) bool {
 	return false
 }
 
+// iterateEndpoints calls each with the repository of the reference on the
+// mirrors of its registry, in the configured order, and then on the
+// endpoints of the registry itself, until it is done. Errors on a mirror
+// fall back to the next endpoint.
 func (c *client) iterateEndpoints(ctx context.Context, namedRef reference.Named, each func(context.Context, distribution.Repository, reference.Named) (bool, error)) error {
-	endpoints, err := allEndpoints(ctx, namedRef, c.insecureRegistry)
+	ref, err := c.rewriteReference(namedRef)
 	if err != nil {
 		return err
 	}
+	mirrors, err := c.mirrorEndpoints(ctx, reference.Domain(ref))
+	if err != nil {
+		return err
+	}
+	endpoints, err := c.allEndpoints(ctx, reference.Domain(ref))
+	if err != nil {
+		return err
+	}
+
+	repoName := reference.Path(reference.TrimNamed(ref))
+	indexInfo := registry.NewIndexInfo(ref)
 
-	repoName := reference.Path(reference.TrimNamed(namedRef))
-	indexInfo := registry.NewIndexInfo(namedRef)
+	for _, endpoint := range mirrors {
+		if c.insecureRegistry {
+			endpoint.TLSConfig.InsecureSkipVerify = true
+		}
+		repoEndpoint := repositoryEndpoint{
+			repoName: repoName,
+			// Credentials for a mirror are stored under its own hostname.
+			indexInfo: &registrytypes.IndexInfo{Name: endpoint.URL.Host, Secure: endpoint.URL.Scheme == "https"},
+			endpoint:  endpoint,
+		}
+		repo, err := c.getRepositoryForReference(ctx, ref, repoEndpoint)
+		if err != nil {
+			logrus.Debugf("falling back from registry mirror %s: %v", endpoint.URL, err)
+			continue
+		}
+		done, err := each(ctx, repo, namedRef)
+		switch {
+		case err != nil:
+			logrus.Debugf("falling back from registry mirror %s: %v", endpoint.URL, err)
+		case done:
+			logrus.Debugf("using registry mirror %s for %s", endpoint.URL, namedRef)
+			return nil
+		default:
+			logrus.Debugf("falling back from registry mirror %s: %s not found", endpoint.URL, namedRef)
+		}
+	}
 
 	confirmedTLSRegistries := make(map[string]bool)
 	for _, endpoint := range endpoints {
@@ -241,7 +488,7 @@ func (c *client) iterateEndpoints(ctx context.Context, namedRef reference.Named,
 			indexInfo: indexInfo,
 			endpoint:  endpoint,
 		}
-		repo, err := c.getRepositoryForReference(ctx, namedRef, repoEndpoint)
+		repo, err := c.getRepositoryForReference(ctx, ref, repoEndpoint)
 		if err != nil {
 			logrus.Debugf("error %s with repo endpoint %+v", err, repoEndpoint)
 			var protoErr httpProtoError
@@ -251,7 +498,7 @@ func (c *client) iterateEndpoints(ctx context.Context, namedRef reference.Named,
 			return err
 		}
 
-		if endpoint.URL.Scheme == "http" && !c.insecureRegistry {
+		if endpoint.URL.Scheme == "http" && !endpoint.TLSConfig.InsecureSkipVerify {
 			logrus.Debugf("skipping non-tls registry endpoint: %s", endpoint.URL)
 			continue
 		}
@@ -268,28 +515,36 @@ func (c *client) iterateEndpoints(ctx context.Context, namedRef reference.Named,
 			return err
 		}
 		if done {
+			logrus.Debugf("using registry endpoint %s for %s", endpoint.URL, namedRef)
 			return nil
 		}
 	}
 	return notFoundError{errors.New("no such manifest: " + namedRef.String())}
 }
 
-// allEndpoints returns a list of endpoints ordered by priority (v2, http).
-func allEndpoints(ctx context.Context, namedRef reference.Named, insecure bool) ([]registry.APIEndpoint, error) {
-	var serviceOpts registry.ServiceOptions
-	if insecure {
-		logrus.Debugf("allowing insecure registry for: %s", reference.Domain(namedRef))
-		serviceOpts.InsecureRegistries = []string{reference.Domain(namedRef)}
+// allEndpoints returns a list of endpoints for the registry host, ordered by
+// priority (v2, http). Plain HTTP endpoints are only included for insecure
+// registries.
+func (c *client) allEndpoints(ctx context.Context, hostname string) ([]registry.APIEndpoint, error) {
+	serviceOpts := c.serviceOptions
+	serviceOpts.InsecureRegistries = slices.Clone(serviceOpts.InsecureRegistries)
+	if c.insecureRegistry {
+		logrus.Debugf("allowing insecure registry for: %s", hostname)
+		serviceOpts.InsecureRegistries = append(serviceOpts.InsecureRegistries, hostname)
 	}
 	registryService, err := registry.NewService(serviceOpts)
 	if err != nil {
 		return nil, err
 	}
-	endpoints, err := registryService.Endpoints(ctx, reference.Domain(namedRef))
-	logrus.Debugf("endpoints for %s: %v", namedRef, endpoints)
+	endpoints, err := registryService.Endpoints(ctx, hostname)
+	logrus.Debugf("endpoints for %s: %v", hostname, endpoints)
 	return endpoints, err
 }
 
 type notFoundError struct{ error }
 
 func (notFoundError) NotFound() {}
+
+type notImplementedError struct{ error }
+
+func (notImplementedError) NotImplemented() {}
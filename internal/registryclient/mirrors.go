@@ -0,0 +1,129 @@
+package registryclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/registry"
+	"github.com/sirupsen/logrus"
+)
+
+// Opt is an option for [NewRegistryClient].
+type Opt func(*client)
+
+// WithConfigFile configures the client to use the registry mirrors, insecure
+// registries, reference rewrite rules, and TLS settings of the given config
+// file.
+func WithConfigFile(cfg *configfile.ConfigFile) Opt {
+	return func(c *client) {
+		c.mirrors = cfg.RegistryMirrors
+		c.rewrites = cfg.RegistryRewrites
+		c.serviceOptions = ServiceOptionsFromConfigFile(cfg)
+	}
+}
+
+// ServiceOptionsFromConfigFile returns the options for the registry service
+// from the CLI's config file: its insecure registries, the "certs.d" directory
+// in the config directory, and its TLS settings per registry.
+func ServiceOptionsFromConfigFile(cfg *configfile.ConfigFile) registry.ServiceOptions {
+	configDir := config.Dir()
+	if cfg.Filename != "" {
+		configDir = filepath.Dir(cfg.Filename)
+	}
+	resolvePath := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(configDir, p)
+	}
+
+	opts := registry.ServiceOptions{
+		InsecureRegistries: slices.Clone(cfg.InsecureRegistries),
+		CertsDirs:          []string{filepath.Join(configDir, "certs.d")},
+	}
+	if len(cfg.RegistryTLS) > 0 {
+		opts.TLS = make(map[string]registry.TLSOptions, len(cfg.RegistryTLS))
+		for host, c := range cfg.RegistryTLS {
+			opts.TLS[host] = registry.TLSOptions{
+				CAFile:             resolvePath(c.CACert),
+				CertFile:           resolvePath(c.Cert),
+				KeyFile:            resolvePath(c.Key),
+				InsecureSkipVerify: c.InsecureSkipVerify,
+			}
+		}
+	}
+	return opts
+}
+
+// rewriteReference returns the reference with its name rewritten by the rule
+// with the longest prefix that matches the name. Prefixes match whole path
+// components of the normalized name, so "docker.io/library" matches
+// "docker.io/library/alpine", but not "docker.io/library-x/alpine". The tag
+// and digest of the reference are preserved.
+func (c *client) rewriteReference(ref reference.Named) (reference.Named, error) {
+	name := ref.Name()
+	var prefix string
+	for p := range c.rewrites {
+		if (name == p || strings.HasPrefix(name, p+"/")) && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		return ref, nil
+	}
+	rewritten, err := reference.ParseNormalizedNamed(c.rewrites[prefix] + strings.TrimPrefix(name, prefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry rewrite rule for %s: %w", prefix, err)
+	}
+	if !reference.IsNameOnly(rewritten) {
+		return nil, fmt.Errorf("invalid registry rewrite rule for %s: replacement must not contain a tag or digest", prefix)
+	}
+	if tagged, ok := ref.(reference.Tagged); ok {
+		if rewritten, err = reference.WithTag(rewritten, tagged.Tag()); err != nil {
+			return nil, err
+		}
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		if rewritten, err = reference.WithDigest(rewritten, digested.Digest()); err != nil {
+			return nil, err
+		}
+	}
+	logrus.Debugf("rewriting %s to %s", ref, rewritten)
+	return rewritten, nil
+}
+
+// mirrorEndpoints returns the endpoints of the mirrors of the registry with
+// the given domain, in the configured order. Mirrors are URLs without a path,
+// such as "https://mirror.example.com"; mirrors that use plain HTTP are
+// allowed, as they are configured explicitly.
+func (c *client) mirrorEndpoints(ctx context.Context, domain string) ([]registry.APIEndpoint, error) {
+	mirrors := c.mirrors[domain]
+	if domain == registry.IndexName && len(mirrors) == 0 {
+		mirrors = c.mirrors[registry.IndexHostname]
+	}
+	endpoints := make([]registry.APIEndpoint, 0, len(mirrors))
+	for _, mirror := range mirrors {
+		u, err := url.Parse(mirror)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return nil, fmt.Errorf("invalid registry mirror %q for %s: must be an http:// or https:// URL without a path", mirror, domain)
+		}
+		// Use the TLS configuration of the mirror's host, which includes
+		// its certificates, and whether it is an insecure registry.
+		hostEndpoints, err := c.allEndpoints(ctx, u.Host)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, registry.APIEndpoint{
+			URL:       &url.URL{Scheme: u.Scheme, Host: u.Host},
+			TLSConfig: hostEndpoints[0].TLSConfig,
+		})
+	}
+	return endpoints, nil
+}
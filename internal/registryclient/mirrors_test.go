@@ -0,0 +1,168 @@
+package registryclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/registry"
+	registrytypes "github.com/moby/moby/api/types/registry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestRewriteReference(t *testing.T) {
+	c := &client{rewrites: map[string]string{
+		"docker.io/library":       "registry.example.com/hub",
+		"docker.io/library/nginx": "registry.example.com/web/nginx",
+		"example.com":             "registry.example.com/example",
+		"bad.example.com":         "registry.example.com/bad:tag",
+	}}
+	testCases := []struct {
+		ref         string
+		expected    string
+		expectedErr string
+	}{
+		{ref: "alpine", expected: "registry.example.com/hub/alpine"},
+		{ref: "alpine:3.20", expected: "registry.example.com/hub/alpine:3.20"},
+		{ref: "nginx:stable", expected: "registry.example.com/web/nginx:stable"},
+		{ref: "docker.io/libraryx/alpine", expected: "docker.io/libraryx/alpine"},
+		{ref: "example.com/team/app@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", expected: "registry.example.com/example/team/app@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{ref: "other.example.com/app", expected: "other.example.com/app"},
+		{ref: "bad.example.com/app", expectedErr: "invalid registry rewrite rule for bad.example.com"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			ref, err := reference.ParseNormalizedNamed(tc.ref)
+			assert.NilError(t, err)
+			rewritten, err := c.rewriteReference(ref)
+			if tc.expectedErr != "" {
+				assert.Check(t, is.ErrorContains(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(rewritten.String(), tc.expected))
+		})
+	}
+}
+
+func TestMirrors(t *testing.T) {
+	upstream := newTestRegistry(t)
+	mirror := newTestRegistry(t)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(broken.Close)
+
+	manifest := ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Layers: []ocispec.Descriptor{}}
+	upstreamDesc := upstream.addManifest(t, "app", "latest", ocispec.MediaTypeImageManifest, manifest)
+	upstream.addManifest(t, "app", "upstream-only", ocispec.MediaTypeImageManifest, manifest)
+	manifest.Annotations = map[string]string{"mirrored": "true"}
+	mirrorDesc := mirror.addManifest(t, "app", "latest", ocispec.MediaTypeImageManifest, manifest)
+
+	var hosts []string
+	c := NewRegistryClient(func(_ context.Context, hostname string) registrytypes.AuthConfig {
+		hosts = append(hosts, hostname)
+		return registrytypes.AuthConfig{}
+	}, "test", true)
+	c.(*client).mirrors = map[string][]string{
+		upstream.host(): {broken.URL, mirror.URL},
+	}
+
+	ctx := context.Background()
+	ref, err := reference.ParseNormalizedNamed(upstream.host() + "/app:latest")
+	assert.NilError(t, err)
+	desc, _, err := c.GetRawManifest(ctx, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(desc.Digest, mirrorDesc.Digest))
+	assert.Check(t, desc.Digest != upstreamDesc.Digest)
+	assert.Check(t, is.Contains(hosts, mirror.host()), "credentials of the mirror should be used")
+
+	// Checking which manifest a tag refers to never uses a mirror.
+	desc, _, err = c.GetUpstreamManifest(ctx, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(desc.Digest, upstreamDesc.Digest))
+
+	ref, err = reference.ParseNormalizedNamed(upstream.host() + "/app:missing")
+	assert.NilError(t, err)
+	_, _, err = c.GetUpstreamManifest(ctx, ref)
+	assert.Check(t, is.ErrorType(err, errdefs.IsNotFound))
+
+	// The manifest is not on the mirror; fall back to the registry itself.
+	ref, err = reference.ParseNormalizedNamed(upstream.host() + "/app:upstream-only")
+	assert.NilError(t, err)
+	desc, _, err = c.GetRawManifest(ctx, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(desc.Digest, upstreamDesc.Digest))
+
+	c.(*client).mirrors = map[string][]string{upstream.host(): {"mirror.example.com/path"}}
+	_, _, err = c.GetRawManifest(ctx, ref)
+	assert.Check(t, is.ErrorContains(err, `invalid registry mirror "mirror.example.com/path"`))
+}
+
+func TestRewritesAndInsecureRegistries(t *testing.T) {
+	r := newTestRegistry(t)
+	config := r.addBlob(t, ocispec.MediaTypeImageConfig, ocispec.Image{
+		Platform: ocispec.Platform{OS: "linux", Architecture: "amd64"},
+		RootFS:   ocispec.RootFS{Type: "layers"},
+	})
+	desc := r.addManifest(t, "hub/library/app", "latest", ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{},
+	})
+
+	ctx := context.Background()
+	ref, err := reference.ParseNormalizedNamed("app:latest")
+	assert.NilError(t, err)
+
+	// The test registry does not use TLS, and is not allowed without
+	// --insecure, or being configured as an insecure registry.
+	c := NewRegistryClient(func(context.Context, string) registrytypes.AuthConfig {
+		return registrytypes.AuthConfig{}
+	}, "test", false)
+	c.(*client).rewrites = map[string]string{"docker.io": r.host() + "/hub"}
+	_, _, err = c.GetRawManifest(ctx, ref)
+	assert.Check(t, err != nil)
+
+	c.(*client).serviceOptions.InsecureRegistries = []string{r.host()}
+	got, _, err := c.GetRawManifest(ctx, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(got.Digest, desc.Digest))
+
+	manifest, err := c.GetManifest(ctx, ref)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(manifest.Ref.String(), "docker.io/library/app:latest"), "the original reference should be preserved")
+}
+
+func TestServiceOptionsFromConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := configfile.New(filepath.Join(dir, "config.json"))
+	cfg.InsecureRegistries = []string{"registry.example.com:5000"}
+	cfg.RegistryTLS = map[string]configfile.RegistryTLSConfig{
+		"registry.example.com": {
+			CACert:             "certs/ca.pem",
+			Cert:               filepath.Join(dir, "client.cert"),
+			Key:                "certs/client.key",
+			InsecureSkipVerify: true,
+		},
+	}
+	assert.Check(t, is.DeepEqual(ServiceOptionsFromConfigFile(cfg), registry.ServiceOptions{
+		InsecureRegistries: []string{"registry.example.com:5000"},
+		CertsDirs:          []string{filepath.Join(dir, "certs.d")},
+		TLS: map[string]registry.TLSOptions{
+			"registry.example.com": {
+				CAFile:             filepath.Join(dir, "certs", "ca.pem"),
+				CertFile:           filepath.Join(dir, "client.cert"),
+				KeyFile:            filepath.Join(dir, "certs", "client.key"),
+				InsecureSkipVerify: true,
+			},
+		},
+	}))
+}
@@ -281,7 +281,9 @@ func ValidateSysctl(val string) (string, error) {
 
 // FilterOpt is a flag type for validating filters
 type FilterOpt struct {
-	filter client.Filters
+	filter           client.Filters
+	comparisons      []ComparisonFilter
+	allowComparisons bool
 }
 
 // NewFilterOpt returns a new FilterOpt
@@ -289,6 +291,47 @@ func NewFilterOpt() FilterOpt {
 	return FilterOpt{filter: make(client.Filters)}
 }
 
+// NewFilterOptWithComparisons returns a new FilterOpt that, in addition to
+// "name=value" filters, accepts comparison filters such as "size>500MB" or
+// "created<=72h". Comparison filters are evaluated on the client, and can
+// be obtained through [FilterOpt.Comparisons].
+func NewFilterOptWithComparisons() FilterOpt {
+	return FilterOpt{filter: make(client.Filters), allowComparisons: true}
+}
+
+// ComparisonFilter is a filter that compares the value of a field using
+// one of the "<", "<=", ">", or ">=" operators.
+type ComparisonFilter struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// String returns the string-representation of the filter, such as "size>500MB".
+func (f ComparisonFilter) String() string {
+	return f.Field + f.Operator + f.Value
+}
+
+// parseComparisonFilter parses a comparison filter. It returns false if the
+// value is not a comparison, which is the case if it has no comparison
+// operator, or if the value contains a "=" before the operator (as in
+// "label=foo>bar").
+func parseComparisonFilter(value string) (ComparisonFilter, bool) {
+	idx := strings.IndexAny(value, "<>=")
+	if idx <= 0 || value[idx] == '=' {
+		return ComparisonFilter{}, false
+	}
+	op := value[idx : idx+1]
+	if idx+1 < len(value) && value[idx+1] == '=' {
+		op += "="
+	}
+	return ComparisonFilter{
+		Field:    strings.ToLower(strings.TrimSpace(value[:idx])),
+		Operator: op,
+		Value:    strings.TrimSpace(value[idx+len(op):]),
+	}, true
+}
+
 func (o *FilterOpt) String() string {
 	if o == nil || len(o.filter) == 0 {
 		return ""
@@ -305,6 +348,15 @@ func (o *FilterOpt) Set(value string) error {
 	if value == "" {
 		return nil
 	}
+	if o.allowComparisons {
+		if f, ok := parseComparisonFilter(value); ok {
+			if f.Value == "" {
+				return errors.New("bad format of filter (expected name<value, name<=value, name>value, or name>=value)")
+			}
+			o.comparisons = append(o.comparisons, f)
+			return nil
+		}
+	}
 	if !strings.Contains(value, "=") {
 		return errors.New("bad format of filter (expected name=value)")
 	}
@@ -327,6 +379,13 @@ func (o *FilterOpt) Value() client.Filters {
 	return o.filter
 }
 
+// Comparisons returns the comparison filters (such as "size>500MB") of this
+// option. Comparison filters are only accepted if the option was created
+// with [NewFilterOptWithComparisons].
+func (o *FilterOpt) Comparisons() []ComparisonFilter {
+	return o.comparisons
+}
+
 // NanoCPUs is a type for fixed point fractional number.
 type NanoCPUs int64
 
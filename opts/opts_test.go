@@ -428,3 +428,38 @@ func TestParseCPUsReturnZeroOnInvalidValues(t *testing.T) {
 	resValue, _ = ParseCPUs("1e-32")
 	assert.Equal(t, z1, resValue)
 }
+
+func TestFilterOptComparisons(t *testing.T) {
+	tests := []struct {
+		value       string
+		comparisons []ComparisonFilter
+		filters     map[string]map[string]bool
+		expectedErr string
+	}{
+		{value: "size>500MB", comparisons: []ComparisonFilter{{Field: "size", Operator: ">", Value: "500MB"}}},
+		{value: "created <= 72h", comparisons: []ComparisonFilter{{Field: "created", Operator: "<=", Value: "72h"}}},
+		{value: "Size>=1GB", comparisons: []ComparisonFilter{{Field: "size", Operator: ">=", Value: "1GB"}}},
+		{value: "label=a>b", filters: map[string]map[string]bool{"label": {"a>b": true}}},
+		{value: "size<", expectedErr: "bad format of filter (expected name<value, name<=value, name>value, or name>=value)"},
+		{value: ">1", expectedErr: "bad format of filter (expected name=value)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			o := NewFilterOptWithComparisons()
+			err := o.Set(tc.value)
+			if tc.expectedErr != "" {
+				assert.Check(t, is.Error(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(o.Comparisons(), tc.comparisons))
+			if tc.filters != nil {
+				assert.Check(t, is.DeepEqual(map[string]map[string]bool(o.Value()), tc.filters))
+			}
+		})
+	}
+
+	// Comparisons are not accepted by default.
+	o := NewFilterOpt()
+	assert.Check(t, is.Error(o.Set("size>500MB"), "bad format of filter (expected name=value)"))
+}